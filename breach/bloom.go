@@ -0,0 +1,140 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// bloomMagic adalah penanda awal file bloom filter
+const bloomMagic = "BRBF1"
+
+// bloomChunkWords adalah jumlah word yang ditulis/dibaca sekaligus, agar filter berukuran
+// gigabyte tidak perlu disalin utuh ke buffer sementara
+const bloomChunkWords = 8192
+
+// BloomFilter adalah bloom filter berisi hash SHA-1 password yang bocor. Ukurannya jauh lebih
+// kecil dari dump aslinya (sekitar 1.8 byte per hash untuk false positive 0.1%), dengan
+// konsekuensi sebagian kecil password yang aman ikut ditolak. Tidak pernah ada false negative.
+type BloomFilter struct {
+	bits  []uint64
+	m     uint64 // Jumlah bit
+	k     uint32 // Jumlah fungsi hash
+	count uint64 // Jumlah hash yang dimasukkan
+}
+
+// NewBloomFilter membuat bloom filter untuk n hash dengan target false positive rate fp (contoh: 0.001)
+func NewBloomFilter(n uint64, fp float64) *BloomFilter {
+	if n == 0 {
+		n = 1
+	}
+	if fp <= 0 || fp >= 1 {
+		fp = 0.001
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+	return &BloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// Add memasukkan hash SHA-1 ke filter
+func (f *BloomFilter) Add(hash [sha1.Size]byte) {
+	h1, h2 := bloomHashes(hash)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.count++
+}
+
+// Contains mengecek apakah hash SHA-1 (kemungkinan besar) ada di filter
+func (f *BloomFilter) Contains(hash [sha1.Size]byte) bool {
+	h1, h2 := bloomHashes(hash)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Breached mengecek password terhadap filter
+func (f *BloomFilter) Breached(password string) (bool, error) {
+	return f.Contains(Hash(password)), nil
+}
+
+// Count mengembalikan jumlah hash yang dimasukkan ke filter
+func (f *BloomFilter) Count() uint64 {
+	return f.count
+}
+
+// WriteTo menulis filter ke w: magic, m, k, count lalu bitset (little endian)
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, len(bloomMagic)+8+4+8)
+	copy(header, bloomMagic)
+	binary.LittleEndian.PutUint64(header[len(bloomMagic):], f.m)
+	binary.LittleEndian.PutUint32(header[len(bloomMagic)+8:], f.k)
+	binary.LittleEndian.PutUint64(header[len(bloomMagic)+12:], f.count)
+	n, err := w.Write(header)
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	buf := make([]byte, bloomChunkWords*8)
+	for start := 0; start < len(f.bits); start += bloomChunkWords {
+		chunk := f.bits[start:min(start+bloomChunkWords, len(f.bits))]
+		for i, word := range chunk {
+			binary.LittleEndian.PutUint64(buf[i*8:], word)
+		}
+		n, err := w.Write(buf[:len(chunk)*8])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// ReadBloomFilter membaca filter yang ditulis oleh WriteTo
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, len(bloomMagic)+8+4+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("bloom filter: %w", err)
+	}
+	if string(header[:len(bloomMagic)]) != bloomMagic {
+		return nil, errors.New("bloom filter: format file tidak dikenal")
+	}
+
+	f := &BloomFilter{
+		m:     binary.LittleEndian.Uint64(header[len(bloomMagic):]),
+		k:     binary.LittleEndian.Uint32(header[len(bloomMagic)+8:]),
+		count: binary.LittleEndian.Uint64(header[len(bloomMagic)+12:]),
+	}
+	if f.m == 0 || f.k == 0 {
+		return nil, errors.New("bloom filter: header tidak valid")
+	}
+	f.bits = make([]uint64, (f.m+63)/64)
+	buf := make([]byte, bloomChunkWords*8)
+	for start := 0; start < len(f.bits); start += bloomChunkWords {
+		chunk := f.bits[start:min(start+bloomChunkWords, len(f.bits))]
+		if _, err := io.ReadFull(r, buf[:len(chunk)*8]); err != nil {
+			return nil, fmt.Errorf("bloom filter: %w", err)
+		}
+		for i := range chunk {
+			chunk[i] = binary.LittleEndian.Uint64(buf[i*8:])
+		}
+	}
+	return f, nil
+}
+
+// bloomHashes mengambil dua nilai hash dari digest SHA-1 untuk double hashing.
+// SHA-1 sudah terdistribusi rata sehingga tidak perlu di-hash ulang.
+func bloomHashes(hash [sha1.Size]byte) (uint64, uint64) {
+	h1 := binary.BigEndian.Uint64(hash[0:8])
+	h2 := binary.BigEndian.Uint64(hash[8:16]) | 1 // Ganjil agar tidak pernah 0
+	return h1, h2
+}
@@ -0,0 +1,81 @@
+// Package breach memeriksa apakah password pernah muncul di kebocoran data tanpa memanggil
+// layanan luar. Dataset yang dipakai adalah dump Pwned Passwords (HIBP) versi SHA-1, baik
+// berupa folder file range k-anonymity (satu file per 5 karakter awal hash) maupun bloom
+// filter ringkas yang dibangun dari dump tersebut dengan go run ./cmd/breach-filter.
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Checker memeriksa password terhadap dataset kebocoran
+type Checker interface {
+	// Breached mengembalikan true jika password ada di dataset
+	Breached(password string) (bool, error)
+}
+
+// Open membuka dataset dari path: folder berarti file range HIBP, file berarti bloom filter
+func Open(path string) (Checker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return NewRangeDir(path), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadBloomFilter(bufio.NewReader(file))
+}
+
+// Hash mengembalikan SHA-1 password, format hash yang dipakai dataset HIBP
+func Hash(password string) [sha1.Size]byte {
+	return sha1.Sum([]byte(password))
+}
+
+// ScanDump membaca dataset HIBP baris per baris dengan format HASH:COUNT dan memanggil fn
+// untuk setiap hash dengan COUNT >= minCount. prefix diisi nama file untuk file range
+// (baris hanya berisi sisa hash), kosong untuk dump lengkap. Baris padding (COUNT 0) dilewati.
+func ScanDump(r io.Reader, prefix string, minCount int, fn func(hash [sha1.Size]byte)) error {
+	if minCount < 1 {
+		minCount = 1
+	}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		suffix, countText, ok := strings.Cut(text, ":")
+		count, err := strconv.Atoi(countText)
+		if !ok || err != nil {
+			return fmt.Errorf("baris %d: format harus HASH:COUNT", line)
+		}
+		if count < minCount {
+			continue
+		}
+
+		var hash [sha1.Size]byte
+		decoded, err := hex.DecodeString(prefix + suffix)
+		if err != nil || len(decoded) != sha1.Size {
+			return fmt.Errorf("baris %d: hash SHA-1 tidak valid", line)
+		}
+		copy(hash[:], decoded)
+		fn(hash)
+	}
+	return scanner.Err()
+}
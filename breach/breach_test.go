@@ -0,0 +1,105 @@
+package breach_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/breach"
+)
+
+// hibpLine membuat baris HASH:COUNT untuk password dengan format dump HIBP
+func hibpLine(password string, count int) string {
+	hash := breach.Hash(password)
+	return fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(hash[:])), count)
+}
+
+func TestRangeDir(t *testing.T) {
+	dir := t.TempDir()
+	// Satu file range per prefix, baris hanya berisi sisa hash
+	for password, count := range map[string]int{"password123": 2254650, "padding-only": 0} {
+		line := hibpLine(password, count)
+		path := filepath.Join(dir, line[:5])
+		if err := os.WriteFile(path, []byte(line[5:]+"\r\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checker, err := breach.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for password, want := range map[string]bool{
+		"password123":             true,
+		"padding-only":            false, // COUNT 0 adalah padding, bukan kebocoran
+		"kuda-lari-7-senja-pagi!": false,
+	} {
+		got, err := checker.Breached(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("Breached(%q) = %v, want %v", password, got, want)
+		}
+	}
+}
+
+func TestBloomFilterRoundTrip(t *testing.T) {
+	dump := strings.Join([]string{
+		hibpLine("password123", 2254650),
+		hibpLine("rahasia123", 42),
+		hibpLine("jarang-dipakai", 1),
+		hibpLine("padding", 0),
+	}, "\n")
+
+	var hashes [][sha1.Size]byte
+	err := breach.ScanDump(strings.NewReader(dump), "", 2, func(hash [sha1.Size]byte) {
+		hashes = append(hashes, hash)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 2 {
+		t.Fatalf("scanned %d hashes with min count 2, want 2", len(hashes))
+	}
+
+	filter := breach.NewBloomFilter(uint64(len(hashes)), 0.001)
+	for _, hash := range hashes {
+		filter.Add(hash)
+	}
+	var buf bytes.Buffer
+	if _, err := filter.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "breached.bloom")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	checker, err := breach.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for password, want := range map[string]bool{
+		"password123":             true,
+		"rahasia123":              true,
+		"jarang-dipakai":          false,
+		"kuda-lari-7-senja-pagi!": false,
+	} {
+		got, _ := checker.Breached(password)
+		if got != want {
+			t.Fatalf("Breached(%q) = %v, want %v", password, got, want)
+		}
+	}
+}
+
+func TestReadBloomFilterRejectsUnknownFormat(t *testing.T) {
+	if _, err := breach.ReadBloomFilter(strings.NewReader("bukan bloom filter sama sekali")); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
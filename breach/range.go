@@ -0,0 +1,73 @@
+package breach
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rangePrefixLength adalah panjang prefix hash (karakter hex) yang menjadi nama file range
+const rangePrefixLength = 5
+
+// RangeDir membaca folder file range HIBP (nama file = 5 karakter hex awal SHA-1, isi file =
+// SUFFIX:COUNT per baris), format yang sama dengan response api.pwnedpasswords.com/range/{prefix}
+type RangeDir struct {
+	dir string
+}
+
+// NewRangeDir membuat RangeDir untuk folder dir
+func NewRangeDir(dir string) *RangeDir {
+	return &RangeDir{dir: dir}
+}
+
+// Breached mencari sisa hash password di file range dengan prefix yang sesuai.
+// File range yang tidak ada berarti tidak ada hash dengan prefix tersebut.
+func (d *RangeDir) Breached(password string) (bool, error) {
+	hash := Hash(password)
+	full := strings.ToUpper(hex.EncodeToString(hash[:]))
+	prefix, suffix := full[:rangePrefixLength], full[rangePrefixLength:]
+
+	file, err := d.open(prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		// Baris padding punya COUNT 0 dan bukan hash yang benar-benar bocor
+		if strings.EqualFold(lineSuffix, suffix) && count != "0" {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// open membuka file range, nama file boleh polos atau ber-ekstensi .txt
+func (d *RangeDir) open(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(d.dir, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Open(filepath.Join(d.dir, prefix+".txt"))
+	}
+	return file, err
+}
+
+// RangePrefix mengembalikan prefix hash dari nama file range, false jika bukan file range
+func RangePrefix(name string) (string, bool) {
+	prefix := strings.TrimSuffix(filepath.Base(name), ".txt")
+	if len(prefix) != rangePrefixLength {
+		return "", false
+	}
+	if _, err := hex.DecodeString(prefix + "0"); err != nil {
+		return "", false
+	}
+	return strings.ToUpper(prefix), true
+}
@@ -0,0 +1,69 @@
+// Command audit-verify menelusuri rantai hash audit log dan checkpoint yang ditandatangani,
+// lalu melaporkan setiap entry yang diubah, disisipkan atau dihapus.
+//
+//	go run ./cmd/audit-verify          # ringkasan
+//	go run ./cmd/audit-verify -json    # report lengkap dalam JSON
+//
+// Exit code 1 jika ada ketidakcocokan. Koneksi database dan AUDIT_PUBLIC_KEY / AUDIT_SIGNING_KEY
+// dibaca dari .env seperti aplikasi utama.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"golang-starter-kit/config"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/services"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "tampilkan report lengkap dalam JSON")
+	flag.Parse()
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		fmt.Println("Error loading .env file")
+	}
+
+	auditConfig, err := config.LoadAuditConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.ConnectDB()
+
+	audit := services.NewAuditService(
+		repositories.NewAuditRepository(config.DB),
+		repositories.NewAuditCheckpointRepository(config.DB),
+		auditConfig,
+	)
+	report, err := audit.Verify(context.Background())
+	if err != nil {
+		log.Fatal("Verifikasi gagal: ", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	} else {
+		fmt.Printf("Entry diperiksa : %d\n", report.Checked)
+		fmt.Printf("Hash terakhir   : %s\n", report.LastHash)
+		fmt.Printf("Checkpoint      : %d (tanda tangan diperiksa: %t)\n", report.Checkpoints, report.SignaturesVerified)
+		for _, b := range report.Breaks {
+			fmt.Printf("RUSAK %s #%d: %s\n", b.Type, b.ID, b.Reason)
+		}
+		if report.Valid {
+			fmt.Println("Audit log valid")
+		}
+	}
+
+	if !report.Valid {
+		os.Exit(1)
+	}
+}
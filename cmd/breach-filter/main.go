@@ -0,0 +1,118 @@
+// Command breach-filter membangun bloom filter password bocor dari dump Pwned Passwords (HIBP)
+// versi SHA-1 yang sudah diunduh, baik file dump lengkap (HASH:COUNT per baris) maupun folder
+// file range hasil pwned-passwords-downloader.
+//
+//	go run ./cmd/breach-filter -in pwned-passwords-sha1.txt -out storage/breached-passwords.bloom
+//	go run ./cmd/breach-filter -in ./pwnedpasswords -fp 0.0001 -min-count 10
+//
+// Hasilnya dipakai aplikasi lewat BREACHED_PASSWORDS_PATH.
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang-starter-kit/breach"
+)
+
+func main() {
+	in := flag.String("in", "", "file dump HIBP atau folder file range (wajib)")
+	out := flag.String("out", "storage/breached-passwords.bloom", "file bloom filter yang dihasilkan")
+	fp := flag.Float64("fp", 0.001, "target false positive rate")
+	minCount := flag.Int("min-count", 1, "hanya masukkan hash yang muncul minimal N kali di kebocoran")
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Pass pertama menghitung jumlah hash untuk menentukan ukuran filter
+	var total uint64
+	err := eachSource(*in, func(r io.Reader, prefix string) error {
+		return breach.ScanDump(r, prefix, *minCount, func([sha1.Size]byte) { total++ })
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	filter := breach.NewBloomFilter(total, *fp)
+	err = eachSource(*in, func(r io.Reader, prefix string) error {
+		return breach.ScanDump(r, prefix, *minCount, filter.Add)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := write(*out, filter); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d hash ditulis ke %s\n", filter.Count(), *out)
+}
+
+// eachSource memanggil fn untuk file dump, atau untuk setiap file range jika in adalah folder
+func eachSource(in string, fn func(r io.Reader, prefix string) error) error {
+	info, err := os.Stat(in)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return scanFile(in, "", fn)
+	}
+
+	return filepath.WalkDir(in, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		prefix, ok := breach.RangePrefix(path)
+		if !ok {
+			return nil
+		}
+		return scanFile(path, prefix, fn)
+	})
+}
+
+// scanFile membuka satu file lalu meneruskannya ke fn
+func scanFile(path, prefix string, fn func(r io.Reader, prefix string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := fn(bufio.NewReader(file), prefix); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// write menyimpan filter ke file sementara lalu me-rename, agar aplikasi tidak membaca file setengah jadi
+func write(path string, filter *breach.BloomFilter) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	if _, err := filter.WriteTo(writer); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
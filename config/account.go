@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// AccountConfig adalah pengaturan fitur akun milik user sendiri (ganti email)
+type AccountConfig struct {
+	EmailConfirmURL string        // URL halaman frontend untuk konfirmasi email baru, token ditambahkan sebagai ?token=
+	EmailChangeTTL  time.Duration // Masa berlaku link konfirmasi email baru
+}
+
+// LoadAccountConfig membaca pengaturan akun dari env EMAIL_CHANGE_CONFIRM_URL dan EMAIL_CHANGE_TTL_HOURS
+func LoadAccountConfig() AccountConfig {
+	return AccountConfig{
+		EmailConfirmURL: GetEnv("EMAIL_CHANGE_CONFIRM_URL", "http://localhost:3000/confirm-email"),
+		EmailChangeTTL:  time.Duration(GetEnvInt("EMAIL_CHANGE_TTL_HOURS", 24)) * time.Hour,
+	}
+}
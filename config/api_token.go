@@ -0,0 +1,19 @@
+package config
+
+// APITokenConfig adalah pengaturan personal access token / API key
+type APITokenConfig struct {
+	DefaultDays int // Masa berlaku token jika user tidak memilih
+	MaxDays     int // Masa berlaku terpanjang yang boleh dipilih user
+}
+
+// LoadAPITokenConfig membaca pengaturan token dari env API_TOKEN_DEFAULT_DAYS dan API_TOKEN_MAX_DAYS
+func LoadAPITokenConfig() APITokenConfig {
+	cfg := APITokenConfig{
+		DefaultDays: GetEnvInt("API_TOKEN_DEFAULT_DAYS", 90),
+		MaxDays:     GetEnvInt("API_TOKEN_MAX_DAYS", 365),
+	}
+	if cfg.DefaultDays > cfg.MaxDays {
+		cfg.DefaultDays = cfg.MaxDays
+	}
+	return cfg
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// AuditConfig adalah pengaturan tanda tangan checkpoint audit log
+type AuditConfig struct {
+	SigningKey ed25519.PrivateKey // nil berarti checkpoint tidak dibuat
+	PublicKey  ed25519.PublicKey  // Untuk verifikasi, nil berarti tanda tangan checkpoint tidak diperiksa
+}
+
+// LoadAuditConfig membaca AUDIT_SIGNING_KEY (seed Ed25519 32 byte, base64) dan AUDIT_PUBLIC_KEY
+// (public key Ed25519, base64). Jika AUDIT_PUBLIC_KEY kosong, public key diturunkan dari signing key.
+// Buat pasangan key dengan: go run generate_audit_key.go
+func LoadAuditConfig() (AuditConfig, error) {
+	var cfg AuditConfig
+
+	if value := os.Getenv("AUDIT_SIGNING_KEY"); value != "" {
+		seed, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return cfg, fmt.Errorf("AUDIT_SIGNING_KEY must be a base64 encoded %d byte Ed25519 seed", ed25519.SeedSize)
+		}
+		cfg.SigningKey = ed25519.NewKeyFromSeed(seed)
+		cfg.PublicKey = cfg.SigningKey.Public().(ed25519.PublicKey)
+	}
+
+	if value := os.Getenv("AUDIT_PUBLIC_KEY"); value != "" {
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return cfg, fmt.Errorf("AUDIT_PUBLIC_KEY must be a base64 encoded %d byte Ed25519 public key", ed25519.PublicKeySize)
+		}
+		cfg.PublicKey = ed25519.PublicKey(key)
+	}
+	return cfg, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"golang-starter-kit/utils"
 	"gorm.io/driver/postgres"
 	// "gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -40,7 +41,8 @@ func ConnectDB() {
 		os.Getenv("DB_PORT"),
 	)
 	// Koneksi DB
-	DB, err = gorm.Open(postgres.Open(database), &gorm.Config{})
+	// NowFunc memakai Clock aplikasi agar created_at/updated_at mengikuti Clock yang sama
+	DB, err = gorm.Open(postgres.Open(database), &gorm.Config{NowFunc: utils.Now})
 	if err != nil {
 		log.Fatal("Failed to connect to BD: ", err)
 	}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetEnv membaca env, mengembalikan def jika kosong
+func GetEnv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// GetEnvInt membaca env sebagai angka, mengembalikan def jika kosong atau tidak valid
+func GetEnvInt(key string, def int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetEnvBool membaca env sebagai boolean (true/false/1/0), mengembalikan def jika kosong atau tidak valid
+func GetEnvBool(key string, def bool) bool {
+	value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetEnvList membaca env berisi daftar yang dipisah koma, item kosong diabaikan
+func GetEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
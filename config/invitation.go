@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// InvitationConfig adalah pengaturan link undangan
+type InvitationConfig struct {
+	AcceptURL string        // URL halaman frontend untuk menerima undangan, token ditambahkan sebagai ?token=
+	TTL       time.Duration // Masa berlaku link undangan
+}
+
+// LoadInvitationConfig membaca pengaturan undangan dari env INVITATION_ACCEPT_URL dan INVITATION_TTL_HOURS
+func LoadInvitationConfig() InvitationConfig {
+	return InvitationConfig{
+		AcceptURL: GetEnv("INVITATION_ACCEPT_URL", "http://localhost:3000/accept-invitation"),
+		TTL:       time.Duration(GetEnvInt("INVITATION_TTL_HOURS", 72)) * time.Hour,
+	}
+}
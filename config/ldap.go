@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LDAPGroupRole memetakan satu grup di directory LDAP ke nama Role lokal
+type LDAPGroupRole struct {
+	Group string // DN grup, contoh "cn=it-admins,ou=groups,dc=example,dc=org"
+	Role  string // Nama role lokal, misalnya "admin"
+}
+
+// LDAPConfig adalah pengaturan login dan sinkronisasi user dari directory LDAP (OpenLDAP, Active Directory)
+type LDAPConfig struct {
+	URL                string        // Contoh ldap://ldap.example.org:389 atau ldaps://...:636. Kosong = LDAP tidak dipakai
+	StartTLS           bool          // Upgrade koneksi ldap:// ke TLS sebelum bind
+	InsecureSkipVerify bool          // Lewati verifikasi sertifikat server, hanya untuk development
+	Timeout            time.Duration // Batas waktu koneksi dan setiap operasi ke server LDAP
+
+	BindDN       string // Akun service untuk mencari user, kosong = anonymous
+	BindPassword string
+	BaseDN       string // Dasar pencarian user, contoh "ou=people,dc=example,dc=org"
+
+	// UserFilter mencari entry user saat login, {login} diganti dengan email yang diinput (sudah di-escape)
+	UserFilter string
+	// SyncFilter memilih semua user yang disinkronkan. User yang tidak lagi cocok dinonaktifkan,
+	// contoh untuk Active Directory: (&(objectClass=user)(!(userAccountControl:1.2.840.113556.1.4.803:=2)))
+	SyncFilter string
+
+	IDAttribute    string // Atribut ID yang tidak berubah, contoh entryUUID (OpenLDAP) atau objectGUID (AD)
+	EmailAttribute string
+	NameAttribute  string
+	GroupAttribute string // Atribut berisi DN grup user, contoh memberOf
+
+	GroupRoles  []LDAPGroupRole // Dicek berurutan, grup pertama yang dimiliki user menentukan role-nya
+	DefaultRole string          // Role user baru jika tidak ada grup yang cocok, kosong = REGISTRATION_DEFAULT_ROLE
+
+	// LinkByEmail menautkan entry baru ke user lokal dengan email yang sama. User dengan password
+	// lokal atau role admin tidak pernah ditautkan agar entry directory tidak bisa mengambil alih akun.
+	LinkByEmail bool
+}
+
+// Enabled menandakan LDAP dikonfigurasi
+func (c LDAPConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+// LoadLDAPConfig membaca pengaturan LDAP dari env LDAP_*. LDAP_GROUP_ROLE_MAP berisi pasangan
+// "DN grup:role" yang dipisah titik koma karena DN sendiri mengandung koma, contoh:
+//
+//	LDAP_GROUP_ROLE_MAP=cn=it-admins,ou=groups,dc=example,dc=org:admin;cn=staff,ou=groups,dc=example,dc=org:user
+func LoadLDAPConfig() (LDAPConfig, error) {
+	cfg := LDAPConfig{
+		URL:                GetEnv("LDAP_URL", ""),
+		StartTLS:           GetEnvBool("LDAP_START_TLS", false),
+		InsecureSkipVerify: GetEnvBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		Timeout:            time.Duration(GetEnvInt("LDAP_TIMEOUT_SECONDS", 10)) * time.Second,
+		BindDN:             GetEnv("LDAP_BIND_DN", ""),
+		BindPassword:       GetEnv("LDAP_BIND_PASSWORD", ""),
+		BaseDN:             GetEnv("LDAP_BASE_DN", ""),
+		UserFilter:         GetEnv("LDAP_USER_FILTER", "(&(objectClass=person)(mail={login}))"),
+		SyncFilter:         GetEnv("LDAP_SYNC_FILTER", "(objectClass=person)"),
+		IDAttribute:        GetEnv("LDAP_ID_ATTRIBUTE", "entryUUID"),
+		EmailAttribute:     GetEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+		NameAttribute:      GetEnv("LDAP_NAME_ATTRIBUTE", "cn"),
+		GroupAttribute:     GetEnv("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+		DefaultRole:        GetEnv("LDAP_DEFAULT_ROLE", ""),
+		LinkByEmail:        GetEnvBool("LDAP_LINK_BY_EMAIL", false),
+	}
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+
+	if cfg.BaseDN == "" {
+		return cfg, fmt.Errorf("LDAP_BASE_DN is required when LDAP_URL is set")
+	}
+	if !strings.Contains(cfg.UserFilter, "{login}") {
+		return cfg, fmt.Errorf("LDAP_USER_FILTER must contain {login}")
+	}
+	if cfg.Timeout <= 0 {
+		return cfg, fmt.Errorf("LDAP_TIMEOUT_SECONDS must be positive")
+	}
+
+	for _, item := range strings.Split(GetEnv("LDAP_GROUP_ROLE_MAP", ""), ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		// DN grup bisa mengandung ":" (jarang), role tidak, jadi dipotong di ":" terakhir
+		index := strings.LastIndex(item, ":")
+		if index < 0 {
+			return cfg, fmt.Errorf("LDAP_GROUP_ROLE_MAP: expected group:role, got %q", item)
+		}
+		group, role := strings.TrimSpace(item[:index]), strings.TrimSpace(item[index+1:])
+		if group == "" || role == "" {
+			return cfg, fmt.Errorf("LDAP_GROUP_ROLE_MAP: expected group:role, got %q", item)
+		}
+		cfg.GroupRoles = append(cfg.GroupRoles, LDAPGroupRole{Group: group, Role: role})
+	}
+	return cfg, nil
+}
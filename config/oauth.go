@@ -0,0 +1,56 @@
+package config
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"time"
+)
+
+// OAuthConfig adalah pengaturan authorization server OAuth 2.0 dan provider OpenID Connect
+type OAuthConfig struct {
+	Issuer          string        // URL publik server, dipakai sebagai claim "iss" di access token dan ID token
+	ConsentURL      string        // URL halaman persetujuan di frontend, query authorize diteruskan apa adanya
+	AccessTokenTTL  time.Duration // Masa berlaku access token
+	RefreshTokenTTL time.Duration // Masa berlaku grant (refresh token) sejak persetujuan
+	CodeTTL         time.Duration // Masa berlaku authorization code
+	IDTokenTTL      time.Duration // Masa berlaku ID token OpenID Connect
+
+	// SigningKey menandatangani ID token (RS256). nil berarti key sementara dibuat saat pertama
+	// dipakai, ID token lama tidak bisa diverifikasi lagi setelah aplikasi restart.
+	SigningKey *rsa.PrivateKey
+}
+
+// LoadOAuthConfig membaca pengaturan OAuth dari env OAUTH_ISSUER, OAUTH_CONSENT_URL,
+// OAUTH_ACCESS_TOKEN_TTL_MINUTES, OAUTH_REFRESH_TOKEN_TTL_DAYS, OAUTH_CODE_TTL_SECONDS,
+// OIDC_ID_TOKEN_TTL_MINUTES dan OIDC_SIGNING_KEY (private key RSA PKCS#8 DER, base64).
+// Buat signing key dengan: go run generate_oidc_key.go
+func LoadOAuthConfig() (OAuthConfig, error) {
+	cfg := OAuthConfig{
+		Issuer:          GetEnv("OAUTH_ISSUER", "http://localhost:8080"),
+		ConsentURL:      GetEnv("OAUTH_CONSENT_URL", "http://localhost:3000/oauth/consent"),
+		AccessTokenTTL:  time.Duration(GetEnvInt("OAUTH_ACCESS_TOKEN_TTL_MINUTES", 60)) * time.Minute,
+		RefreshTokenTTL: time.Duration(GetEnvInt("OAUTH_REFRESH_TOKEN_TTL_DAYS", 30)) * 24 * time.Hour,
+		CodeTTL:         time.Duration(GetEnvInt("OAUTH_CODE_TTL_SECONDS", 60)) * time.Second,
+		IDTokenTTL:      time.Duration(GetEnvInt("OIDC_ID_TOKEN_TTL_MINUTES", 60)) * time.Minute,
+	}
+
+	if value := os.Getenv("OIDC_SIGNING_KEY"); value != "" {
+		der, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return cfg, fmt.Errorf("OIDC_SIGNING_KEY must be base64 encoded: %w", err)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return cfg, fmt.Errorf("OIDC_SIGNING_KEY must be a PKCS#8 private key: %w", err)
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok || rsaKey.N.BitLen() < 2048 {
+			return cfg, fmt.Errorf("OIDC_SIGNING_KEY must be an RSA key of at least 2048 bits")
+		}
+		cfg.SigningKey = rsaKey
+	}
+	return cfg, nil
+}
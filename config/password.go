@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"golang-starter-kit/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Jenis karakter yang bisa diwajibkan lewat PASSWORD_REQUIRED_CLASSES
+const (
+	PasswordClassLower  = "lower"
+	PasswordClassUpper  = "upper"
+	PasswordClassDigit  = "digit"
+	PasswordClassSymbol = "symbol"
+)
+
+// PasswordPolicyConfig adalah aturan password yang berlaku untuk semua cara membuat atau mengganti password
+type PasswordPolicyConfig struct {
+	MinLength        int      // Panjang minimal (jumlah karakter)
+	MaxLength        int      // Panjang maksimal, 0 berarti tanpa batas
+	RequiredClasses  []string // Jenis karakter yang wajib ada: lower, upper, digit, symbol
+	MinScore         int      // Skor kekuatan minimal 0-4 (skala zxcvbn), 0 berarti tidak dicek
+	DisallowPersonal bool     // Tolak password yang mengandung nama atau email user
+	History          int      // Jumlah password terakhir yang tidak boleh dipakai ulang, 0 berarti tidak dicek
+	BreachedPath     string   // Folder file range HIBP atau file bloom filter, kosong berarti tidak dicek
+}
+
+// LoadPasswordPolicy membaca kebijakan password dari env PASSWORD_*
+func LoadPasswordPolicy() (PasswordPolicyConfig, error) {
+	policy := PasswordPolicyConfig{
+		MinLength:        GetEnvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:        GetEnvInt("PASSWORD_MAX_LENGTH", 128),
+		MinScore:         GetEnvInt("PASSWORD_MIN_SCORE", 2),
+		DisallowPersonal: GetEnvBool("PASSWORD_DISALLOW_PERSONAL", true),
+		History:          GetEnvInt("PASSWORD_HISTORY", 5),
+		BreachedPath:     GetEnv("BREACHED_PASSWORDS_PATH", ""),
+	}
+
+	for _, class := range GetEnvList("PASSWORD_REQUIRED_CLASSES") {
+		class = strings.ToLower(class)
+		switch class {
+		case PasswordClassLower, PasswordClassUpper, PasswordClassDigit, PasswordClassSymbol:
+			policy.RequiredClasses = append(policy.RequiredClasses, class)
+		default:
+			return policy, fmt.Errorf("PASSWORD_REQUIRED_CLASSES: jenis karakter %q tidak dikenal", class)
+		}
+	}
+	if policy.MinLength < 1 {
+		return policy, fmt.Errorf("PASSWORD_MIN_LENGTH harus lebih dari 0")
+	}
+	if policy.MaxLength != 0 && policy.MaxLength < policy.MinLength {
+		return policy, fmt.Errorf("PASSWORD_MAX_LENGTH tidak boleh lebih kecil dari PASSWORD_MIN_LENGTH")
+	}
+	if policy.MinScore < 0 || policy.MinScore > 4 {
+		return policy, fmt.Errorf("PASSWORD_MIN_SCORE harus di antara 0 dan 4")
+	}
+	if policy.History < 0 {
+		return policy, fmt.Errorf("PASSWORD_HISTORY tidak boleh negatif")
+	}
+	return policy, nil
+}
+
+// LoadPasswordHashConfig membaca algoritma dan parameter hash password dari env PASSWORD_HASH_*,
+// PASSWORD_BCRYPT_COST, PASSWORD_ARGON2_* dan PASSWORD_PEPPER
+func LoadPasswordHashConfig() (utils.PasswordHashConfig, error) {
+	hash := utils.DefaultPasswordHashConfig()
+	hash.Algorithm = strings.ToLower(GetEnv("PASSWORD_HASH_ALGORITHM", hash.Algorithm))
+	hash.BcryptCost = GetEnvInt("PASSWORD_BCRYPT_COST", hash.BcryptCost)
+	memory := GetEnvInt("PASSWORD_ARGON2_MEMORY_KB", int(hash.Argon2Memory))
+	iterations := GetEnvInt("PASSWORD_ARGON2_ITERATIONS", int(hash.Argon2Iterations))
+	parallelism := GetEnvInt("PASSWORD_ARGON2_PARALLELISM", int(hash.Argon2Parallelism))
+	hash.Pepper = []byte(GetEnv("PASSWORD_PEPPER", ""))
+
+	switch hash.Algorithm {
+	case utils.PasswordHashBcrypt:
+		if hash.BcryptCost < bcrypt.MinCost || hash.BcryptCost > bcrypt.MaxCost {
+			return hash, fmt.Errorf("PASSWORD_BCRYPT_COST harus di antara %d dan %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case utils.PasswordHashArgon2id:
+		if iterations < 1 {
+			return hash, fmt.Errorf("PASSWORD_ARGON2_ITERATIONS harus lebih dari 0")
+		}
+		if parallelism < 1 || parallelism > 255 {
+			return hash, fmt.Errorf("PASSWORD_ARGON2_PARALLELISM harus di antara 1 dan 255")
+		}
+		if memory < 8*parallelism {
+			return hash, fmt.Errorf("PASSWORD_ARGON2_MEMORY_KB minimal 8 x PASSWORD_ARGON2_PARALLELISM")
+		}
+	default:
+		return hash, fmt.Errorf("PASSWORD_HASH_ALGORITHM %q tidak dikenal, gunakan bcrypt atau argon2id", hash.Algorithm)
+	}
+	hash.Argon2Memory = uint32(memory)
+	hash.Argon2Iterations = uint32(iterations)
+	hash.Argon2Parallelism = uint8(parallelism)
+	return hash, nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PasswordlessConfig adalah pengaturan login tanpa password lewat magic link atau kode email
+type PasswordlessConfig struct {
+	Enabled        bool
+	Roles          []string      // Role yang boleh login tanpa password, kosong = semua role
+	LinkURL        string        // URL halaman frontend untuk magic link, token ditambahkan sebagai ?token=
+	TTL            time.Duration // Masa berlaku link dan kode login
+	MaxAttempts    int           // Batas kode salah sebelum kode tidak bisa dipakai lagi
+	ResendInterval time.Duration // Jeda minimum sebelum user bisa meminta link atau kode baru
+	LockoutLimit   int           // Batas kode salah per user dalam LockoutWindow, berlaku lintas kode
+	LockoutWindow  time.Duration // Rentang waktu (bergeser) untuk menghitung LockoutLimit
+}
+
+// AllowsRole menandakan user dengan role ini boleh login tanpa password
+func (c PasswordlessConfig) AllowsRole(role string) bool {
+	if !c.Enabled {
+		return false
+	}
+	if len(c.Roles) == 0 {
+		return true
+	}
+	for _, allowed := range c.Roles {
+		if strings.EqualFold(allowed, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadPasswordlessConfig membaca pengaturan login tanpa password dari env PASSWORDLESS_*
+func LoadPasswordlessConfig() (PasswordlessConfig, error) {
+	cfg := PasswordlessConfig{
+		Enabled:        GetEnvBool("PASSWORDLESS_ENABLED", false),
+		Roles:          GetEnvList("PASSWORDLESS_ROLES"),
+		LinkURL:        GetEnv("PASSWORDLESS_LINK_URL", "http://localhost:3000/login/magic"),
+		TTL:            time.Duration(GetEnvInt("PASSWORDLESS_TTL_MINUTES", 10)) * time.Minute,
+		MaxAttempts:    GetEnvInt("PASSWORDLESS_MAX_ATTEMPTS", 5),
+		ResendInterval: time.Duration(GetEnvInt("PASSWORDLESS_RESEND_SECONDS", 60)) * time.Second,
+		LockoutLimit:   GetEnvInt("PASSWORDLESS_LOCKOUT_ATTEMPTS", 10),
+		LockoutWindow:  time.Duration(GetEnvInt("PASSWORDLESS_LOCKOUT_MINUTES", 15)) * time.Minute,
+	}
+	if cfg.TTL <= 0 {
+		return cfg, fmt.Errorf("PASSWORDLESS_TTL_MINUTES must be positive")
+	}
+	if cfg.MaxAttempts < 1 {
+		return cfg, fmt.Errorf("PASSWORDLESS_MAX_ATTEMPTS must be at least 1")
+	}
+	if cfg.ResendInterval < 0 {
+		return cfg, fmt.Errorf("PASSWORDLESS_RESEND_SECONDS must not be negative")
+	}
+	if cfg.LockoutLimit < 1 {
+		return cfg, fmt.Errorf("PASSWORDLESS_LOCKOUT_ATTEMPTS must be at least 1")
+	}
+	if cfg.LockoutWindow <= 0 {
+		return cfg, fmt.Errorf("PASSWORDLESS_LOCKOUT_MINUTES must be positive")
+	}
+	return cfg, nil
+}
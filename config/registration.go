@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// RegistrationMode menentukan siapa yang boleh mendaftar lewat /api/register
+type RegistrationMode string
+
+const (
+	RegistrationOpen       RegistrationMode = "open"        // Siapa saja boleh mendaftar
+	RegistrationInviteOnly RegistrationMode = "invite_only" // Hanya lewat undangan
+	RegistrationClosed     RegistrationMode = "closed"      // Registrasi publik dimatikan
+)
+
+// RegistrationPolicy adalah aturan registrasi publik
+type RegistrationPolicy struct {
+	Mode           RegistrationMode
+	AllowedDomains []string // Jika diisi, hanya domain email ini yang boleh mendaftar
+	DeniedDomains  []string // Domain email yang selalu ditolak
+	DefaultRole    string   // Nama role yang diberikan server untuk user baru
+
+	// EnumerationSafe membuat /api/register selalu menjawab "cek email Anda" tanpa memberi tahu
+	// apakah email sudah terdaftar. Akun baru dibuat setelah link konfirmasi di email dibuka.
+	EnumerationSafe bool
+	ConfirmURL      string        // URL halaman frontend untuk konfirmasi registrasi, token ditambahkan sebagai ?token=
+	ConfirmTTL      time.Duration // Masa berlaku link konfirmasi registrasi
+}
+
+// LoadRegistrationPolicy membaca aturan registrasi dari env:
+// REGISTRATION_MODE, REGISTRATION_ALLOWED_DOMAINS, REGISTRATION_DENIED_DOMAINS, REGISTRATION_DEFAULT_ROLE,
+// REGISTRATION_ENUMERATION_SAFE, REGISTRATION_CONFIRM_URL dan REGISTRATION_CONFIRM_TTL_HOURS
+func LoadRegistrationPolicy() RegistrationPolicy {
+	mode := RegistrationMode(strings.ToLower(GetEnv("REGISTRATION_MODE", string(RegistrationOpen))))
+	switch mode {
+	case RegistrationOpen, RegistrationInviteOnly, RegistrationClosed:
+	default:
+		// Nilai tidak dikenal dianggap tertutup agar tidak membuka registrasi tanpa sengaja
+		mode = RegistrationClosed
+	}
+
+	return RegistrationPolicy{
+		Mode:           mode,
+		AllowedDomains: lowerAll(GetEnvList("REGISTRATION_ALLOWED_DOMAINS")),
+		DeniedDomains:  lowerAll(GetEnvList("REGISTRATION_DENIED_DOMAINS")),
+		DefaultRole:    GetEnv("REGISTRATION_DEFAULT_ROLE", "user"),
+
+		EnumerationSafe: GetEnvBool("REGISTRATION_ENUMERATION_SAFE", false),
+		ConfirmURL:      GetEnv("REGISTRATION_CONFIRM_URL", "http://localhost:3000/confirm-registration"),
+		ConfirmTTL:      time.Duration(GetEnvInt("REGISTRATION_CONFIRM_TTL_HOURS", 24)) * time.Hour,
+	}
+}
+
+// EmailDomainAllowed mengecek domain email terhadap denylist lalu allowlist
+func (p RegistrationPolicy) EmailDomainAllowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	for _, denied := range p.DeniedDomains {
+		if domain == denied {
+			return false
+		}
+	}
+	if len(p.AllowedDomains) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// lowerAll mengubah semua item menjadi huruf kecil
+func lowerAll(items []string) []string {
+	for i, item := range items {
+		items[i] = strings.ToLower(item)
+	}
+	return items
+}
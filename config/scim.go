@@ -0,0 +1,21 @@
+package config
+
+import "strings"
+
+// SCIMConfig adalah pengaturan API provisioning SCIM 2.0 (/scim/v2)
+type SCIMConfig struct {
+	BaseURL    string // URL dasar endpoint SCIM yang bisa diakses client, dipakai untuk meta.location
+	MaxResults int    // Jumlah resource terbanyak dalam satu halaman list
+}
+
+// LoadSCIMConfig membaca pengaturan SCIM dari env SCIM_BASE_URL dan SCIM_MAX_RESULTS
+func LoadSCIMConfig() SCIMConfig {
+	cfg := SCIMConfig{
+		BaseURL:    strings.TrimRight(GetEnv("SCIM_BASE_URL", "http://localhost:8080/scim/v2"), "/"),
+		MaxResults: GetEnvInt("SCIM_MAX_RESULTS", 200),
+	}
+	if cfg.MaxResults < 1 {
+		cfg.MaxResults = 200
+	}
+	return cfg
+}
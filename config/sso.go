@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SSORoleMapping memetakan satu nilai claim dari identity provider ke nama Role lokal
+type SSORoleMapping struct {
+	Value string // Nilai di claim role, misalnya nama grup "it-admins"
+	Role  string // Nama role lokal, misalnya "admin"
+}
+
+// SSOProvider adalah identity provider OpenID Connect eksternal yang bisa dipakai untuk login
+type SSOProvider struct {
+	ID           string   // Dipakai di URL, contoh /api/sso/corp/start
+	Name         string   // Nama yang ditampilkan di halaman login
+	Issuer       string   // URL issuer, metadata dibaca dari {Issuer}/.well-known/openid-configuration
+	ClientID     string   // Client yang didaftarkan di identity provider
+	ClientSecret string   // Kosong untuk public client (hanya PKCE)
+	Scopes       []string // Scope yang diminta, openid selalu ditambahkan
+	RedirectURL  string   // Halaman callback di frontend yang terdaftar di identity provider
+
+	// Provision membuat user baru saat identitas pertama kali login (just-in-time provisioning)
+	Provision bool
+	// LinkByEmail menautkan identitas baru ke user lokal dengan email yang sama, hanya jika
+	// identity provider menyatakan email sudah diverifikasi (email_verified)
+	LinkByEmail bool
+
+	RoleClaim    string           // Claim berisi grup atau role, contoh "groups". Kosong = role tidak dipetakan
+	RoleMappings []SSORoleMapping // Dicek berurutan, mapping pertama yang cocok dipakai
+	DefaultRole  string           // Role user baru jika tidak ada mapping yang cocok, kosong = REGISTRATION_DEFAULT_ROLE
+}
+
+// SSOConfig adalah pengaturan login lewat identity provider eksternal
+type SSOConfig struct {
+	Providers []SSOProvider
+	StateTTL  time.Duration // Masa berlaku satu percobaan login SSO (state, nonce dan PKCE verifier)
+}
+
+// Provider mencari provider berdasarkan ID
+func (c SSOConfig) Provider(id string) (SSOProvider, bool) {
+	for _, provider := range c.Providers {
+		if provider.ID == id {
+			return provider, true
+		}
+	}
+	return SSOProvider{}, false
+}
+
+// ssoProviderID membatasi ID provider karena dipakai di URL dan nama env
+var ssoProviderID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// LoadSSOConfig membaca daftar provider dari env SSO_PROVIDERS (ID dipisah koma), lalu pengaturan
+// setiap provider dari env SSO_<ID>_*, contoh untuk ID "corp":
+//
+//	SSO_CORP_NAME, SSO_CORP_ISSUER, SSO_CORP_CLIENT_ID, SSO_CORP_CLIENT_SECRET, SSO_CORP_SCOPES,
+//	SSO_CORP_REDIRECT_URL, SSO_CORP_PROVISION, SSO_CORP_LINK_BY_EMAIL, SSO_CORP_ROLE_CLAIM,
+//	SSO_CORP_ROLE_MAP (format "nilai:role,nilai:role") dan SSO_CORP_DEFAULT_ROLE
+//
+// SSO_CALLBACK_URL menjadi dasar redirect URL provider yang tidak mengisi SSO_<ID>_REDIRECT_URL
+// ({SSO_CALLBACK_URL}/{ID}), SSO_STATE_TTL_MINUTES mengatur masa berlaku percobaan login.
+func LoadSSOConfig() (SSOConfig, error) {
+	cfg := SSOConfig{
+		StateTTL: time.Duration(GetEnvInt("SSO_STATE_TTL_MINUTES", 10)) * time.Minute,
+	}
+	callbackURL := strings.TrimRight(GetEnv("SSO_CALLBACK_URL", "http://localhost:3000/sso/callback"), "/")
+
+	for _, id := range lowerAll(GetEnvList("SSO_PROVIDERS")) {
+		// "ldap" dan "scim" dipakai untuk identitas user dari directory LDAP dan client SCIM
+		if !ssoProviderID.MatchString(id) || id == "ldap" || id == "scim" {
+			return cfg, fmt.Errorf("SSO_PROVIDERS: invalid provider id %q", id)
+		}
+		if _, exists := cfg.Provider(id); exists {
+			return cfg, fmt.Errorf("SSO_PROVIDERS: duplicate provider id %q", id)
+		}
+
+		prefix := "SSO_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_"
+		provider := SSOProvider{
+			ID:           id,
+			Name:         GetEnv(prefix+"NAME", id),
+			Issuer:       strings.TrimRight(GetEnv(prefix+"ISSUER", ""), "/"),
+			ClientID:     GetEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: GetEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       GetEnvList(prefix + "SCOPES"),
+			RedirectURL:  GetEnv(prefix+"REDIRECT_URL", callbackURL+"/"+id),
+			Provision:    GetEnvBool(prefix+"PROVISION", true),
+			LinkByEmail:  GetEnvBool(prefix+"LINK_BY_EMAIL", false),
+			RoleClaim:    GetEnv(prefix+"ROLE_CLAIM", ""),
+			DefaultRole:  GetEnv(prefix+"DEFAULT_ROLE", ""),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return cfg, fmt.Errorf("%sISSUER and %sCLIENT_ID are required", prefix, prefix)
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+
+		for _, item := range GetEnvList(prefix + "ROLE_MAP") {
+			value, role, ok := strings.Cut(item, ":")
+			value, role = strings.TrimSpace(value), strings.TrimSpace(role)
+			if !ok || value == "" || role == "" {
+				return cfg, fmt.Errorf("%sROLE_MAP: expected value:role, got %q", prefix, item)
+			}
+			provider.RoleMappings = append(provider.RoleMappings, SSORoleMapping{Value: value, Role: role})
+		}
+		if len(provider.RoleMappings) > 0 && provider.RoleClaim == "" {
+			return cfg, fmt.Errorf("%sROLE_CLAIM is required when %sROLE_MAP is set", prefix, prefix)
+		}
+
+		cfg.Providers = append(cfg.Providers, provider)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"      // Framework web Gin
+	"golang-starter-kit/middleware" // Identitas user dan session yang sedang login
+	"golang-starter-kit/services"   // Aturan bisnis (akun)
+	"golang-starter-kit/utils"      // Helper (response)
+)
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangeMyPassword mengganti password user yang sedang login dan mencabut session lainnya
+func ChangeMyPassword(c *gin.Context) {
+	var input ChangePasswordInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	revoked, err := accountService.ChangePassword(c.Request.Context(), services.ChangePasswordParams{
+		UserID:          middleware.CurrentUserID(c),
+		SessionID:       middleware.CurrentSessionID(c),
+		CurrentPassword: input.CurrentPassword,
+		NewPassword:     input.NewPassword,
+	})
+	if err != nil {
+		respondError(c, err, "Gagal mengganti password")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Password berhasil diganti", gin.H{"revoked_sessions": revoked}))
+}
+
+type ChangeEmailInput struct {
+	Password string `json:"password" binding:"required"`
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Locale   string `json:"locale" binding:"omitempty,max=10"` // Bahasa email konfirmasi, contoh: id, en
+}
+
+// ChangeMyEmail mengirim link konfirmasi ke email baru milik user yang sedang login
+func ChangeMyEmail(c *gin.Context) {
+	var input ChangeEmailInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	change, err := accountService.RequestEmailChange(c.Request.Context(), services.RequestEmailChangeParams{
+		UserID:   middleware.CurrentUserID(c),
+		Password: input.Password,
+		NewEmail: input.NewEmail,
+		Locale:   input.Locale,
+	})
+	if err != nil {
+		respondError(c, err, "Gagal meminta ganti email")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Link konfirmasi telah dikirim ke email baru", change))
+}
+
+type ConfirmEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ConfirmMyEmail mengganti email user dari link konfirmasi
+func ConfirmMyEmail(c *gin.Context) {
+	var input ConfirmEmailInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	user, err := accountService.ConfirmEmailChange(c.Request.Context(), input.Token)
+	if err != nil {
+		respondError(c, err, "Gagal mengonfirmasi email")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Email berhasil diganti", user))
+}
@@ -0,0 +1,132 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+	_, laptop := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+	_, phone := login(t, h, user.Email, testutil.DefaultPassword, "Phone")
+
+	rec := h.Request(t, http.MethodPost, "/api/me/password", map[string]string{
+		"current_password": "salah123",
+		"new_password":     "baru12345",
+	}, laptop)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Password saat ini salah")
+
+	rec = h.Request(t, http.MethodPost, "/api/me/password", map[string]string{
+		"current_password": testutil.DefaultPassword,
+		"new_password":     testutil.DefaultPassword,
+	}, laptop)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Password tidak memenuhi kebijakan")
+
+	rec = h.Request(t, http.MethodPost, "/api/me/password", map[string]string{
+		"current_password": testutil.DefaultPassword,
+		"new_password":     "baru12345",
+	}, laptop)
+	var data struct {
+		RevokedSessions int `json:"revoked_sessions"`
+	}
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Password berhasil diganti", &data)
+	if data.RevokedSessions != 1 {
+		t.Fatalf("revoked_sessions = %d, want 1", data.RevokedSessions)
+	}
+
+	// Session lain dicabut, session yang dipakai untuk mengganti password tetap aktif
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, phone)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, laptop)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	rec, _ = login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	rec, _ = login(t, h, user.Email, "baru12345", "Laptop")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+}
+
+func TestChangeEmailFlow(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+	token := h.Token(t, user)
+
+	rec := h.Request(t, http.MethodPost, "/api/me/email", map[string]string{
+		"password":  "salah123",
+		"new_email": "budi.baru@example.com",
+	}, token)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Password saat ini salah")
+
+	rec = h.Request(t, http.MethodPost, "/api/me/email", map[string]string{
+		"password":  testutil.DefaultPassword,
+		"new_email": "budi.baru@example.com",
+	}, token)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Link konfirmasi telah dikirim ke email baru", nil)
+
+	// Email belum berubah sebelum dikonfirmasi
+	var stored models.User
+	h.DB.First(&stored, user.ID)
+	if stored.Email != "budi@example.com" {
+		t.Fatalf("email changed before confirmation: %s", stored.Email)
+	}
+
+	link := linkToken(t, h, "budi.baru@example.com")
+	rec = h.Request(t, http.MethodPost, "/api/me/email/confirm", map[string]string{"token": link}, "")
+	var updated models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Email berhasil diganti", &updated)
+	if updated.Email != "budi.baru@example.com" {
+		t.Fatalf("email = %s, want budi.baru@example.com", updated.Email)
+	}
+
+	// Pemberitahuan dikirim ke alamat lama
+	notice, ok := h.Mailer.Last("budi@example.com")
+	if !ok || notice.Subject != "Email akun Anda telah diganti" {
+		t.Fatalf("unexpected notice: %+v", notice)
+	}
+
+	// Link hanya bisa dipakai sekali
+	rec = h.Request(t, http.MethodPost, "/api/me/email/confirm", map[string]string{"token": link}, "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Link konfirmasi email tidak valid")
+}
+
+func TestChangeEmailRejectsTakenAndExpired(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+	h.CreateUser(t, testutil.UserAttrs{Email: "ani@example.com"})
+	token := h.Token(t, user)
+
+	rec := h.Request(t, http.MethodPost, "/api/me/email", map[string]string{
+		"password":  testutil.DefaultPassword,
+		"new_email": "ani@example.com",
+	}, token)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Email sudah terdaftar")
+
+	rec = h.Request(t, http.MethodPost, "/api/me/email", map[string]string{
+		"password":  testutil.DefaultPassword,
+		"new_email": "budi@example.com",
+	}, token)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Email baru sama dengan email saat ini")
+
+	// Permintaan baru membatalkan link sebelumnya
+	h.Request(t, http.MethodPost, "/api/me/email", map[string]string{
+		"password":  testutil.DefaultPassword,
+		"new_email": "budi.lama@example.com",
+	}, token)
+	oldLink := linkToken(t, h, "budi.lama@example.com")
+	h.Request(t, http.MethodPost, "/api/me/email", map[string]string{
+		"password":  testutil.DefaultPassword,
+		"new_email": "budi.baru@example.com",
+	}, token)
+	rec = h.Request(t, http.MethodPost, "/api/me/email/confirm", map[string]string{"token": oldLink}, "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Link konfirmasi email tidak valid")
+
+	link := linkToken(t, h, "budi.baru@example.com")
+	h.Clock.Advance(25 * time.Hour)
+	rec = h.Request(t, http.MethodPost, "/api/me/email/confirm", map[string]string{"token": link}, "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Link konfirmasi email sudah kadaluwarsa")
+}
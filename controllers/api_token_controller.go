@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"      // Framework web Gin
+	"golang-starter-kit/middleware" // Identitas user yang sedang login
+	"golang-starter-kit/services"   // Aturan bisnis (token)
+	"golang-starter-kit/utils"      // Helper (response)
+)
+
+type CreateAPITokenInput struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 = masa berlaku default
+}
+
+// GetMyTokens menampilkan personal access token aktif milik user yang sedang login
+func GetMyTokens(c *gin.Context) {
+	tokens, err := apiTokenService.List(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data token")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar token", tokens))
+}
+
+// CreateMyToken membuat personal access token baru. Token hanya ditampilkan sekali di response ini.
+func CreateMyToken(c *gin.Context) {
+	var input CreateAPITokenInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	token, err := apiTokenService.Create(c.Request.Context(), services.CreateAPITokenParams{
+		UserID:        middleware.CurrentUserID(c),
+		Name:          input.Name,
+		Scopes:        input.Scopes,
+		ExpiresInDays: input.ExpiresInDays,
+	})
+	if err != nil {
+		respondError(c, err, "Gagal membuat token")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Token berhasil dibuat, simpan sekarang karena tidak akan ditampilkan lagi", token))
+}
+
+// RevokeMyToken mencabut personal access token milik user yang sedang login
+func RevokeMyToken(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrAPITokenNotFound, "")
+		return
+	}
+
+	if err := apiTokenService.Revoke(c.Request.Context(), middleware.CurrentUserID(c), id); err != nil {
+		respondError(c, err, "Gagal mencabut token")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Token berhasil dicabut", nil))
+}
@@ -0,0 +1,129 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+)
+
+// createToken membuat personal access token lewat /api/me/tokens dan mengembalikan token aslinya
+func createToken(t *testing.T, h *testutil.Harness, user models.User, body map[string]interface{}) (string, models.APIToken) {
+	t.Helper()
+	rec := h.AuthRequest(t, user, http.MethodPost, "/api/me/tokens", body)
+	var data struct {
+		Token    string          `json:"token"`
+		APIToken models.APIToken `json:"api_token"`
+	}
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Token berhasil dibuat, simpan sekarang karena tidak akan ditampilkan lagi", &data)
+	return data.Token, data.APIToken
+}
+
+// apiKeyRequest mengirim request dengan token di header X-API-Key
+func apiKeyRequest(h *testutil.Harness, method, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, http.NoBody)
+	req.Header.Set("X-API-Key", key)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPITokenAuthAndScopes(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	plain, token := createToken(t, h, user, map[string]interface{}{
+		"name":   "CI",
+		"scopes": []string{models.ScopeUsersRead, models.ScopeUsersRead},
+	})
+
+	if !strings.HasPrefix(plain, models.APITokenPrefix) || !strings.HasPrefix(plain, token.Prefix) {
+		t.Fatalf("token = %q, prefix = %q", plain, token.Prefix)
+	}
+	if len(token.Scopes) != 1 || token.Scopes[0] != models.ScopeUsersRead {
+		t.Fatalf("scopes = %v, want [%s]", token.Scopes, models.ScopeUsersRead)
+	}
+	if want := testutil.DefaultTime.Add(90 * 24 * time.Hour); !token.ExpiresAt.Equal(want) {
+		t.Fatalf("expires_at = %v, want %v", token.ExpiresAt, want)
+	}
+
+	// Hanya hash yang disimpan
+	var stored models.APIToken
+	h.DB.First(&stored, token.ID)
+	if stored.TokenHash == "" || strings.Contains(stored.TokenHash, plain) {
+		t.Fatalf("token hash = %q", stored.TokenHash)
+	}
+
+	// Token diterima lewat Authorization: Bearer dan X-API-Key
+	rec := h.Request(t, http.MethodGet, "/api/user/", nil, plain)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	rec = apiKeyRequest(h, http.MethodGet, "/api/user/"+strconv.Itoa(int(user.ID)), plain)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	h.DB.First(&stored, token.ID)
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(testutil.DefaultTime) {
+		t.Fatalf("last_used_at = %v, want %v", stored.LastUsedAt, testutil.DefaultTime)
+	}
+
+	// Scope yang tidak dimiliki token ditolak
+	rec = h.Request(t, http.MethodPost, "/api/user/", map[string]string{"name": "Baru"}, plain)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+	rec = h.Request(t, http.MethodGet, "/api/role/", nil, plain)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+
+	// Token tidak bisa dipakai untuk mengelola akun, termasuk membuat token baru
+	rec = h.Request(t, http.MethodGet, "/api/me/tokens", nil, plain)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+
+	// Token yang tidak dikenal ditolak
+	rec = apiKeyRequest(h, http.MethodGet, "/api/user/", models.APITokenPrefix+"unknown")
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestAPITokenRevokeAndExpiry(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	other := h.CreateUser(t, testutil.UserAttrs{})
+	revoked, revokedToken := createToken(t, h, user, map[string]interface{}{"name": "Lama", "scopes": []string{models.ScopeUsersRead}})
+	expiring, _ := createToken(t, h, user, map[string]interface{}{"name": "Sementara", "scopes": []string{models.ScopeUsersRead}, "expires_in_days": 1})
+
+	// Token milik user lain tidak bisa dicabut
+	path := "/api/me/tokens/" + strconv.Itoa(int(revokedToken.ID))
+	rec := h.AuthRequest(t, other, http.MethodDelete, path, nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, services.ErrAPITokenNotFound.Message)
+
+	rec = h.AuthRequest(t, user, http.MethodDelete, path, nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Token berhasil dicabut", nil)
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, revoked)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+
+	rec = h.AuthRequest(t, user, http.MethodGet, "/api/me/tokens", nil)
+	var tokens []models.APIToken
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar token", &tokens)
+	if len(tokens) != 1 || tokens[0].Name != "Sementara" {
+		t.Fatalf("unexpected tokens: %+v", tokens)
+	}
+
+	// Token kadaluwarsa ditolak
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, expiring)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	h.Clock.Advance(25 * time.Hour)
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, expiring)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestCreateAPITokenValidation(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, user, http.MethodPost, "/api/me/tokens", map[string]interface{}{"name": "CI", "scopes": []string{"everything"}})
+	testutil.AssertError(t, rec, http.StatusBadRequest, services.ErrInvalidTokenScope.Message)
+
+	rec = h.AuthRequest(t, user, http.MethodPost, "/api/me/tokens", map[string]interface{}{"name": "CI", "scopes": []string{models.ScopeUsersRead}, "expires_in_days": 366})
+	testutil.AssertError(t, rec, http.StatusBadRequest, services.ErrInvalidTokenExpiry.Message)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"        // Framework web Gin
+	"golang-starter-kit/repositories" // Filter audit log
+	"golang-starter-kit/services"     // Aturan bisnis (audit)
+	"golang-starter-kit/utils"        // Helper (response, clock)
+)
+
+// GetAuditLogs menampilkan audit log per halaman (?page=, ?per_page=), bisa difilter dengan
+// ?actor_id=, ?action=, ?target_type=, ?target_id=, ?from= dan ?to= (RFC3339)
+func GetAuditLogs(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+
+	result, err := auditService.List(c.Request.Context(), filter, page, perPage)
+	if err != nil {
+		respondError(c, err, "Gagal mengambil audit log")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar audit log", result))
+}
+
+// ExportAuditLogs mengunduh audit log sebagai JSON Lines dengan filter yang sama seperti GetAuditLogs
+func ExportAuditLogs(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.jsonl", utils.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// Header sudah terkirim, error di tengah export hanya bisa memutus stream
+	if err := auditService.Export(c.Request.Context(), filter, c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// VerifyAuditLogs memeriksa rantai hash dan checkpoint audit log
+func VerifyAuditLogs(c *gin.Context) {
+	report, err := auditService.Verify(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Gagal memverifikasi audit log")
+		return
+	}
+
+	// Data berhasil di ambil
+	message := "Audit log valid"
+	if !report.Valid {
+		message = "Audit log tidak valid"
+	}
+	c.JSON(http.StatusOK, utils.APIResponseSuccess(message, report))
+}
+
+// GetAuditCheckpoints menampilkan semua checkpoint audit log yang sudah ditandatangani
+func GetAuditCheckpoints(c *gin.Context) {
+	checkpoints, err := auditService.ListCheckpoints(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Gagal mengambil checkpoint audit")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar checkpoint audit", checkpoints))
+}
+
+// ExportAuditCheckpoints mengunduh semua checkpoint sebagai JSON Lines untuk diarsipkan di luar sistem
+func ExportAuditCheckpoints(c *gin.Context) {
+	filename := fmt.Sprintf("audit-checkpoints-%s.jsonl", utils.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	if err := auditService.ExportCheckpoints(c.Request.Context(), c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// auditFilter membaca filter audit log dari query string, mengirim response 400 dan false jika tidak valid
+func auditFilter(c *gin.Context) (repositories.AuditFilter, bool) {
+	filter := repositories.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+
+	if value := c.Query("actor_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			respondError(c, services.ErrInvalidAuditFilter, "")
+			return filter, false
+		}
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+	for key, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondError(c, services.ErrInvalidAuditFilter, "")
+			return filter, false
+		}
+		*dest = &t
+	}
+	return filter, true
+}
@@ -0,0 +1,170 @@
+package controllers_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+)
+
+type auditPage struct {
+	Items   []models.AuditLog `json:"items"`
+	Page    int               `json:"page"`
+	PerPage int               `json:"per_page"`
+	Total   int64             `json:"total"`
+}
+
+func TestAuditRecordsMutationsWithRequestContext(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	body, _ := json.Marshal(map[string]string{"name": "Siti", "email": "siti@example.com", "password": "rahasia123"})
+	req := httptest.NewRequest(http.MethodPost, "/api/user/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+h.Token(t, admin))
+	req.Header.Set("User-Agent", "audit-test/1.0")
+	req.Header.Set("X-Request-ID", "req-123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var created models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dibuat", &created)
+	if rec.Header().Get("X-Request-ID") != "req-123" {
+		t.Fatalf("request id not echoed: %q", rec.Header().Get("X-Request-ID"))
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodPut, fmt.Sprintf("/api/user/%d", created.ID), map[string]string{"name": "Siti Aminah"})
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil diupdate", nil)
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/admin/audit?target_type=user", nil)
+	var page auditPage
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar audit log", &page)
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("unexpected audit page: %+v", page)
+	}
+
+	// Terbaru lebih dulu
+	update, create := page.Items[0], page.Items[1]
+	if create.Action != "user.create" || create.ActorID == nil || *create.ActorID != admin.ID ||
+		create.RequestID != "req-123" || create.UserAgent != "audit-test/1.0" || create.IP == "" ||
+		create.TargetID != fmt.Sprint(created.ID) {
+		t.Fatalf("unexpected create entry: %+v", create)
+	}
+	if strings.Contains(string(create.Changes), "password") {
+		t.Fatalf("password must not be recorded: %s", create.Changes)
+	}
+
+	// Diff update hanya berisi field yang berubah
+	var changes map[string]map[string]interface{}
+	if err := json.Unmarshal(update.Changes, &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes["name"]["before"] != "Siti" || changes["name"]["after"] != "Siti Aminah" {
+		t.Fatalf("unexpected update diff: %s", update.Changes)
+	}
+	if update.RequestID == "" || update.RequestID == "req-123" {
+		t.Fatalf("a request id should be generated per request: %q", update.RequestID)
+	}
+}
+
+func TestAuditListFiltersAndPagination(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	for _, name := range []string{"editor", "viewer", "author"} {
+		rec := h.AuthRequest(t, admin, http.MethodPost, "/api/role/", map[string]string{"name": name})
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Role berhasil dibuat", nil)
+	}
+
+	rec := h.AuthRequest(t, admin, http.MethodGet, "/api/admin/audit?action=role.create&page=2&per_page=2", nil)
+	var page auditPage
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar audit log", &page)
+	if page.Total != 3 || len(page.Items) != 1 || page.Page != 2 || page.PerPage != 2 {
+		t.Fatalf("unexpected audit page: %+v", page)
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, fmt.Sprintf("/api/admin/audit?actor_id=%d&from=2025-01-01T10:00:00Z", admin.ID), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar audit log", &page)
+	if page.Total != 0 {
+		t.Fatalf("from filter should exclude earlier entries: %+v", page)
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/admin/audit?from=kemarin", nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, services.ErrInvalidAuditFilter.Message)
+
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	rec = h.AuthRequest(t, user, http.MethodGet, "/api/admin/audit", nil)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+}
+
+func TestAuditExportJSONLines(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	for _, name := range []string{"editor", "viewer"} {
+		h.AuthRequest(t, admin, http.MethodPost, "/api/role/", map[string]string{"name": name})
+	}
+
+	rec := h.AuthRequest(t, admin, http.MethodGet, "/api/admin/audit/export?target_type=role", nil)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	var actions []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var entry models.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 2 || actions[0] != "role.create" {
+		t.Fatalf("unexpected export: %v", actions)
+	}
+}
+
+func TestAuditLogIsImmutable(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	h.AuthRequest(t, admin, http.MethodPost, "/api/role/", map[string]string{"name": "editor"})
+
+	var entry models.AuditLog
+	if err := h.DB.First(&entry).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.Model(&entry).Update("action", "role.delete").Error; err == nil {
+		t.Fatal("updating an audit log should fail")
+	}
+	if err := h.DB.Delete(&entry).Error; err == nil {
+		t.Fatal("deleting an audit log should fail")
+	}
+}
+
+func TestAuditVerifyEndpoint(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	h.AuthRequest(t, admin, http.MethodPost, "/api/role/", map[string]string{"name": "editor"})
+	h.AuthRequest(t, admin, http.MethodPost, "/api/role/", map[string]string{"name": "viewer"})
+
+	rec := h.AuthRequest(t, admin, http.MethodGet, "/api/admin/audit/verify", nil)
+	var report services.AuditVerifyReport
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Audit log valid", &report)
+	if report.Checked != 2 || !report.Valid {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	h.DB.Model(&models.AuditLog{ID: 1}).UpdateColumn("target_id", "999")
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/admin/audit/verify", nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Audit log tidak valid", &report)
+	if report.Valid || len(report.Breaks) != 1 || report.Breaks[0].ID != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
@@ -2,29 +2,25 @@ package controllers
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"time"
-	"github.com/gin-gonic/gin"		// Framework web Gin
-	"github.com/golang-jwt/jwt/v5"	// JWT untuk autentikasi
-	"golang.org/x/crypto/bcrypt"  	// Untuk hashing password
-	"golang-starter-kit/models"   	// Model database
-	"golang-starter-kit/utils"    	// Helper (response, jwt, blacklist)
+
+	"github.com/gin-gonic/gin"    // Framework web Gin
+	"golang-starter-kit/services" // Aturan bisnis (auth)
+	"golang-starter-kit/utils"    // Helper (response)
 )
 
 // RegisterInput adalah struktur data yang digunakan saat register
 type RegisterInput struct {
 	Name     string `json:"name" binding:"required,min=3"`
 	Email    string `json:"email" binding:"required,email,min=6"`
-	Password string `json:"password" binding:"required,min=6"`
-	IDRole   uint   `json:"id_role" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	IDRole   uint   `json:"id_role"` // Hanya dipakai jika yang mendaftarkan adalah admin
 }
 
 func Register(c *gin.Context) {
-	var user models.User
 	var input RegisterInput
 
-	// ------ Validasi ------ //
 	// Input Validation
 	ok, resp := utils.InputValidation(c, &input)
 	if !ok {
@@ -32,39 +28,47 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// Validasi format password (hanya a-z, A-Z, 0-9, @, #, $)
-	if !utils.InputValidationPasswordCriteria(input.Password) {
-		c.JSON(http.StatusBadRequest, utils.APIResponseError(
-			"Password hanya boleh berisi huruf, angka, dan karakter @, #, $", nil))
+	// Daftarkan user baru, role dari client diabaikan kecuali dikirim oleh admin
+	user, err := authService.Register(c.Request.Context(), services.CreateUserParams{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: input.Password,
+		IDRole:   assignableRoleID(c, input.IDRole),
+	})
+	if err != nil {
+		respondError(c, err, "Gagal menyimpan data ke database")
 		return
 	}
 
-	// Cek apakah email sudah terdaftar
-	if err := models.DB.Where("email = ?", input.Email).First(&user).Error; err == nil {
-		c.JSON(http.StatusBadRequest, utils.APIResponseError("Email sudah terdaftar", nil))
+	// Registrasi aman dari enumerasi: response selalu sama, akun dibuat setelah konfirmasi email
+	if user == nil {
+		c.JSON(http.StatusOK, utils.APIResponseSuccess("Silakan cek email Anda untuk menyelesaikan registrasi", nil))
 		return
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal mengenkripsi password", nil))
+	// Kirim response sukses dengan data user yang baru dibuat
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Registrasi berhasil", user))
+}
+
+// ConfirmRegistrationInput adalah token dari link konfirmasi registrasi
+type ConfirmRegistrationInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ConfirmRegistration membuat akun dari link konfirmasi registrasi
+func ConfirmRegistration(c *gin.Context) {
+	var input ConfirmRegistrationInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
 		return
 	}
-	// ------ END Validasi ------ //
-
-	// Buat object user baru dengan data dari input
-	user = models.User{
-		Name:      input.Name,
-		Email:     input.Email,
-		Password:  string(hashedPassword),
-		IDRole:    input.IDRole,
-		CreatedAt: time.Now(),
-	}
 
-	// Simpan user baru ke database
-	if err := models.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal menyimpan data ke database", nil))
+	user, err := authService.ConfirmRegistration(c.Request.Context(), input.Token)
+	if err != nil {
+		respondError(c, err, "Gagal mengonfirmasi registrasi")
 		return
 	}
 
@@ -98,33 +102,23 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Ambil user dari database berdasarkan email, sekaligus preload relasi role-nya
-	var user models.User
-
-	// Check Email ada atau tidak
-	if err := models.DB.Preload("Role").Where("email = ?", input.Email).Where("deleted_at IS NULL").First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, utils.APIResponseError("Email tidak ditemukan", nil))
-		return
-	}
-
-	// Cek apakah password yang diinput cocok dengan password yang di-hash di database
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, utils.APIResponseError("Password yang anda masukan salah", nil))
-		return
-	}
-
-	// Generate token JWT berdasarkan ID dan email user
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	// Verifikasi email dan password, lalu terbitkan token
+	result, err := authService.Login(c.Request.Context(), input.Email, input.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal membuat token", nil))
+		respondError(c, err, "Gagal login")
 		return
 	}
 
-	// Siapkan data response yang berisi token dan informasi user
-	expiredAt := time.Now().Add(time.Hour * 24) // expired JWT 24 jam, sesuaikan jika perlu
-	data := gin.H{
-		"expired": expiredAt.Format(time.RFC3339),
-		"token":   token,
+	// Kirim response sukses dengan data user dan token
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Login berhasil", loginData(result)))
+}
+
+// loginData menyiapkan data response login yang berisi token dan informasi user
+func loginData(result *services.LoginResult) gin.H {
+	user := result.User
+	return gin.H{
+		"expired": result.ExpiresAt.Format(time.RFC3339),
+		"token":   result.Token,
 		"user": gin.H{
 			"id":      user.ID,
 			"name":    user.Name,
@@ -136,9 +130,6 @@ func Login(c *gin.Context) {
 			},
 		},
 	}
-
-	// Kirim response sukses dengan data user dan token
-	c.JSON(http.StatusOK, utils.APIResponseSuccess("Login berhasil", data))
 }
 
 func Logout(c *gin.Context) {
@@ -146,26 +137,12 @@ func Logout(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-	// Parse dan validasi token JWT
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
-
-	// Jika token tidak valid, kirim response error
-	if err != nil || !token.Valid {
-		c.JSON(http.StatusUnauthorized, utils.APIResponseError("Token tidak valid", nil))
+	// Masukkan token ke blacklist
+	if err := authService.Logout(c.Request.Context(), tokenString); err != nil {
+		respondError(c, err, "Gagal logout")
 		return
 	}
 
-	// Ambil waktu kadaluarsa dari token, dan tambahkan ke blacklist
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if exp, ok := claims["exp"].(float64); ok {
-			// Konversi timestamp ke waktu dan tambahkan ke daftar blacklist
-			expTime := time.Unix(int64(exp), 0)
-			utils.AddToBlacklist(tokenString, expTime)
-		}
-	}
-
 	// Kirim response logout sukses
 	c.JSON(http.StatusOK, utils.APIResponseSuccess("Berhasil logout", nil))
 }
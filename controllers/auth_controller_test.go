@@ -0,0 +1,347 @@
+package controllers_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+func TestRegister(t *testing.T) {
+	h := testutil.New(t)
+	role := h.CreateRole(t, testutil.RoleAttrs{})
+
+	rec := h.Request(t, http.MethodPost, "/api/register", map[string]interface{}{
+		"name":     "Budi",
+		"email":    "budi@example.com",
+		"password": "rahasia123",
+		"id_role":  role.ID,
+	}, "")
+
+	var user models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", &user)
+	if user.ID == 0 || user.Email != "budi@example.com" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
+
+func TestRegisterDuplicateEmail(t *testing.T) {
+	h := testutil.New(t)
+	existing := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.Request(t, http.MethodPost, "/api/register", map[string]interface{}{
+		"name":     "Budi",
+		"email":    existing.Email,
+		"password": "rahasia123",
+		"id_role":  existing.IDRole,
+	}, "")
+
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Email sudah terdaftar")
+}
+
+func TestRegisterValidation(t *testing.T) {
+	h := testutil.New(t)
+
+	rec := h.Request(t, http.MethodPost, "/api/register", map[string]interface{}{
+		"name":  "Budi",
+		"email": "bukan-email",
+	}, "")
+
+	testutil.AssertError(t, rec, http.StatusBadRequest, "")
+}
+
+func TestLogin(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.Request(t, http.MethodPost, "/api/login", map[string]string{
+		"email":    user.Email,
+		"password": testutil.DefaultPassword,
+	}, "")
+
+	var data struct {
+		Token string `json:"token"`
+		User  struct {
+			ID uint `json:"id"`
+		} `json:"user"`
+	}
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", &data)
+	if data.Token == "" || data.User.ID != user.ID {
+		t.Fatalf("unexpected login data: %+v", data)
+	}
+
+	// Token hasil login bisa dipakai untuk endpoint yang dilindungi
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, data.Token)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar user", nil)
+}
+
+func TestLoginFailures(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.Request(t, http.MethodPost, "/api/login", map[string]string{
+		"email":    user.Email,
+		"password": "salah123",
+	}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, "Email atau password salah")
+	wrongPassword := rec.Body.String()
+
+	// Email yang tidak terdaftar mendapat response yang persis sama
+	rec = h.Request(t, http.MethodPost, "/api/login", map[string]string{
+		"email":    "tidak-ada@example.com",
+		"password": testutil.DefaultPassword,
+	}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, "Email atau password salah")
+	if rec.Body.String() != wrongPassword {
+		t.Fatalf("responses differ:\n%s\n%s", wrongPassword, rec.Body.String())
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/login", map[string]string{}, "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Email dan Password tidak boleh kosong")
+}
+
+func TestLogoutBlacklistsToken(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	token := h.Token(t, user)
+
+	rec := h.Request(t, http.MethodPost, "/api/logout", nil, token)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Berhasil logout", nil)
+
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestProtectedRouteRequiresToken(t *testing.T) {
+	h := testutil.New(t)
+
+	rec := h.Request(t, http.MethodGet, "/api/user/", nil, "")
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, "bukan-token")
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestTokenExpiresWithClock(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	token := h.Token(t, user)
+
+	h.Clock.Advance(23 * time.Hour)
+	rec := h.Request(t, http.MethodGet, "/api/user/", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	h.Clock.Advance(2 * time.Hour)
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestLoginUsesClockForExpiry(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	if !user.CreatedAt.Equal(testutil.DefaultTime) {
+		t.Fatalf("created_at = %v, want %v", user.CreatedAt, testutil.DefaultTime)
+	}
+
+	rec := h.Request(t, http.MethodPost, "/api/login", map[string]string{
+		"email":    user.Email,
+		"password": testutil.DefaultPassword,
+	}, "")
+
+	var data struct {
+		Expired string `json:"expired"`
+	}
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", &data)
+	want := testutil.DefaultTime.Add(24 * time.Hour).Format(time.RFC3339)
+	if data.Expired != want {
+		t.Fatalf("expired = %q, want %q", data.Expired, want)
+	}
+}
+
+func registerBody(email string, idRole uint) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     "Budi",
+		"email":    email,
+		"password": "rahasia123",
+		"id_role":  idRole,
+	}
+}
+
+func TestRegisterIgnoresClientRole(t *testing.T) {
+	h := testutil.New(t)
+	adminRole := h.SystemRole(t, models.AdminRoleName)
+	defaultRole := h.SystemRole(t, models.UserRoleName)
+
+	// Anonim tidak bisa memilih role admin
+	rec := h.Request(t, http.MethodPost, "/api/register", registerBody("budi@example.com", adminRole.ID), "")
+	var user models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", &user)
+	if user.IDRole != defaultRole.ID {
+		t.Fatalf("id_role = %d, want default role %d", user.IDRole, defaultRole.ID)
+	}
+
+	// Admin boleh menentukan role
+	admin := h.CreateAdmin(t)
+	rec = h.AuthRequest(t, admin, http.MethodPost, "/api/register", registerBody("andi@example.com", adminRole.ID))
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", &user)
+	if user.IDRole != adminRole.ID {
+		t.Fatalf("id_role = %d, want %d", user.IDRole, adminRole.ID)
+	}
+}
+
+func TestRegisterDefaultRoleFromConfig(t *testing.T) {
+	t.Setenv("REGISTRATION_DEFAULT_ROLE", "member")
+	h := testutil.New(t)
+	member := h.CreateRole(t, testutil.RoleAttrs{Name: "member"})
+
+	rec := h.Request(t, http.MethodPost, "/api/register", registerBody("budi@example.com", 0), "")
+	var user models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", &user)
+	if user.IDRole != member.ID {
+		t.Fatalf("id_role = %d, want %d", user.IDRole, member.ID)
+	}
+}
+
+func TestRegisterModes(t *testing.T) {
+	cases := []struct {
+		mode    string
+		message string
+	}{
+		{"closed", "Registrasi publik sedang ditutup"},
+		{"invite_only", "Registrasi hanya dapat dilakukan melalui undangan"},
+		{"typo", "Registrasi publik sedang ditutup"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			t.Setenv("REGISTRATION_MODE", tc.mode)
+			h := testutil.New(t)
+
+			rec := h.Request(t, http.MethodPost, "/api/register", registerBody("budi@example.com", 0), "")
+			testutil.AssertError(t, rec, http.StatusForbidden, tc.message)
+		})
+	}
+}
+
+func TestRegisterEmailDomains(t *testing.T) {
+	t.Setenv("REGISTRATION_ALLOWED_DOMAINS", "example.com, Example.org")
+	t.Setenv("REGISTRATION_DENIED_DOMAINS", "example.org")
+	h := testutil.New(t)
+
+	for _, email := range []string{"budi@gmail.com", "budi@EXAMPLE.org"} {
+		rec := h.Request(t, http.MethodPost, "/api/register", registerBody(email, 0), "")
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Domain email tidak diizinkan untuk registrasi")
+	}
+
+	rec := h.Request(t, http.MethodPost, "/api/register", registerBody("budi@Example.com", 0), "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", nil)
+}
+
+func TestRegisterRejectsBreachedPassword(t *testing.T) {
+	// Dataset range HIBP berisi satu hash: SHA-1("rahasia123")
+	dir := t.TempDir()
+	hash := sha1.Sum([]byte("rahasia123"))
+	full := strings.ToUpper(hex.EncodeToString(hash[:]))
+	if err := os.WriteFile(filepath.Join(dir, full[:5]), []byte(full[5:]+":42\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BREACHED_PASSWORDS_PATH", dir)
+	h := testutil.New(t)
+
+	rec := h.Request(t, http.MethodPost, "/api/register", map[string]interface{}{
+		"name":     "Budi",
+		"email":    "budi@example.com",
+		"password": "rahasia123",
+	}, "")
+	resp := testutil.AssertError(t, rec, http.StatusBadRequest, "Password tidak memenuhi kebijakan")
+	if !strings.Contains(string(resp.Data), `"rule":"breached"`) {
+		t.Fatalf("unexpected violations: %s", resp.Data)
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/register", map[string]interface{}{
+		"name":     "Budi",
+		"email":    "budi@example.com",
+		"password": "rahasia124",
+	}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", nil)
+}
+
+func TestLoginUpgradesLegacyPasswordHash(t *testing.T) {
+	h := testutil.New(t)
+	// Factory menyimpan hash bcrypt, sedangkan aplikasi memakai argon2id
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.Request(t, http.MethodPost, "/api/login", map[string]string{
+		"email":    user.Email,
+		"password": testutil.DefaultPassword,
+	}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", nil)
+
+	var stored models.User
+	h.DB.First(&stored, user.ID)
+	if !strings.HasPrefix(stored.Password, "$argon2id$") {
+		t.Fatalf("password hash not upgraded: %s", stored.Password)
+	}
+
+	// Login berikutnya memakai hash baru
+	rec = h.Request(t, http.MethodPost, "/api/login", map[string]string{
+		"email":    user.Email,
+		"password": testutil.DefaultPassword,
+	}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", nil)
+}
+
+func TestRegisterEnumerationSafe(t *testing.T) {
+	t.Setenv("REGISTRATION_ENUMERATION_SAFE", "true")
+	h := testutil.New(t)
+	existing := h.CreateUser(t, testutil.UserAttrs{Email: "ada@example.com"})
+
+	// Email baru dan email yang sudah terdaftar mendapat response yang sama
+	var bodies []string
+	for _, email := range []string{"baru@example.com", existing.Email} {
+		rec := h.Request(t, http.MethodPost, "/api/register", map[string]interface{}{
+			"name":     "Budi",
+			"email":    email,
+			"password": "rahasia123",
+		}, "")
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Silakan cek email Anda untuk menyelesaikan registrasi", nil)
+		bodies = append(bodies, rec.Body.String())
+	}
+	if bodies[0] != bodies[1] {
+		t.Fatalf("responses differ:\n%s\n%s", bodies[0], bodies[1])
+	}
+
+	// Pemilik email yang sudah terdaftar hanya mendapat pemberitahuan
+	notice, ok := h.Mailer.Last(existing.Email)
+	if !ok || notice.Subject != "Percobaan registrasi dengan email Anda" {
+		t.Fatalf("unexpected notice: %+v", notice)
+	}
+
+	// Akun baru belum dibuat sebelum link dikonfirmasi
+	var count int64
+	h.DB.Model(&models.User{}).Where("email = ?", "baru@example.com").Count(&count)
+	if count != 0 {
+		t.Fatal("user created before confirmation")
+	}
+
+	token := linkToken(t, h, "baru@example.com")
+	rec := h.Request(t, http.MethodPost, "/api/register/confirm", map[string]string{"token": token}, "")
+	var user models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", &user)
+	if user.Email != "baru@example.com" || user.Role.Name != models.UserRoleName {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	rec, _ = login(t, h, "baru@example.com", "rahasia123", "Laptop")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	// Link hanya bisa dipakai sekali
+	rec = h.Request(t, http.MethodPost, "/api/register/confirm", map[string]string{"token": token}, "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Link konfirmasi registrasi tidak valid")
+}
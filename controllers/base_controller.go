@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"golang-starter-kit/breach"
+	"golang-starter-kit/config"
+	"golang-starter-kit/mailer"
+	"golang-starter-kit/middleware"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/services"
+	"golang-starter-kit/utils"
+)
+
+// Service yang dipakai oleh controller, diisi oleh InitController
+var (
+	authService         *services.AuthService
+	userService         *services.UserService
+	roleService         *services.RoleService
+	invitationService   *services.InvitationService
+	jobService          *services.JobService
+	auditService        *services.AuditService
+	sessionService      *services.SessionService
+	accountService      *services.AccountService
+	apiTokenService     *services.APITokenService
+	oauthService        *services.OAuthService
+	ssoService          *services.SSOService
+	ldapService         *services.LDAPService
+	scimService         *services.SCIMService
+	passwordlessService *services.PasswordlessService
+)
+
+// InitController menyiapkan repository dan service yang dipakai oleh controller
+func InitController() {
+	userRepository := repositories.NewUserRepository(models.DB)
+	roleRepository := repositories.NewRoleRepository(models.DB)
+	invitationRepository := repositories.NewInvitationRepository(models.DB)
+
+	registrationPolicy := config.LoadRegistrationPolicy()
+
+	auditConfig, err := config.LoadAuditConfig()
+	if err != nil {
+		log.Fatal("Invalid audit config: ", err)
+	}
+	auditService = services.NewAuditService(
+		repositories.NewAuditRepository(models.DB),
+		repositories.NewAuditCheckpointRepository(models.DB),
+		auditConfig,
+	)
+	passwordHash, err := config.LoadPasswordHashConfig()
+	if err != nil {
+		log.Fatal("Invalid password hash config: ", err)
+	}
+	utils.SetPasswordHasher(utils.NewPasswordHasher(passwordHash))
+
+	passwordPolicy, err := config.LoadPasswordPolicy()
+	if err != nil {
+		log.Fatal("Invalid password policy: ", err)
+	}
+	var breached breach.Checker
+	if passwordPolicy.BreachedPath != "" {
+		if breached, err = breach.Open(passwordPolicy.BreachedPath); err != nil {
+			log.Fatal("Failed to load breached passwords: ", err)
+		}
+	}
+	sessionService = services.NewSessionService(
+		repositories.NewSessionRepository(models.DB),
+		repositories.NewLoginAttemptRepository(models.DB),
+		auditService,
+	)
+	userService = services.NewUserService(
+		userRepository,
+		roleRepository,
+		registrationPolicy.DefaultRole,
+		services.NewPasswordPolicy(passwordPolicy, repositories.NewPasswordHistoryRepository(models.DB), breached),
+		sessionService,
+		auditService,
+	)
+	roleService = services.NewRoleService(roleRepository, auditService)
+	ldapConfig, err := config.LoadLDAPConfig()
+	if err != nil {
+		log.Fatal("Invalid LDAP config: ", err)
+	}
+	ldapService = services.NewLDAPService(
+		repositories.NewUserIdentityRepository(models.DB),
+		userRepository,
+		roleRepository,
+		userService,
+		sessionService,
+		ldapConfig,
+		auditService,
+	)
+	authService = services.NewAuthService(
+		userRepository,
+		repositories.NewPendingRegistrationRepository(models.DB),
+		userService,
+		sessionService,
+		ldapService,
+		mailer.Default(),
+		registrationPolicy,
+	)
+	passwordlessConfig, err := config.LoadPasswordlessConfig()
+	if err != nil {
+		log.Fatal("Invalid passwordless login config: ", err)
+	}
+	passwordlessService = services.NewPasswordlessService(
+		userRepository,
+		repositories.NewLoginCodeRepository(models.DB),
+		authService,
+		sessionService,
+		mailer.Default(),
+		passwordlessConfig,
+	)
+	invitationService = services.NewInvitationService(
+		invitationRepository,
+		userRepository,
+		roleRepository,
+		userService,
+		mailer.Default(),
+		config.LoadInvitationConfig(),
+		auditService,
+	)
+	accountService = services.NewAccountService(
+		userRepository,
+		repositories.NewEmailChangeRepository(models.DB),
+		userService,
+		sessionService,
+		mailer.Default(),
+		config.LoadAccountConfig(),
+		auditService,
+	)
+	apiTokenService = services.NewAPITokenService(
+		repositories.NewAPITokenRepository(models.DB),
+		config.LoadAPITokenConfig(),
+		auditService,
+	)
+	oauthConfig, err := config.LoadOAuthConfig()
+	if err != nil {
+		log.Fatal("Invalid OAuth config: ", err)
+	}
+	oauthService = services.NewOAuthService(
+		repositories.NewOAuthClientRepository(models.DB),
+		repositories.NewOAuthGrantRepository(models.DB),
+		repositories.NewOAuthCodeRepository(models.DB),
+		repositories.NewOAuthConsentRepository(models.DB),
+		userRepository,
+		oauthConfig,
+		auditService,
+	)
+	ssoConfig, err := config.LoadSSOConfig()
+	if err != nil {
+		log.Fatal("Invalid SSO config: ", err)
+	}
+	ssoService = services.NewSSOService(
+		repositories.NewUserIdentityRepository(models.DB),
+		repositories.NewSSOStateRepository(models.DB),
+		userRepository,
+		roleRepository,
+		userService,
+		sessionService,
+		ssoConfig,
+		auditService,
+	)
+	scimService = services.NewSCIMService(
+		userRepository,
+		roleRepository,
+		repositories.NewUserIdentityRepository(models.DB),
+		userService,
+		roleService,
+		sessionService,
+		config.LoadSCIMConfig(),
+		auditService,
+	)
+	jobService = services.NewJobService(repositories.NewJobRepository(models.DB), auditService)
+}
+
+// SharedUserService mengembalikan UserService yang disiapkan InitController, lengkap dengan password policy,
+// agar pekerjaan di luar HTTP seperti sinkronisasi LDAP memakai aturan yang sama
+func SharedUserService() *services.UserService {
+	return userService
+}
+
+// SharedSessionService mengembalikan SessionService yang disiapkan InitController
+func SharedSessionService() *services.SessionService {
+	return sessionService
+}
+
+// SharedAuditService mengembalikan AuditService yang disiapkan InitController
+func SharedAuditService() *services.AuditService {
+	return auditService
+}
+
+// respondError mengubah error dari service menjadi response JSON dengan HTTP status yang sesuai.
+// Error yang tidak dikenal dianggap error internal dan memakai pesan fallback.
+func respondError(c *gin.Context, err error, fallback string) {
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		c.JSON(http.StatusInternalServerError, utils.APIResponseError(fallback, nil))
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch serviceErr.Kind {
+	case services.KindValidation:
+		status = http.StatusBadRequest
+	case services.KindNotFound:
+		status = http.StatusNotFound
+	case services.KindConflict:
+		status = http.StatusBadRequest
+	case services.KindUnauthorized:
+		status = http.StatusUnauthorized
+	case services.KindForbidden:
+		status = http.StatusForbidden
+	}
+	c.JSON(status, utils.APIResponseError(serviceErr.Message, serviceErr.Details))
+}
+
+// queryFlag membaca query parameter boolean, contoh: ?with_deleted atau ?with_deleted=true
+func queryFlag(c *gin.Context, key string) bool {
+	value, exists := c.GetQuery(key)
+	if !exists {
+		return false
+	}
+	if value == "" {
+		return true
+	}
+	flag, err := strconv.ParseBool(value)
+	return err == nil && flag
+}
+
+// deletedScope membaca ?with_deleted / ?only_deleted. Hanya admin yang boleh melihat
+// data yang sudah dihapus, selain admin akan mendapat response 403 dan false.
+func deletedScope(c *gin.Context) (repositories.DeletedScope, bool) {
+	scope := repositories.ExcludeDeleted
+	switch {
+	case queryFlag(c, "only_deleted"):
+		scope = repositories.OnlyDeleted
+	case queryFlag(c, "with_deleted"):
+		scope = repositories.WithDeleted
+	}
+
+	if scope != repositories.ExcludeDeleted && !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, utils.APIResponseError("Hanya admin yang dapat melihat data yang sudah dihapus", nil))
+		return scope, false
+	}
+	return scope, true
+}
+
+// assignableRoleID mengembalikan idRole hanya jika user yang sedang login boleh memberi role
+// (admin). Selain itu role dari client diabaikan dan server memakai role default.
+func assignableRoleID(c *gin.Context, idRole uint) uint {
+	if idRole == 0 || !middleware.IsAdmin(c) {
+		return 0
+	}
+	return idRole
+}
+
+// paramID membaca parameter :id dari URL, false jika bukan angka
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
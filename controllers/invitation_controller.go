@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"      // Framework web Gin
+	"golang-starter-kit/middleware" // Identitas user yang sedang login
+	"golang-starter-kit/services"   // Aturan bisnis (undangan)
+	"golang-starter-kit/utils"      // Helper (response)
+)
+
+// GetInvitations menampilkan semua undangan, bisa difilter dengan ?status=pending|accepted|revoked|expired
+func GetInvitations(c *gin.Context) {
+	invitations, err := invitationService.List(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data undangan")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar undangan", invitations))
+}
+
+type CreateInvitationInput struct {
+	Email  string `json:"email" binding:"required,email"`
+	IDRole uint   `json:"id_role" binding:"required"`
+	Locale string `json:"locale" binding:"omitempty,max=10"` // Bahasa email undangan, contoh: id, en
+}
+
+// CreateInvitation mengundang email dengan role yang sudah ditentukan
+func CreateInvitation(c *gin.Context) {
+	var input CreateInvitationInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	invitation, err := invitationService.Invite(c.Request.Context(), services.InviteParams{
+		Email:     input.Email,
+		IDRole:    input.IDRole,
+		Locale:    input.Locale,
+		InvitedBy: middleware.CurrentUserID(c),
+	})
+	if err != nil {
+		respondError(c, err, "Gagal membuat undangan")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Undangan berhasil dikirim", invitation))
+}
+
+// ResendInvitation mengirim ulang undangan dengan link baru
+func ResendInvitation(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrInvitationNotFound, "")
+		return
+	}
+
+	invitation, err := invitationService.Resend(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Gagal mengirim ulang undangan")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Undangan berhasil dikirim ulang", invitation))
+}
+
+// RevokeInvitation mencabut undangan
+func RevokeInvitation(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrInvitationNotFound, "")
+		return
+	}
+
+	invitation, err := invitationService.Revoke(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Gagal mencabut undangan")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Undangan berhasil dicabut", invitation))
+}
+
+type AcceptInvitationInput struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required,min=3"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AcceptInvitation membuat akun dari link undangan
+func AcceptInvitation(c *gin.Context) {
+	var input AcceptInvitationInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	user, err := invitationService.Accept(c.Request.Context(), services.AcceptInvitationParams{
+		Token:    input.Token,
+		Name:     input.Name,
+		Password: input.Password,
+	})
+	if err != nil {
+		respondError(c, err, "Gagal menerima undangan")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Undangan berhasil diterima", user))
+}
@@ -0,0 +1,179 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+var tokenLinkPattern = regexp.MustCompile(`token=([^\s"&<]+)`)
+
+// linkToken mengambil token dari link pada email terakhir yang dikirim ke alamat to
+func linkToken(t *testing.T, h *testutil.Harness, to string) string {
+	t.Helper()
+	msg, ok := h.Mailer.Last(to)
+	if !ok {
+		t.Fatalf("no email sent to %s", to)
+	}
+	match := tokenLinkPattern.FindStringSubmatch(msg.Text)
+	if match == nil {
+		t.Fatalf("no token link in email: %s", msg.Text)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func acceptBody(token string) map[string]string {
+	return map[string]string{"token": token, "name": "Tamu Baru", "password": "rahasia123"}
+}
+
+func TestInvitationFlow(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	role := h.CreateRole(t, testutil.RoleAttrs{Name: "editor"})
+
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/invitation/", map[string]interface{}{
+		"email":   "tamu@example.com",
+		"id_role": role.ID,
+	})
+	var invitation models.Invitation
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Undangan berhasil dikirim", &invitation)
+	if invitation.Status != models.InvitationPending || invitation.InvitedBy != admin.ID {
+		t.Fatalf("unexpected invitation: %+v", invitation)
+	}
+
+	// Undangan kedua untuk email yang sama ditolak selama yang pertama masih aktif
+	rec = h.AuthRequest(t, admin, http.MethodPost, "/api/invitation/", map[string]interface{}{
+		"email":   "tamu@example.com",
+		"id_role": role.ID,
+	})
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Undangan untuk email ini masih aktif")
+
+	token := linkToken(t, h, "tamu@example.com")
+	rec = h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody(token), "")
+	var user models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Undangan berhasil diterima", &user)
+	if user.Email != "tamu@example.com" || user.IDRole != role.ID {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	// Link hanya bisa dipakai sekali
+	rec = h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody(token), "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Undangan sudah diterima atau dicabut")
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/invitation/?status=accepted", nil)
+	var invitations []models.Invitation
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar undangan", &invitations)
+	if len(invitations) != 1 || invitations[0].Status != models.InvitationAccepted {
+		t.Fatalf("unexpected invitations: %+v", invitations)
+	}
+}
+
+func TestInvitationAcceptWorksWhenRegistrationIsInviteOnly(t *testing.T) {
+	t.Setenv("REGISTRATION_MODE", "invite_only")
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	h.AuthRequest(t, admin, http.MethodPost, "/api/invitation/", map[string]interface{}{
+		"email":   "tamu@example.com",
+		"id_role": admin.IDRole,
+	})
+	rec := h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody(linkToken(t, h, "tamu@example.com")), "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Undangan berhasil diterima", nil)
+}
+
+func TestInvitationResendInvalidatesOldLink(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/invitation/", map[string]interface{}{
+		"email":   "tamu@example.com",
+		"id_role": admin.IDRole,
+	})
+	var invitation models.Invitation
+	testutil.AssertSuccess(t, rec, http.StatusOK, "", &invitation)
+	oldToken := linkToken(t, h, "tamu@example.com")
+
+	rec = h.AuthRequest(t, admin, http.MethodPost, fmt.Sprintf("/api/invitation/%d/resend", invitation.ID), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Undangan berhasil dikirim ulang", nil)
+	if n := len(h.Mailer.Messages()); n != 2 {
+		t.Fatalf("sent %d emails, want 2", n)
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody(oldToken), "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Link undangan tidak valid")
+
+	rec = h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody(linkToken(t, h, "tamu@example.com")), "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Undangan berhasil diterima", nil)
+}
+
+func TestInvitationExpiryAndRevoke(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/invitation/", map[string]interface{}{
+		"email":   "tamu@example.com",
+		"id_role": admin.IDRole,
+	})
+	var invitation models.Invitation
+	testutil.AssertSuccess(t, rec, http.StatusOK, "", &invitation)
+	token := linkToken(t, h, "tamu@example.com")
+
+	// Link kadaluwarsa setelah 72 jam (default INVITATION_TTL_HOURS)
+	h.Clock.Advance(73 * time.Hour)
+	rec = h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody(token), "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Link undangan sudah kadaluwarsa")
+
+	// Undangan kadaluwarsa bisa dikirim ulang, lalu dicabut
+	path := fmt.Sprintf("/api/invitation/%d", invitation.ID)
+	h.AuthRequest(t, admin, http.MethodPost, path+"/resend", nil)
+	token = linkToken(t, h, "tamu@example.com")
+
+	rec = h.AuthRequest(t, admin, http.MethodDelete, path, nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Undangan berhasil dicabut", &invitation)
+	if invitation.Status != models.InvitationRevoked {
+		t.Fatalf("status = %q, want revoked", invitation.Status)
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody(token), "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Undangan sudah diterima atau dicabut")
+}
+
+func TestInvitationRequiresAdmin(t *testing.T) {
+	h := testutil.New(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, member, http.MethodPost, "/api/invitation/", map[string]interface{}{
+		"email":   "tamu@example.com",
+		"id_role": member.IDRole,
+	})
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+
+	rec = h.Request(t, http.MethodPost, "/api/invitation/accept", acceptBody("palsu.123.abc"), "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Link undangan tidak valid")
+}
+
+func TestInvitationEmailIsLocalized(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	role := h.CreateRole(t, testutil.RoleAttrs{Name: "editor"})
+
+	h.AuthRequest(t, admin, http.MethodPost, "/api/invitation/", map[string]interface{}{
+		"email":   "guest@example.com",
+		"id_role": role.ID,
+		"locale":  "en",
+	})
+	msg, _ := h.Mailer.Last("guest@example.com")
+	if msg.Subject != "You're invited to join as editor" || msg.HTML == "" {
+		t.Fatalf("unexpected email: %+v", msg)
+	}
+}
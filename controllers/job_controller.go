@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"        // Framework web Gin
+	"golang-starter-kit/repositories" // Filter daftar job
+	"golang-starter-kit/services"     // Aturan bisnis (job)
+	"golang-starter-kit/utils"        // Helper (response)
+)
+
+// GetJobs menampilkan job di antrian, bisa difilter dengan ?status=pending|running|succeeded|dead, ?type= dan ?limit=
+func GetJobs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	jobs, err := jobService.List(c.Request.Context(), repositories.JobFilter{
+		Status: c.Query("status"),
+		Type:   c.Query("type"),
+		Limit:  limit,
+	})
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data job")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar job", jobs))
+}
+
+// GetJobByID menampilkan detail satu job termasuk error terakhirnya
+func GetJobByID(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrJobNotFound, "")
+		return
+	}
+
+	job, err := jobService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data job")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Detail job", job))
+}
+
+// RetryJob menjalankan ulang job yang gagal
+func RetryJob(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrJobNotFound, "")
+		return
+	}
+
+	job, err := jobService.Retry(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Gagal menjadwalkan ulang job")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Job dijadwalkan ulang", job))
+}
@@ -0,0 +1,67 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+func TestJobAdminEndpoints(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	dead := models.Job{Type: "mail.send", Payload: "{}", Status: models.JobDead, RunAt: h.Clock.Now(), Attempts: 5, MaxAttempts: 5, LastError: "smtp down"}
+	done := models.Job{Type: "mail.send", Payload: "{}", Status: models.JobSucceeded, RunAt: h.Clock.Now(), Attempts: 1, MaxAttempts: 5}
+	h.DB.Create(&dead)
+	h.DB.Create(&done)
+
+	rec := h.AuthRequest(t, admin, http.MethodGet, "/api/admin/jobs?status=dead", nil)
+	var list []models.Job
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar job", &list)
+	if len(list) != 1 || list[0].ID != dead.ID {
+		t.Fatalf("unexpected jobs: %+v", list)
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/admin/jobs?status=unknown", nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Status job tidak valid")
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, fmt.Sprintf("/api/admin/jobs/%d", dead.ID), nil)
+	var job models.Job
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Detail job", &job)
+	if job.LastError != "smtp down" {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodPost, fmt.Sprintf("/api/admin/jobs/%d/retry", dead.ID), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Job dijadwalkan ulang", &job)
+	if job.Status != models.JobPending || job.Attempts != 0 {
+		t.Fatalf("job should be pending again: %+v", job)
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodPost, fmt.Sprintf("/api/admin/jobs/%d/retry", done.ID), nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Hanya job yang gagal (dead) atau menunggu retry yang dapat diulang")
+
+	// Payload tidak pernah ditampilkan, job rahasia yang payload-nya sudah dihapus tidak bisa diulang
+	secret := models.Job{Type: "mail.send", Payload: `{"text":"kode 123456"}`, Sensitive: true, Status: models.JobPending, RunAt: h.Clock.Now(), MaxAttempts: 5}
+	h.DB.Create(&secret)
+	rec = h.AuthRequest(t, admin, http.MethodGet, fmt.Sprintf("/api/admin/jobs/%d", secret.ID), nil)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	if strings.Contains(rec.Body.String(), "123456") {
+		t.Fatalf("job payload leaked: %s", rec.Body.String())
+	}
+	h.DB.Model(&secret).Updates(map[string]interface{}{"status": models.JobDead, "payload": ""})
+	rec = h.AuthRequest(t, admin, http.MethodPost, fmt.Sprintf("/api/admin/jobs/%d/retry", secret.ID), nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Isi job rahasia sudah dihapus sehingga tidak dapat diulang")
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/admin/jobs/999", nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "Job tidak ditemukan")
+
+	// Selain admin tidak boleh melihat antrian job
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	rec = h.AuthRequest(t, user, http.MethodGet, "/api/admin/jobs", nil)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+}
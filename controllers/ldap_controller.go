@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin" // Framework web Gin
+	"golang-starter-kit/utils" // Helper (response)
+)
+
+// SyncLDAP menjalankan sinkronisasi user dari directory LDAP saat itu juga (di luar jadwal)
+func SyncLDAP(c *gin.Context) {
+	result, err := ldapService.Sync(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Gagal sinkronisasi LDAP")
+		return
+	}
+
+	// Response success dengan jumlah user yang berubah
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Sinkronisasi LDAP selesai", result))
+}
@@ -0,0 +1,217 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+// ldapAdminsGroup dipetakan ke role admin di semua test LDAP
+const ldapAdminsGroup = "cn=it-admins,ou=groups,dc=example,dc=org"
+
+// newLDAPHarness menjalankan server LDAP lokal dan mengarahkan aplikasi ke sana
+func newLDAPHarness(t *testing.T) (*testutil.Harness, *testutil.MockLDAP) {
+	t.Helper()
+	directory := testutil.NewMockLDAP(t)
+	t.Setenv("LDAP_URL", directory.URL)
+	t.Setenv("LDAP_BIND_DN", directory.BindDN)
+	t.Setenv("LDAP_BIND_PASSWORD", directory.BindPassword)
+	t.Setenv("LDAP_BASE_DN", "ou=people,"+directory.BaseDN)
+	t.Setenv("LDAP_GROUP_ROLE_MAP", ldapAdminsGroup+":"+models.AdminRoleName)
+	return testutil.New(t), directory
+}
+
+// ldapLogin login dengan email dan password lewat endpoint login biasa
+func ldapLogin(t *testing.T, h *testutil.Harness, email, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	return h.Request(t, http.MethodPost, "/api/login", map[string]string{"email": email, "password": password}, "")
+}
+
+func TestLDAPLogin(t *testing.T) {
+	h, directory := newLDAPHarness(t)
+	directory.Put(testutil.LDAPUser("sari", "sari@example.org", "ldap-secret", ldapAdminsGroup))
+
+	t.Run("user baru dibuat dari directory dengan role hasil mapping", func(t *testing.T) {
+		var data ssoLoginData
+		testutil.AssertSuccess(t, ldapLogin(t, h, "sari@example.org", "ldap-secret"), http.StatusOK, "Login berhasil", &data)
+		if data.User.Email != "sari@example.org" || data.User.Role.Name != models.AdminRoleName {
+			t.Fatalf("user = %+v", data.User)
+		}
+
+		var identities []models.UserIdentity
+		testutil.AssertSuccess(t, h.Request(t, http.MethodGet, "/api/me/identities", nil, data.Token), http.StatusOK, "Daftar identitas", &identities)
+		if len(identities) != 1 || identities[0].Provider != models.IdentityProviderLDAP || identities[0].Subject != "uuid-sari" {
+			t.Fatalf("identities = %+v", identities)
+		}
+
+		// Identitas LDAP tidak bisa dilepas oleh user
+		path := "/api/me/identities/" + strconv.Itoa(int(identities[0].ID))
+		testutil.AssertError(t, h.Request(t, http.MethodDelete, path, nil, data.Token), http.StatusBadRequest, "Identitas LDAP dikelola oleh directory dan tidak dapat dilepas")
+	})
+
+	t.Run("password salah dan email tidak dikenal", func(t *testing.T) {
+		testutil.AssertError(t, ldapLogin(t, h, "sari@example.org", "wrong"), http.StatusUnauthorized, "Email atau password salah")
+		testutil.AssertError(t, ldapLogin(t, h, "nobody@example.org", "ldap-secret"), http.StatusUnauthorized, "Email atau password salah")
+		// Filter tidak bisa disusupi lewat email
+		testutil.AssertError(t, ldapLogin(t, h, "*", "ldap-secret"), http.StatusUnauthorized, "Email atau password salah")
+	})
+
+	t.Run("user lokal tetap memakai password lokal", func(t *testing.T) {
+		user := h.CreateUser(t, testutil.UserAttrs{})
+		rec := ldapLogin(t, h, user.Email, testutil.DefaultPassword)
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", nil)
+	})
+
+	t.Run("server LDAP mati", func(t *testing.T) {
+		t.Setenv("LDAP_URL", "ldap://127.0.0.1:1")
+		h := testutil.New(t)
+		testutil.AssertError(t, ldapLogin(t, h, "sari@example.org", "ldap-secret"), http.StatusInternalServerError, "Server LDAP tidak dapat dihubungi")
+	})
+}
+
+func TestLDAPSync(t *testing.T) {
+	t.Setenv("LDAP_LINK_BY_EMAIL", "true")
+	h, directory := newLDAPHarness(t)
+	admin := h.CreateAdmin(t)
+	// User lokal tanpa password (misalnya dibuat lewat SSO) boleh ditautkan
+	local := h.CreateUser(t, testutil.UserAttrs{Name: "Budi", Email: "budi@example.org"})
+	h.DB.Model(&local).Update("password", "")
+
+	sari := testutil.LDAPUser("sari", "sari@example.org", "ldap-secret", ldapAdminsGroup)
+	budi := testutil.LDAPUser("budi", "budi@example.org", "budi-ldap")
+	directory.Put(sari)
+	directory.Put(budi)
+
+	sync := func(t *testing.T) (result struct{ Created, Updated, Deactivated, Failed int }) {
+		t.Helper()
+		rec := h.AuthRequest(t, admin, http.MethodPost, "/api/admin/ldap/sync", nil)
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Sinkronisasi LDAP selesai", &result)
+		return result
+	}
+
+	// sari dibuat, budi ditautkan ke user lokal dengan email yang sama
+	if result := sync(t); result.Created != 1 || result.Updated != 0 || result.Deactivated != 0 || result.Failed != 0 {
+		t.Fatalf("first sync = %+v", result)
+	}
+	var created models.User
+	h.DB.Preload("Role").Where("email = ?", "sari@example.org").First(&created)
+	if created.Name != "Sari" || created.Role.Name != models.AdminRoleName || created.Password != "" {
+		t.Fatalf("created user = %+v", created)
+	}
+
+	// budi sekarang login lewat LDAP
+	testutil.AssertError(t, ldapLogin(t, h, "budi@example.org", testutil.DefaultPassword), http.StatusUnauthorized, "Email atau password salah")
+	var data ssoLoginData
+	testutil.AssertSuccess(t, ldapLogin(t, h, "budi@example.org", "budi-ldap"), http.StatusOK, "Login berhasil", &data)
+	if data.User.ID != local.ID {
+		t.Fatalf("logged in as %d, want %d", data.User.ID, local.ID)
+	}
+
+	// Perubahan nama di directory ikut diperbarui
+	sari.Attributes["cn"] = []string{"Sari Dewi"}
+	directory.Put(sari)
+	if result := sync(t); result.Updated != 1 || result.Created != 0 {
+		t.Fatalf("second sync = %+v", result)
+	}
+	h.DB.First(&created, created.ID)
+	if created.Name != "Sari Dewi" {
+		t.Fatalf("name = %q", created.Name)
+	}
+
+	// User yang hilang dari directory dinonaktifkan dan session-nya dicabut
+	directory.Remove(budi.DN)
+	if result := sync(t); result.Deactivated != 1 {
+		t.Fatalf("third sync = %+v", result)
+	}
+	testutil.AssertStatus(t, h.Request(t, http.MethodGet, "/api/me/sessions", nil, data.Token), http.StatusUnauthorized)
+	var deactivated models.User
+	h.DB.First(&deactivated, local.ID)
+	if deactivated.DeactivatedAt == nil {
+		t.Fatal("budi should be deactivated")
+	}
+
+	// Muncul lagi di directory berarti aktif lagi
+	directory.Put(budi)
+	if result := sync(t); result.Updated != 1 || result.Deactivated != 0 {
+		t.Fatalf("fourth sync = %+v", result)
+	}
+	testutil.AssertSuccess(t, ldapLogin(t, h, "budi@example.org", "budi-ldap"), http.StatusOK, "Login berhasil", nil)
+
+	// Hasil kosong (misalnya base DN salah) tidak menonaktifkan semua user
+	directory.Remove(sari.DN)
+	directory.Remove(budi.DN)
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/admin/ldap/sync", nil)
+	testutil.AssertError(t, rec, http.StatusInternalServerError, "Sinkronisasi LDAP tidak menemukan user, tidak ada user yang dinonaktifkan")
+}
+
+func TestLDAPDoesNotTakeOverLocalAccounts(t *testing.T) {
+	// syncFailed menjalankan sinkronisasi dan mengembalikan jumlah entry yang gagal
+	syncFailed := func(t *testing.T, h *testutil.Harness, admin models.User) int {
+		t.Helper()
+		var result struct{ Created, Failed int }
+		rec := h.AuthRequest(t, admin, http.MethodPost, "/api/admin/ldap/sync", nil)
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Sinkronisasi LDAP selesai", &result)
+		if result.Created != 0 {
+			t.Fatalf("sync created %d users", result.Created)
+		}
+		return result.Failed
+	}
+	linked := func(h *testutil.Harness, user models.User) bool {
+		var count int64
+		h.DB.Model(&models.UserIdentity{}).Where("user_id = ? AND provider = ?", user.ID, models.IdentityProviderLDAP).Count(&count)
+		return count > 0
+	}
+
+	t.Run("tanpa LDAP_LINK_BY_EMAIL", func(t *testing.T) {
+		h, directory := newLDAPHarness(t)
+		admin := h.CreateAdmin(t)
+		local := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.org"})
+		h.DB.Model(&local).Update("password", "")
+		directory.Put(testutil.LDAPUser("budi", "budi@example.org", "budi-ldap"))
+
+		if failed := syncFailed(t, h, admin); failed != 1 || linked(h, local) {
+			t.Fatalf("failed = %d, linked = %v", failed, linked(h, local))
+		}
+		testutil.AssertError(t, ldapLogin(t, h, "budi@example.org", "budi-ldap"), http.StatusUnauthorized, "Email atau password salah")
+	})
+
+	t.Run("user dengan password lokal atau role admin", func(t *testing.T) {
+		t.Setenv("LDAP_LINK_BY_EMAIL", "true")
+		h, directory := newLDAPHarness(t)
+		admin := h.CreateAdmin(t)
+		withPassword := h.CreateUser(t, testutil.UserAttrs{Email: "rudi@example.org"})
+		adminRole := h.SystemRole(t, models.AdminRoleName)
+		otherAdmin := h.CreateUser(t, testutil.UserAttrs{Email: "root@example.org", IDRole: adminRole.ID})
+		h.DB.Model(&otherAdmin).Update("password", "")
+		directory.Put(testutil.LDAPUser("rudi", "rudi@example.org", "rudi-ldap"))
+		directory.Put(testutil.LDAPUser("root", "root@example.org", "root-ldap", ldapAdminsGroup))
+
+		if failed := syncFailed(t, h, admin); failed != 2 || linked(h, withPassword) || linked(h, otherAdmin) {
+			t.Fatalf("failed = %d, local accounts should not be linked", failed)
+		}
+		// Password lokal tetap berlaku, password directory tidak
+		testutil.AssertSuccess(t, ldapLogin(t, h, "rudi@example.org", testutil.DefaultPassword), http.StatusOK, "Login berhasil", nil)
+		testutil.AssertError(t, ldapLogin(t, h, "rudi@example.org", "rudi-ldap"), http.StatusUnauthorized, "Email atau password salah")
+	})
+}
+
+func TestLDAPDeactivatedUserCannotLogin(t *testing.T) {
+	h, directory := newLDAPHarness(t)
+	directory.Put(testutil.LDAPUser("rina", "rina@example.org", "ldap-secret"))
+	testutil.AssertSuccess(t, ldapLogin(t, h, "rina@example.org", "ldap-secret"), http.StatusOK, "Login berhasil", nil)
+
+	h.DB.Model(&models.User{}).Where("email = ?", "rina@example.org").Update("deactivated_at", h.Clock.Now())
+	testutil.AssertError(t, ldapLogin(t, h, "rina@example.org", "ldap-secret"), http.StatusForbidden, "Akun sudah dinonaktifkan")
+	// Status akun tidak terlihat tanpa password yang benar
+	testutil.AssertError(t, ldapLogin(t, h, "rina@example.org", "wrong"), http.StatusUnauthorized, "Email atau password salah")
+}
+
+func TestLDAPSyncDisabled(t *testing.T) {
+	h := testutil.New(t)
+	rec := h.AuthRequest(t, h.CreateAdmin(t), http.MethodPost, "/api/admin/ldap/sync", nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "LDAP tidak dikonfigurasi")
+}
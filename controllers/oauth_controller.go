@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"      // Framework web Gin
+	"golang-starter-kit/middleware" // Identitas user yang sedang login
+	"golang-starter-kit/models"     // Scope OpenID Connect
+	"golang-starter-kit/services"   // Aturan bisnis (OAuth)
+	"golang-starter-kit/utils"      // Helper (response)
+)
+
+type CreateOAuthClientInput struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types"` // Kosong = authorization_code dan refresh_token
+	Scopes       []string `json:"scopes" binding:"required"`
+	Confidential bool     `json:"confidential"`
+	FirstParty   bool     `json:"first_party"`
+}
+
+// GetOAuthClients menampilkan semua client OAuth (admin)
+func GetOAuthClients(c *gin.Context) {
+	clients, err := oauthService.ListClients(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data client")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar client OAuth", clients))
+}
+
+// CreateOAuthClient mendaftarkan client OAuth baru (admin). Secret hanya ditampilkan sekali di response ini.
+func CreateOAuthClient(c *gin.Context) {
+	var input CreateOAuthClientInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	client, err := oauthService.CreateClient(c.Request.Context(), services.CreateOAuthClientParams{
+		Name:         input.Name,
+		RedirectURIs: input.RedirectURIs,
+		GrantTypes:   input.GrantTypes,
+		Scopes:       input.Scopes,
+		Confidential: input.Confidential,
+		FirstParty:   input.FirstParty,
+	})
+	if err != nil {
+		respondError(c, err, "Gagal mendaftarkan client")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Client OAuth berhasil didaftarkan", client))
+}
+
+// DeleteOAuthClient menghapus client OAuth beserta semua token-nya (admin)
+func DeleteOAuthClient(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrOAuthClientNotFound, "")
+		return
+	}
+
+	if err := oauthService.DeleteClient(c.Request.Context(), id); err != nil {
+		respondError(c, err, "Gagal menghapus client")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Client OAuth berhasil dihapus", nil))
+}
+
+// AuthorizeInput adalah parameter request authorize yang diteruskan oleh halaman persetujuan
+type AuthorizeInput struct {
+	ResponseType        string `json:"response_type" form:"response_type"`
+	ClientID            string `json:"client_id" form:"client_id" binding:"required"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" form:"scope"`
+	State               string `json:"state" form:"state"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"`
+	Nonce               string `json:"nonce" form:"nonce"` // OpenID Connect, dimasukkan ke ID token
+}
+
+// request mengubah input menjadi AuthorizeRequest untuk service
+func (input AuthorizeInput) request() services.AuthorizeRequest {
+	return services.AuthorizeRequest{
+		ResponseType:        input.ResponseType,
+		ClientID:            input.ClientID,
+		RedirectURI:         input.RedirectURI,
+		Scope:               input.Scope,
+		State:               input.State,
+		CodeChallenge:       input.CodeChallenge,
+		CodeChallengeMethod: input.CodeChallengeMethod,
+		Nonce:               input.Nonce,
+	}
+}
+
+// ApproveAuthorizeInput adalah pilihan user di halaman persetujuan
+type ApproveAuthorizeInput struct {
+	AuthorizeInput
+	Approve bool `json:"approve"`
+}
+
+// OAuthAuthorize adalah endpoint authorize yang dibuka browser. Request diteruskan ke halaman
+// persetujuan di frontend, yang memakai GetOAuthAuthorization dan ApproveOAuthAuthorization.
+func OAuthAuthorize(c *gin.Context) {
+	target := oauthService.ConsentURL()
+	if query := c.Request.URL.RawQuery; query != "" {
+		target += "?" + query
+	}
+	c.Redirect(http.StatusFound, target)
+}
+
+// GetOAuthAuthorization memeriksa request authorize dan mengembalikan data untuk halaman persetujuan
+func GetOAuthAuthorization(c *gin.Context) {
+	var input AuthorizeInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.APIResponseError("client_id wajib diisi", nil))
+		return
+	}
+
+	prompt, err := oauthService.Prepare(c.Request.Context(), middleware.CurrentUserID(c), input.request())
+	if err != nil {
+		respondOAuthAuthorizeError(c, err)
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Permintaan akses", prompt))
+}
+
+// ApproveOAuthAuthorization menyimpan pilihan user dan mengembalikan URL redirect ke client
+func ApproveOAuthAuthorization(c *gin.Context) {
+	var input ApproveAuthorizeInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	redirectTo, err := oauthService.Approve(c.Request.Context(), middleware.CurrentUserID(c), input.request(), input.Approve)
+	if err != nil {
+		respondOAuthAuthorizeError(c, err)
+		return
+	}
+
+	// Response success, frontend mengarahkan browser ke redirect_to
+	message := "Akses diberikan"
+	if !input.Approve {
+		message = "Akses ditolak"
+	}
+	c.JSON(http.StatusOK, utils.APIResponseSuccess(message, gin.H{"redirect_to": redirectTo}))
+}
+
+// OAuthToken adalah endpoint token (RFC 6749). Request berupa form, response memakai format OAuth.
+func OAuthToken(c *gin.Context) {
+	token, err := oauthService.Token(c.Request.Context(), services.TokenRequest{
+		Client:       clientCredentials(c),
+		GrantType:    c.PostForm("grant_type"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		CodeVerifier: c.PostForm("code_verifier"),
+		RefreshToken: c.PostForm("refresh_token"),
+		Scope:        c.PostForm("scope"),
+	})
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+
+	noStore(c)
+	c.JSON(http.StatusOK, token)
+}
+
+// OAuthRevoke adalah endpoint pencabutan token (RFC 7009). Token yang tidak dikenal tetap dijawab 200.
+func OAuthRevoke(c *gin.Context) {
+	if err := oauthService.Revoke(c.Request.Context(), clientCredentials(c), c.PostForm("token")); err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// OAuthIntrospect adalah endpoint introspeksi token (RFC 7662) untuk resource server
+func OAuthIntrospect(c *gin.Context) {
+	result, err := oauthService.Introspect(c.Request.Context(), clientCredentials(c), c.PostForm("token"))
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+
+	noStore(c)
+	c.JSON(http.StatusOK, result)
+}
+
+// OpenIDConfiguration adalah dokumen discovery OpenID Connect
+func OpenIDConfiguration(c *gin.Context) {
+	c.JSON(http.StatusOK, oauthService.Discovery())
+}
+
+// OAuthJWKS menampilkan public key untuk memverifikasi ID token
+func OAuthJWKS(c *gin.Context) {
+	keys, err := oauthService.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// OAuthUserInfo adalah endpoint userinfo OpenID Connect. Hanya access token OAuth dengan scope
+// openid yang diterima, claim yang dikembalikan mengikuti scope token.
+func OAuthUserInfo(c *gin.Context) {
+	if !middleware.IsScopedToken(c) || !middleware.HasScope(c, models.ScopeOpenID) {
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient_scope"})
+		return
+	}
+
+	claims, err := oauthService.UserInfo(c.Request.Context(), middleware.CurrentUserID(c), middleware.CurrentScopes(c))
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		return
+	}
+
+	noStore(c)
+	c.JSON(http.StatusOK, claims)
+}
+
+// clientCredentials membaca identitas client dari header Basic atau dari body form
+func clientCredentials(c *gin.Context) services.ClientCredentials {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		return services.ClientCredentials{ClientID: id, ClientSecret: secret}
+	}
+	return services.ClientCredentials{ClientID: c.PostForm("client_id"), ClientSecret: c.PostForm("client_secret")}
+}
+
+// respondOAuthError mengirim error endpoint token dalam format OAuth (RFC 6749 bagian 5.2)
+func respondOAuthError(c *gin.Context, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		noStore(c)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	if oauthErr.Status() == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	noStore(c)
+	c.JSON(oauthErr.Status(), gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+}
+
+// respondOAuthAuthorizeError mengirim error request authorize ke halaman persetujuan. Error yang
+// boleh diteruskan ke client membawa redirect_to, error lain ditampilkan oleh halaman persetujuan.
+func respondOAuthAuthorizeError(c *gin.Context, err error) {
+	var oauthErr *services.OAuthError
+	if errors.As(err, &oauthErr) {
+		c.JSON(http.StatusBadRequest, utils.APIResponseError(oauthErr.Description, gin.H{
+			"error":       oauthErr.Code,
+			"redirect_to": oauthErr.RedirectTo(),
+		}))
+		return
+	}
+	respondError(c, err, "Gagal memproses permintaan akses")
+}
+
+// noStore melarang cache untuk response yang berisi token (RFC 6749 bagian 5.1)
+func noStore(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+}
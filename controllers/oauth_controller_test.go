@@ -0,0 +1,375 @@
+package controllers_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+	"golang-starter-kit/utils"
+)
+
+const (
+	oauthRedirectURI = "https://app.example.com/callback"
+	oauthVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+// createOAuthClient mendaftarkan client OAuth lewat endpoint admin
+func createOAuthClient(t *testing.T, h *testutil.Harness, body map[string]interface{}) services.NewOAuthClient {
+	t.Helper()
+	rec := h.AuthRequest(t, h.CreateAdmin(t), http.MethodPost, "/api/admin/oauth/clients", body)
+	var data services.NewOAuthClient
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Client OAuth berhasil didaftarkan", &data)
+	return data
+}
+
+// authorizeQuery membuat parameter authorize dengan PKCE S256 dari oauthVerifier
+func authorizeQuery(clientID, scope string) url.Values {
+	sum := sha256.Sum256([]byte(oauthVerifier))
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {oauthRedirectURI},
+		"scope":                 {scope},
+		"state":                 {"xyz"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}
+}
+
+// approve menyetujui request authorize sebagai user dan mengembalikan authorization code
+func approve(t *testing.T, h *testutil.Harness, user models.User, query url.Values) string {
+	t.Helper()
+	body := map[string]interface{}{"approve": true}
+	for key := range query {
+		body[key] = query.Get(key)
+	}
+	rec := h.AuthRequest(t, user, http.MethodPost, "/api/oauth/authorize", body)
+	var data struct {
+		RedirectTo string `json:"redirect_to"`
+	}
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Akses diberikan", &data)
+
+	redirect, err := url.Parse(data.RedirectTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(data.RedirectTo, oauthRedirectURI+"?") || redirect.Query().Get("state") != "xyz" {
+		t.Fatalf("redirect_to = %q", data.RedirectTo)
+	}
+	return redirect.Query().Get("code")
+}
+
+// oauthPost mengirim form ke endpoint OAuth, dengan autentikasi Basic jika secret diisi
+func oauthPost(h *testutil.Harness, path string, form url.Values, clientID, secret string) *httptest.ResponseRecorder {
+	if secret == "" && clientID != "" {
+		form.Set("client_id", clientID)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if secret != "" {
+		req.SetBasicAuth(clientID, secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeOAuth membaca response endpoint OAuth dengan status tertentu
+func decodeOAuth(t *testing.T, rec *httptest.ResponseRecorder, status int, dest interface{}) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d, body = %s", rec.Code, status, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), dest); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+}
+
+// assertOAuthError memastikan endpoint OAuth menjawab dengan kode error tertentu
+func assertOAuthError(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	var data struct {
+		Error string `json:"error"`
+	}
+	decodeOAuth(t, rec, status, &data)
+	if data.Error != code {
+		t.Fatalf("error = %q, want %q", data.Error, code)
+	}
+}
+
+func TestOAuthAuthorizationCodeWithPKCE(t *testing.T) {
+	h := testutil.New(t)
+	client := createOAuthClient(t, h, map[string]interface{}{
+		"name":          "SPA",
+		"redirect_uris": []string{oauthRedirectURI},
+		"scopes":        []string{models.ScopeUsersRead, models.ScopeUsersWrite},
+	})
+	if client.ClientSecret != "" {
+		t.Fatalf("public client got a secret")
+	}
+	clientID := client.Client.ClientID
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	query := authorizeQuery(clientID, models.ScopeUsersRead)
+
+	// Browser diteruskan ke halaman persetujuan beserta query-nya
+	rec := h.Request(t, http.MethodGet, "/oauth/authorize?"+query.Encode(), nil, "")
+	testutil.AssertStatus(t, rec, http.StatusFound)
+	if location := rec.Header().Get("Location"); !strings.HasSuffix(location, "?"+query.Encode()) {
+		t.Fatalf("location = %q", location)
+	}
+
+	var prompt services.AuthorizationPrompt
+	rec = h.AuthRequest(t, user, http.MethodGet, "/api/oauth/authorize?"+query.Encode(), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Permintaan akses", &prompt)
+	if !prompt.ConsentRequired || prompt.Client.Name != "SPA" || len(prompt.Scopes) != 1 {
+		t.Fatalf("unexpected prompt: %+v", prompt)
+	}
+
+	code := approve(t, h, user, query)
+
+	// Code verifier yang salah ditolak
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {oauthRedirectURI}, "code_verifier": {strings.Repeat("a", 43)}}
+	rec = oauthPost(h, "/oauth/token", form, clientID, "")
+	assertOAuthError(t, rec, http.StatusBadRequest, services.OAuthInvalidGrant)
+
+	form.Set("code_verifier", oauthVerifier)
+	rec = oauthPost(h, "/oauth/token", form, clientID, "")
+	var token services.TokenResponse
+	decodeOAuth(t, rec, http.StatusOK, &token)
+	if token.AccessToken == "" || token.RefreshToken == "" || token.Scope != models.ScopeUsersRead || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("unexpected token response: %+v", token)
+	}
+
+	// Access token dibatasi scope-nya dan tidak bisa dipakai untuk mengelola akun
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, token.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	rec = h.Request(t, http.MethodPost, "/api/user/", map[string]string{"name": "Baru"}, token.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, token.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+
+	// Persetujuan diingat untuk scope yang sama
+	rec = h.AuthRequest(t, user, http.MethodGet, "/api/oauth/authorize?"+query.Encode(), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Permintaan akses", &prompt)
+	if prompt.ConsentRequired {
+		t.Fatalf("consent should be remembered")
+	}
+
+	// Kode yang dipakai ulang ditolak dan token yang sudah diterbitkan dari kode itu dicabut
+	rec = oauthPost(h, "/oauth/token", form, clientID, "")
+	assertOAuthError(t, rec, http.StatusBadRequest, services.OAuthInvalidGrant)
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, token.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestOAuthAuthorizeErrors(t *testing.T) {
+	h := testutil.New(t)
+	client := createOAuthClient(t, h, map[string]interface{}{
+		"name":          "SPA",
+		"redirect_uris": []string{oauthRedirectURI},
+		"scopes":        []string{models.ScopeUsersRead},
+	})
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	// Redirect URI yang tidak terdaftar tidak boleh dipakai untuk redirect
+	query := authorizeQuery(client.Client.ClientID, models.ScopeUsersRead)
+	query.Set("redirect_uri", "https://evil.example.com/callback")
+	rec := h.AuthRequest(t, user, http.MethodGet, "/api/oauth/authorize?"+query.Encode(), nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, services.ErrInvalidRedirectURI.Message)
+
+	// Scope di luar izin client dan request tanpa PKCE dikembalikan ke client
+	for _, mutate := range []func(url.Values){
+		func(q url.Values) { q.Set("scope", models.ScopeAdmin) },
+		func(q url.Values) { q.Del("code_challenge") },
+	} {
+		query = authorizeQuery(client.Client.ClientID, models.ScopeUsersRead)
+		mutate(query)
+		rec = h.AuthRequest(t, user, http.MethodGet, "/api/oauth/authorize?"+query.Encode(), nil)
+		response := testutil.DecodeResponse(t, rec)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), oauthRedirectURI) {
+			t.Fatalf("status = %d, body = %s (%+v)", rec.Code, rec.Body.String(), response)
+		}
+	}
+
+	// User menolak: client menerima error access_denied beserta state
+	body := map[string]interface{}{"approve": false}
+	query = authorizeQuery(client.Client.ClientID, models.ScopeUsersRead)
+	for key := range query {
+		body[key] = query.Get(key)
+	}
+	rec = h.AuthRequest(t, user, http.MethodPost, "/api/oauth/authorize", body)
+	var data struct {
+		RedirectTo string `json:"redirect_to"`
+	}
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Akses ditolak", &data)
+	redirect, _ := url.Parse(data.RedirectTo)
+	if redirect.Query().Get("error") != services.OAuthAccessDenied || redirect.Query().Get("state") != "xyz" {
+		t.Fatalf("redirect_to = %q", data.RedirectTo)
+	}
+}
+
+func TestOAuthRefreshRevokeAndIntrospect(t *testing.T) {
+	h := testutil.New(t)
+	client := createOAuthClient(t, h, map[string]interface{}{
+		"name":          "Backend",
+		"redirect_uris": []string{oauthRedirectURI},
+		"scopes":        []string{models.ScopeUsersRead, models.ScopeRolesRead},
+		"confidential":  true,
+		"first_party":   true,
+	})
+	clientID, secret := client.Client.ClientID, client.ClientSecret
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	// Client first party tidak memerlukan persetujuan
+	query := authorizeQuery(clientID, "")
+	var prompt services.AuthorizationPrompt
+	rec := h.AuthRequest(t, user, http.MethodGet, "/api/oauth/authorize?"+query.Encode(), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Permintaan akses", &prompt)
+	if prompt.ConsentRequired || len(prompt.Scopes) != 2 {
+		t.Fatalf("unexpected prompt: %+v", prompt)
+	}
+	code := approve(t, h, user, query)
+
+	// Client confidential wajib mengirim secret yang benar
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {oauthRedirectURI}, "code_verifier": {oauthVerifier}}
+	rec = oauthPost(h, "/oauth/token", form, clientID, "wrong")
+	assertOAuthError(t, rec, http.StatusUnauthorized, services.OAuthInvalidClient)
+
+	var token services.TokenResponse
+	decodeOAuth(t, oauthPost(h, "/oauth/token", form, clientID, secret), http.StatusOK, &token)
+
+	// Refresh token diganti setiap dipakai, scope boleh dipersempit
+	refresh := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {token.RefreshToken}, "scope": {models.ScopeRolesRead}}
+	var refreshed services.TokenResponse
+	decodeOAuth(t, oauthPost(h, "/oauth/token", refresh, clientID, secret), http.StatusOK, &refreshed)
+	if refreshed.RefreshToken == token.RefreshToken || refreshed.Scope != models.ScopeRolesRead {
+		t.Fatalf("unexpected refresh response: %+v", refreshed)
+	}
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, refreshed.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+
+	var info services.Introspection
+	decodeOAuth(t, oauthPost(h, "/oauth/introspect", url.Values{"token": {refreshed.AccessToken}}, clientID, secret), http.StatusOK, &info)
+	if !info.Active || info.ClientID != clientID || info.Username != user.Email || info.Scope != models.ScopeRolesRead {
+		t.Fatalf("unexpected introspection: %+v", info)
+	}
+	decodeOAuth(t, oauthPost(h, "/oauth/introspect", url.Values{"token": {refreshed.RefreshToken}}, clientID, secret), http.StatusOK, &info)
+	if !info.Active || info.TokenType != "refresh_token" {
+		t.Fatalf("unexpected introspection: %+v", info)
+	}
+
+	// Mencabut refresh token ikut mencabut semua access token dari grant yang sama
+	rec = oauthPost(h, "/oauth/revoke", url.Values{"token": {refreshed.RefreshToken}}, clientID, secret)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	for _, accessToken := range []string{token.AccessToken, refreshed.AccessToken} {
+		rec = h.Request(t, http.MethodGet, "/api/role/", nil, accessToken)
+		testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	}
+	info = services.Introspection{}
+	decodeOAuth(t, oauthPost(h, "/oauth/introspect", url.Values{"token": {refreshed.AccessToken}}, clientID, secret), http.StatusOK, &info)
+	if info.Active {
+		t.Fatalf("revoked token still active: %+v", info)
+	}
+
+	// Token yang tidak dikenal tetap dijawab 200
+	rec = oauthPost(h, "/oauth/revoke", url.Values{"token": {"unknown"}}, clientID, secret)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+}
+
+func TestOAuthRefreshTokenReuseRevokesGrant(t *testing.T) {
+	h := testutil.New(t)
+	client := createOAuthClient(t, h, map[string]interface{}{
+		"name":          "Backend",
+		"redirect_uris": []string{oauthRedirectURI},
+		"scopes":        []string{models.ScopeRolesRead},
+		"confidential":  true,
+		"first_party":   true,
+	})
+	clientID, secret := client.Client.ClientID, client.ClientSecret
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	code := approve(t, h, user, authorizeQuery(clientID, ""))
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {oauthRedirectURI}, "code_verifier": {oauthVerifier}}
+	var token services.TokenResponse
+	decodeOAuth(t, oauthPost(h, "/oauth/token", form, clientID, secret), http.StatusOK, &token)
+
+	var refreshed services.TokenResponse
+	refresh := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {token.RefreshToken}}
+	decodeOAuth(t, oauthPost(h, "/oauth/token", refresh, clientID, secret), http.StatusOK, &refreshed)
+
+	// Refresh token lama dipakai ulang: grant dicabut, termasuk refresh token dan access token terbaru
+	assertOAuthError(t, oauthPost(h, "/oauth/token", refresh, clientID, secret), http.StatusBadRequest, services.OAuthInvalidGrant)
+	refresh.Set("refresh_token", refreshed.RefreshToken)
+	assertOAuthError(t, oauthPost(h, "/oauth/token", refresh, clientID, secret), http.StatusBadRequest, services.OAuthInvalidGrant)
+	rec := h.Request(t, http.MethodGet, "/api/role/", nil, refreshed.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+
+	// Grant yang dicabut tetap ditolak setelah blacklist dikosongkan
+	rec = h.Request(t, http.MethodPost, "/api/secret/clear-black-list", map[string]string{"password": "secret123"}, "")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	rec = h.Request(t, http.MethodGet, "/api/role/", nil, refreshed.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+
+	// Replica lain dengan blacklist kosong membaca status grant dari database
+	t.Setenv("BLACKLIST_FILE", filepath.Join(t.TempDir(), "replica.json"))
+	utils.InitBlacklist()
+	rec = h.Request(t, http.MethodGet, "/api/role/", nil, refreshed.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	// Client public tidak boleh memakai client credentials
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/admin/oauth/clients", map[string]interface{}{
+		"name":        "Public",
+		"grant_types": []string{models.GrantClientCredentials},
+		"scopes":      []string{models.ScopeUsersRead},
+	})
+	testutil.AssertError(t, rec, http.StatusBadRequest, services.ErrInvalidOAuthClient.Message)
+
+	client := createOAuthClient(t, h, map[string]interface{}{
+		"name":         "Worker",
+		"grant_types":  []string{models.GrantClientCredentials},
+		"scopes":       []string{models.ScopeUsersRead},
+		"confidential": true,
+	})
+	clientID, secret := client.Client.ClientID, client.ClientSecret
+
+	rec = oauthPost(h, "/oauth/token", url.Values{"grant_type": {"client_credentials"}, "scope": {models.ScopeAdmin}}, clientID, secret)
+	assertOAuthError(t, rec, http.StatusBadRequest, services.OAuthInvalidScope)
+	rec = oauthPost(h, "/oauth/token", url.Values{"grant_type": {"password"}}, clientID, secret)
+	assertOAuthError(t, rec, http.StatusBadRequest, services.OAuthUnsupportedGrantType)
+
+	var token services.TokenResponse
+	decodeOAuth(t, oauthPost(h, "/oauth/token", url.Values{"grant_type": {"client_credentials"}}, clientID, secret), http.StatusOK, &token)
+	if token.RefreshToken != "" || token.Scope != models.ScopeUsersRead {
+		t.Fatalf("unexpected token response: %+v", token)
+	}
+
+	// Token tanpa user hanya untuk resource server lain lewat introspeksi
+	rec = h.Request(t, http.MethodGet, "/api/user/", nil, token.AccessToken)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	var info services.Introspection
+	decodeOAuth(t, oauthPost(h, "/oauth/introspect", url.Values{"token": {token.AccessToken}}, clientID, secret), http.StatusOK, &info)
+	if !info.Active || info.Sub != clientID {
+		t.Fatalf("unexpected introspection: %+v", info)
+	}
+
+	// Menghapus client mencabut token-nya
+	rec = h.AuthRequest(t, admin, http.MethodDelete, "/api/admin/oauth/clients/"+strconv.Itoa(int(client.Client.ID)), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Client OAuth berhasil dihapus", nil)
+	rec = oauthPost(h, "/oauth/introspect", url.Values{"token": {token.AccessToken}}, clientID, secret)
+	assertOAuthError(t, rec, http.StatusUnauthorized, services.OAuthInvalidClient)
+}
@@ -0,0 +1,205 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+// newOIDCServer menjalankan aplikasi di server HTTP lokal agar relying party bisa memakai
+// discovery dan JWKS. OAUTH_ISSUER harus sama dengan URL server, jadi port dipesan sebelum harness dibuat.
+func newOIDCServer(t *testing.T) (*testutil.Harness, *httptest.Server) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OAUTH_ISSUER", "http://"+listener.Addr().String())
+
+	h := testutil.New(t)
+	server := httptest.NewUnstartedServer(h.Router)
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return h, server
+}
+
+// exchangeCode menukar authorization code di endpoint token server lokal
+func exchangeCode(t *testing.T, server *httptest.Server, clientID, code string) map[string]interface{} {
+	t.Helper()
+	resp, err := http.PostForm(server.URL+"/oauth/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {clientID},
+		"code":          {code},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {oauthVerifier},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var token map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("token status = %d, body = %v", resp.StatusCode, token)
+	}
+	return token
+}
+
+func TestOpenIDConnectRelyingParty(t *testing.T) {
+	h, server := newOIDCServer(t)
+	ctx := oidc.ClientContext(context.Background(), server.Client())
+
+	client := createOAuthClient(t, h, map[string]interface{}{
+		"name":          "Relying Party",
+		"redirect_uris": []string{oauthRedirectURI},
+		"scopes":        models.OIDCScopes,
+	})
+	clientID := client.Client.ClientID
+	user := h.CreateUser(t, testutil.UserAttrs{Name: "Budi", Email: "budi@example.com"})
+	var role models.Role
+	h.DB.First(&role, user.IDRole)
+
+	// Discovery dan JWKS dibaca langsung oleh library relying party
+	provider, err := oidc.NewProvider(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("discovery: %v", err)
+	}
+	if provider.Endpoint().TokenURL != server.URL+"/oauth/token" {
+		t.Fatalf("token endpoint = %q", provider.Endpoint().TokenURL)
+	}
+
+	query := authorizeQuery(clientID, "openid profile email roles")
+	query.Set("nonce", "n-0S6_WzA2Mj")
+	token := exchangeCode(t, server, clientID, approve(t, h, user, query))
+
+	rawIDToken, _ := token["id_token"].(string)
+	if rawIDToken == "" {
+		t.Fatalf("no id_token in %v", token)
+	}
+	verifier := provider.Verifier(&oidc.Config{ClientID: clientID, Now: h.Clock.Now})
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		t.Fatalf("verify id_token: %v", err)
+	}
+	if idToken.Nonce != "n-0S6_WzA2Mj" || idToken.Subject != strconv.Itoa(int(user.ID)) {
+		t.Fatalf("nonce = %q, sub = %q", idToken.Nonce, idToken.Subject)
+	}
+	accessToken := token["access_token"].(string)
+	if err := idToken.VerifyAccessToken(accessToken); err != nil {
+		t.Fatalf("at_hash: %v", err)
+	}
+
+	var claims struct {
+		Name  string   `json:"name"`
+		Email string   `json:"email"`
+		Roles []string `json:"roles"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Name != "Budi" || claims.Email != "budi@example.com" || len(claims.Roles) != 1 || claims.Roles[0] != role.Name {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	// Userinfo dengan access token yang sama mengembalikan subject yang sama
+	info, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}))
+	if err != nil {
+		t.Fatalf("userinfo: %v", err)
+	}
+	if info.Subject != idToken.Subject || info.Email != "budi@example.com" {
+		t.Fatalf("userinfo = %+v", info)
+	}
+}
+
+func TestOpenIDConnectScopes(t *testing.T) {
+	h := testutil.New(t)
+	client := createOAuthClient(t, h, map[string]interface{}{
+		"name":          "Reader",
+		"redirect_uris": []string{oauthRedirectURI},
+		"scopes":        []string{models.ScopeOpenID, models.ScopeEmail, models.ScopeUsersRead},
+	})
+	clientID := client.Client.ClientID
+	user := h.CreateUser(t, testutil.UserAttrs{})
+
+	t.Run("id token hanya berisi claim sesuai scope", func(t *testing.T) {
+		code := approve(t, h, user, authorizeQuery(clientID, "openid"))
+		var token struct {
+			AccessToken string `json:"access_token"`
+			IDToken     string `json:"id_token"`
+		}
+		decodeOAuth(t, oauthPost(h, "/oauth/token", url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"redirect_uri":  {oauthRedirectURI},
+			"code_verifier": {oauthVerifier},
+		}, clientID, ""), http.StatusOK, &token)
+
+		var claims map[string]interface{}
+		decodeOAuth(t, userInfo(h, token.AccessToken), http.StatusOK, &claims)
+		if claims["sub"] != strconv.Itoa(int(user.ID)) || claims["email"] != nil || claims["name"] != nil {
+			t.Fatalf("claims = %v", claims)
+		}
+	})
+
+	t.Run("token tanpa scope openid tidak mendapat id token dan userinfo", func(t *testing.T) {
+		code := approve(t, h, user, authorizeQuery(clientID, models.ScopeUsersRead))
+		var token map[string]interface{}
+		decodeOAuth(t, oauthPost(h, "/oauth/token", url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"redirect_uri":  {oauthRedirectURI},
+			"code_verifier": {oauthVerifier},
+		}, clientID, ""), http.StatusOK, &token)
+		if _, ok := token["id_token"]; ok {
+			t.Fatalf("unexpected id_token")
+		}
+
+		rec := userInfo(h, token["access_token"].(string))
+		assertOAuthError(t, rec, http.StatusForbidden, "insufficient_scope")
+		if !strings.Contains(rec.Header().Get("WWW-Authenticate"), "insufficient_scope") {
+			t.Fatalf("WWW-Authenticate = %q", rec.Header().Get("WWW-Authenticate"))
+		}
+	})
+
+	t.Run("token login biasa ditolak userinfo", func(t *testing.T) {
+		assertOAuthError(t, userInfo(h, h.Token(t, user)), http.StatusForbidden, "insufficient_scope")
+	})
+
+	t.Run("discovery memakai issuer dari konfigurasi", func(t *testing.T) {
+		var doc struct {
+			Issuer  string   `json:"issuer"`
+			JWKSURI string   `json:"jwks_uri"`
+			Algs    []string `json:"id_token_signing_alg_values_supported"`
+		}
+		decodeOAuth(t, h.Request(t, http.MethodGet, "/.well-known/openid-configuration", nil, ""), http.StatusOK, &doc)
+		if doc.JWKSURI != doc.Issuer+"/oauth/jwks" || len(doc.Algs) != 1 || doc.Algs[0] != "RS256" {
+			t.Fatalf("discovery = %+v", doc)
+		}
+	})
+}
+
+// userInfo memanggil endpoint userinfo dengan access token
+func userInfo(h *testutil.Harness, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang-starter-kit/services"
+	"golang-starter-kit/utils"
+)
+
+type PasswordlessRequestInput struct {
+	Email  string `json:"email" binding:"required,email"`
+	Method string `json:"method" binding:"required,oneof=link code"` // link = magic link, code = kode 6 digit
+	Locale string `json:"locale" binding:"omitempty,max=10"`         // Bahasa email, contoh: id, en
+}
+
+// RequestPasswordlessLogin mengirim magic link atau kode login ke email. Response selalu sama
+// untuk email terdaftar maupun tidak.
+func RequestPasswordlessLogin(c *gin.Context) {
+	var input PasswordlessRequestInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	err := passwordlessService.Request(c.Request.Context(), services.PasswordlessRequestParams{
+		Email:  input.Email,
+		Method: input.Method,
+		Locale: input.Locale,
+	})
+	if err != nil {
+		respondError(c, err, "Gagal mengirim link atau kode login")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Jika email terdaftar dan boleh login tanpa password, link atau kode login telah dikirim", nil))
+}
+
+type PasswordlessLinkInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// LoginWithMagicLink menukar token dari magic link dengan JWT
+func LoginWithMagicLink(c *gin.Context) {
+	var input PasswordlessLinkInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	result, err := passwordlessService.LoginWithLink(c.Request.Context(), input.Token)
+	if err != nil {
+		respondError(c, err, "Gagal login")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Login berhasil", loginData(result)))
+}
+
+type PasswordlessCodeInput struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// LoginWithCode menukar kode login dari email dengan JWT
+func LoginWithCode(c *gin.Context) {
+	var input PasswordlessCodeInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	result, err := passwordlessService.LoginWithCode(c.Request.Context(), input.Email, input.Code)
+	if err != nil {
+		respondError(c, err, "Gagal login")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Login berhasil", loginData(result)))
+}
@@ -0,0 +1,220 @@
+package controllers_test
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+// loginCodePattern mengambil kode 6 digit dari email kode login
+var loginCodePattern = regexp.MustCompile(`(?m)^(\d{6})$`)
+
+// newPasswordlessHarness mengaktifkan login tanpa password. env berisi pengaturan tambahan PASSWORDLESS_*.
+func newPasswordlessHarness(t *testing.T, env map[string]string) *testutil.Harness {
+	t.Helper()
+	t.Setenv("PASSWORDLESS_ENABLED", "true")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return testutil.New(t)
+}
+
+// loginCode membaca kode login terakhir yang dikirim ke email
+func loginCode(t *testing.T, h *testutil.Harness, to string) string {
+	t.Helper()
+	msg, ok := h.Mailer.Last(to)
+	if !ok {
+		t.Fatalf("no email sent to %s", to)
+	}
+	match := loginCodePattern.FindStringSubmatch(msg.Text)
+	if match == nil {
+		t.Fatalf("no login code in email: %s", msg.Text)
+	}
+	return match[1]
+}
+
+const passwordlessInvalid = "Link atau kode login tidak valid atau sudah kadaluwarsa"
+
+func TestPasswordlessDisabled(t *testing.T) {
+	h := testutil.New(t)
+	h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+
+	rec := h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+	testutil.AssertError(t, rec, http.StatusForbidden, "Login tanpa password tidak diaktifkan")
+	if len(h.Mailer.Messages()) != 0 {
+		t.Fatal("email sent while passwordless login is disabled")
+	}
+}
+
+func TestPasswordlessMagicLink(t *testing.T) {
+	h := newPasswordlessHarness(t, nil)
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+
+	rec := h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "sms"}, "")
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Field 'Method' tidak valid")
+
+	// Email yang tidak terdaftar mendapat response yang sama, tanpa email terkirim
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "tidakada@example.com", "method": "link"}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Jika email terdaftar dan boleh login tanpa password, link atau kode login telah dikirim", nil)
+	if len(h.Mailer.Messages()) != 0 {
+		t.Fatal("email sent to unknown address")
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "link", "locale": "en"}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Jika email terdaftar dan boleh login tanpa password, link atau kode login telah dikirim", nil)
+	if msg, _ := h.Mailer.Last("budi@example.com"); msg.Subject != "Your sign-in link" {
+		t.Fatalf("subject = %q", msg.Subject)
+	}
+	link := linkToken(t, h, "budi@example.com")
+
+	// Hanya hash yang disimpan
+	var stored models.LoginCode
+	h.DB.Where("user_id = ?", user.ID).First(&stored)
+	if stored.CodeHash == "" || stored.CodeHash == link {
+		t.Fatalf("unexpected code hash: %q", stored.CodeHash)
+	}
+
+	var data ssoLoginData
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/link", map[string]string{"token": link}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", &data)
+	if data.User.ID != user.ID || data.Token == "" {
+		t.Fatalf("unexpected login data: %+v", data)
+	}
+
+	// JWT sama dengan hasil login biasa
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, data.Token)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	// Link hanya bisa dipakai sekali
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/link", map[string]string{"token": link}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+
+	// Link kadaluwarsa setelah PASSWORDLESS_TTL_MINUTES
+	h.Clock.Advance(time.Minute)
+	h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "link"}, "")
+	link = linkToken(t, h, "budi@example.com")
+	h.Clock.Advance(11 * time.Minute)
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/link", map[string]string{"token": link}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+}
+
+func TestPasswordlessCode(t *testing.T) {
+	h := newPasswordlessHarness(t, map[string]string{"PASSWORDLESS_MAX_ATTEMPTS": "3"})
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+
+	rec := h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	code := loginCode(t, h, "budi@example.com")
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	// Permintaan ulang sebelum jeda tidak mengirim kode baru
+	h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+	if count := len(h.Mailer.Messages()); count != 1 {
+		t.Fatalf("sent %d emails, want 1", count)
+	}
+
+	// Setelah batas percobaan habis, kode yang benar pun ditolak
+	for i := 0; i < 3; i++ {
+		rec = h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": wrong}, "")
+		testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+	}
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": code}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+
+	var failed int64
+	h.DB.Model(&models.LoginAttempt{}).Where("user_id = ? AND reason = ?", user.ID, models.LoginFailedInvalidCode).Count(&failed)
+	if failed != 4 {
+		t.Fatalf("recorded %d failed attempts, want 4", failed)
+	}
+
+	// Kode baru bisa diminta setelah jeda dan langsung menerbitkan JWT
+	h.Clock.Advance(time.Minute)
+	h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+	code = loginCode(t, h, "budi@example.com")
+
+	var data ssoLoginData
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": code}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", &data)
+	if data.User.ID != user.ID {
+		t.Fatalf("logged in as %d, want %d", data.User.ID, user.ID)
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": code}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+}
+
+func TestPasswordlessCodeLockoutSpansCodes(t *testing.T) {
+	h := newPasswordlessHarness(t, map[string]string{
+		"PASSWORDLESS_MAX_ATTEMPTS":     "3",
+		"PASSWORDLESS_LOCKOUT_ATTEMPTS": "5",
+		"PASSWORDLESS_LOCKOUT_MINUTES":  "15",
+	})
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+
+	// Meminta kode baru setiap jeda tidak mengembalikan jatah percobaan
+	var code string
+	for _, wrongs := range []int{3, 2} {
+		h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+		code = loginCode(t, h, "budi@example.com")
+		wrong := "000000"
+		if code == wrong {
+			wrong = "111111"
+		}
+		for i := 0; i < wrongs; i++ {
+			rec := h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": wrong}, "")
+			testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+		}
+		h.Clock.Advance(time.Minute)
+	}
+
+	// Kode yang benar pun ditolak dan kode baru tidak dikirim selama user terkunci
+	rec := h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": code}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	if count := len(h.Mailer.Messages()); count != 2 {
+		t.Fatalf("sent %d emails, want 2", count)
+	}
+
+	// Setelah percobaan gagal keluar dari rentang waktu, user bisa login lagi
+	h.Clock.Advance(15 * time.Minute)
+	h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+	code = loginCode(t, h, "budi@example.com")
+	var data ssoLoginData
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": code}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", &data)
+	if data.User.ID != user.ID {
+		t.Fatalf("logged in as %d, want %d", data.User.ID, user.ID)
+	}
+}
+
+func TestPasswordlessRoles(t *testing.T) {
+	h := newPasswordlessHarness(t, map[string]string{"PASSWORDLESS_ROLES": models.UserRoleName})
+	userRole := h.SystemRole(t, models.UserRoleName)
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com", IDRole: userRole.ID})
+	adminRole := h.SystemRole(t, models.AdminRoleName)
+	h.CreateUser(t, testutil.UserAttrs{Email: "admin@example.com", IDRole: adminRole.ID})
+
+	// Role yang tidak diizinkan tidak mendapat email, response tetap sama
+	rec := h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "admin@example.com", "method": "code"}, "")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	if _, ok := h.Mailer.Last("admin@example.com"); ok {
+		t.Fatal("email sent to role without passwordless login")
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless", map[string]string{"email": "budi@example.com", "method": "code"}, "")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	code := loginCode(t, h, "budi@example.com")
+
+	// Role diperiksa lagi saat kode ditukar
+	h.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("id_role", adminRole.ID)
+	rec = h.Request(t, http.MethodPost, "/api/login/passwordless/code", map[string]string{"email": "budi@example.com", "code": code}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, passwordlessInvalid)
+}
@@ -2,21 +2,23 @@ package controllers
 
 import (
 	"net/http"
-	"time"
-	"github.com/gin-gonic/gin"	// Framework web Gin
-	"golang-starter-kit/models" // Model database
-	"golang-starter-kit/utils"  // Helper untuk (response)
+	"strconv"
+
+	"github.com/gin-gonic/gin"    // Framework web Gin
+	"golang-starter-kit/services" // Aturan bisnis (role)
+	"golang-starter-kit/utils"    // Helper untuk (response)
 )
 
+// GetRoles menampilkan semua role, admin bisa menambahkan ?with_deleted atau ?only_deleted
 func GetRoles(c *gin.Context) {
-	var roles []models.Role
-
-	// Mengambil semua role yang belum dihapus (deleted_at IS NULL)
-	result := models.DB.Where("deleted_at IS NULL").Find(&roles)
+	scope, ok := deletedScope(c)
+	if !ok {
+		return
+	}
 
-	// Jika terjadi error saat mengambil data, kirim response error
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal mengambil data", nil))
+	roles, err := roleService.List(c.Request.Context(), scope)
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data")
 		return
 	}
 
@@ -30,7 +32,6 @@ type CreateRoleInput struct {
 
 func CreateRole(c *gin.Context) {
 	var input CreateRoleInput
-	var role models.Role
 
 	// Input Validation
 	ok, resp := utils.InputValidation(c, &input)
@@ -39,31 +40,27 @@ func CreateRole(c *gin.Context) {
 		return
 	}
 
-	// Buat object role baru dengan data dari input
-	role = models.Role{
-		Name: input.Name,
-		CreatedAt: time.Now(),
-	}
-
-	// Kondisi Create
-	if err := models.DB.Create(&role).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal membuat role", nil))
+	role, err := roleService.Create(c.Request.Context(), input.Name)
+	if err != nil {
+		respondError(c, err, "Gagal membuat role")
 		return
 	}
-	
+
 	// Response success
 	c.JSON(http.StatusOK, utils.APIResponseSuccess("Role berhasil dibuat", role))
 }
 
 // GetRoleByID menampilkan detail role berdasarkan ID
 func GetRoleByID(c *gin.Context) {
-	id := c.Param("id")
-	
-	var role models.Role
-	
-	// Kondisi data ada atau tidak
-	if err := models.DB.Where("deleted_at IS NULL").First(&role, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.APIResponseError("Role tidak ditemukan", nil))
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrRoleNotFound, "")
+		return
+	}
+
+	role, err := roleService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data")
 		return
 	}
 
@@ -77,9 +74,12 @@ type UpdateRoleInput struct {
 
 // UpdateRole mengubah data role
 func UpdateRole(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrRoleNotFound, "")
+		return
+	}
 
-	var role models.Role
 	var input UpdateRoleInput
 
 	// Input Validation
@@ -89,45 +89,37 @@ func UpdateRole(c *gin.Context) {
 		return
 	}
 
-	// Chek Role ada atau tidak
-	if err := models.DB.Where("deleted_at IS NULL").First(&role, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.APIResponseError("Role tidak ditemukan", nil))
+	role, err := roleService.Update(c.Request.Context(), id, input.Name)
+	if err != nil {
+		respondError(c, err, "Gagal mengupdate role")
 		return
 	}
 
-	// Buat object role baru dengan data dari input
-	if input.Name != nil && *input.Name != "" {
-		role.Name = *input.Name
-	}
-
-	// Kondisi Save
-	if err := models.DB.Save(&role).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal mengupdate role", nil))
-		return
-	}
-	
 	// Data berhasil di update
 	c.JSON(http.StatusOK, utils.APIResponseSuccess("Role berhasil diupdate", role))
 }
 
-// Delete Role (Soft Delete)
+// Delete Role (Soft Delete), user yang masih memakai role bisa dipindahkan lewat ?reassign_to=ID
 func DeleteRole(c *gin.Context) {
-	id := c.Param("id")
-
-	var role models.Role
-	
-	// Check Role
-	if err := models.DB.Where("deleted_at IS NULL").First(&role, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.APIResponseError("Role tidak ditemukan", nil))
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrRoleNotFound, "")
 		return
 	}
 
-	// Field model
-	now := time.Now()
+	var reassignTo *uint
+	if value := c.Query("reassign_to"); value != "" {
+		toID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			respondError(c, services.ErrInvalidReassignRole, "")
+			return
+		}
+		target := uint(toID)
+		reassignTo = &target
+	}
 
-	// Kondisi check update deleted_at
-	if err := models.DB.Model(&role).Update("deleted_at", &now).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal menghapus role", nil))
+	if err := roleService.Delete(c.Request.Context(), id, reassignTo); err != nil {
+		respondError(c, err, "Gagal menghapus role")
 		return
 	}
 
@@ -0,0 +1,104 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+func TestRoleCRUD(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateUser(t, testutil.UserAttrs{})
+
+	// Create
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/role/", map[string]string{"name": "editor"})
+	var created models.Role
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Role berhasil dibuat", &created)
+
+	// List
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/role/", nil)
+	var roles []models.Role
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Data berhasil diambil", &roles)
+	// Role sistem (admin, user) + role milik user + role baru
+	if len(roles) != 4 {
+		t.Fatalf("len(roles) = %d, want 4", len(roles))
+	}
+
+	// Update
+	path := fmt.Sprintf("/api/role/%d", created.ID)
+	rec = h.AuthRequest(t, admin, http.MethodPut, path, map[string]string{"name": "penulis"})
+	var updated models.Role
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Role berhasil diupdate", &updated)
+	if updated.Name != "penulis" {
+		t.Fatalf("name = %q, want %q", updated.Name, "penulis")
+	}
+
+	// Delete
+	rec = h.AuthRequest(t, admin, http.MethodDelete, path, nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Role berhasil dihapus", nil)
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, path, nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "Role tidak ditemukan")
+}
+
+func TestCreateRoleValidation(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/role/", map[string]string{})
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Field 'Name' wajib diisi")
+}
+
+func TestDeleteRoleInUse(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+	path := fmt.Sprintf("/api/role/%d", member.IDRole)
+
+	rec := h.AuthRequest(t, admin, http.MethodDelete, path, nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Role masih dipakai oleh user, gunakan ?reassign_to= untuk memindahkan user ke role lain")
+
+	rec = h.AuthRequest(t, admin, http.MethodDelete, path+"?reassign_to=999", nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Role tujuan reassign tidak valid")
+
+	rec = h.AuthRequest(t, admin, http.MethodDelete, fmt.Sprintf("%s?reassign_to=%d", path, member.IDRole), nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Role tujuan reassign tidak valid")
+
+	target := h.CreateRole(t, testutil.RoleAttrs{})
+	rec = h.AuthRequest(t, admin, http.MethodDelete, fmt.Sprintf("%s?reassign_to=%d", path, target.ID), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Role berhasil dihapus", nil)
+
+	var moved models.User
+	h.DB.Preload("Role").First(&moved, member.ID)
+	if moved.IDRole != target.ID || moved.Role.Name != target.Name {
+		t.Fatalf("user not reassigned: %+v", moved)
+	}
+}
+
+func TestSystemRolesAreProtected(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	path := fmt.Sprintf("/api/role/%d", admin.IDRole)
+
+	rec := h.AuthRequest(t, admin, http.MethodDelete, path, nil)
+	testutil.AssertError(t, rec, http.StatusForbidden, "Role sistem tidak dapat dihapus atau diganti nama")
+
+	rec = h.AuthRequest(t, admin, http.MethodPut, path, map[string]string{"name": "superadmin"})
+	testutil.AssertError(t, rec, http.StatusForbidden, "Role sistem tidak dapat dihapus atau diganti nama")
+
+	// Menyimpan dengan nama yang sama tetap diperbolehkan
+	rec = h.AuthRequest(t, admin, http.MethodPut, path, map[string]string{"name": models.AdminRoleName})
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Role berhasil diupdate", nil)
+}
+
+func TestRoleForeignKey(t *testing.T) {
+	h := testutil.New(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+
+	if err := h.DB.Unscoped().Delete(&models.Role{}, member.IDRole).Error; err == nil {
+		t.Fatal("hard deleting a referenced role should violate the foreign key")
+	}
+}
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"    // Framework web Gin
+	"golang-starter-kit/services" // Aturan bisnis (SCIM)
+)
+
+// scimContentType adalah media type request dan response SCIM (RFC 7644 bagian 3.1)
+const scimContentType = "application/scim+json; charset=utf-8"
+
+// GetSCIMServiceProviderConfig menampilkan fitur SCIM yang didukung
+func GetSCIMServiceProviderConfig(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, scimService.ServiceProviderConfig())
+}
+
+// GetSCIMResourceTypes menampilkan resource type User dan Group
+func GetSCIMResourceTypes(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, scimService.ResourceTypes())
+}
+
+// GetSCIMResourceType menampilkan satu resource type
+func GetSCIMResourceType(c *gin.Context) {
+	resourceType, err := scimService.ResourceType(c.Param("id"))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, resourceType)
+}
+
+// GetSCIMSchemas menampilkan definisi schema User dan Group
+func GetSCIMSchemas(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, scimService.Schemas())
+}
+
+// GetSCIMSchema menampilkan satu definisi schema berdasarkan URN
+func GetSCIMSchema(c *gin.Context) {
+	schema, err := scimService.Schema(c.Param("id"))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, schema)
+}
+
+// GetSCIMUsers menampilkan user, mendukung ?filter=, ?startIndex= dan ?count=
+func GetSCIMUsers(c *gin.Context) {
+	list, err := scimService.ListUsers(c.Request.Context(), scimListParams(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, list)
+}
+
+// GetSCIMUser menampilkan satu user
+func GetSCIMUser(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrUserNotFound)
+		return
+	}
+
+	user, err := scimService.GetUser(c.Request.Context(), id)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// CreateSCIMUser membuat user dari client SCIM
+func CreateSCIMUser(c *gin.Context) {
+	var input services.SCIMUserInput
+	if !bindSCIM(c, &input) {
+		return
+	}
+
+	user, err := scimService.CreateUser(c.Request.Context(), input)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Header("Location", user.Meta.Location)
+	respondSCIM(c, http.StatusCreated, user)
+}
+
+// ReplaceSCIMUser mengganti atribut user (PUT)
+func ReplaceSCIMUser(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrUserNotFound)
+		return
+	}
+	var input services.SCIMUserInput
+	if !bindSCIM(c, &input) {
+		return
+	}
+
+	user, err := scimService.ReplaceUser(c.Request.Context(), id, input)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// PatchSCIMUser mengubah sebagian atribut user, misalnya active=false untuk menonaktifkan user
+func PatchSCIMUser(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrUserNotFound)
+		return
+	}
+	var request services.SCIMPatchRequest
+	if !bindSCIM(c, &request) {
+		return
+	}
+
+	user, err := scimService.PatchUser(c.Request.Context(), id, request)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// DeleteSCIMUser menghapus user (soft delete)
+func DeleteSCIMUser(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrUserNotFound)
+		return
+	}
+
+	if err := scimService.DeleteUser(c.Request.Context(), id); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetSCIMGroups menampilkan role sebagai grup, mendukung ?filter=, ?startIndex=, ?count= dan
+// ?excludedAttributes=members
+func GetSCIMGroups(c *gin.Context) {
+	list, err := scimService.ListGroups(c.Request.Context(), scimListParams(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, list)
+}
+
+// GetSCIMGroup menampilkan satu grup beserta anggotanya
+func GetSCIMGroup(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrRoleNotFound)
+		return
+	}
+
+	group, err := scimService.GetGroup(c.Request.Context(), id, c.Query("excludedAttributes"))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// CreateSCIMGroup membuat role baru dari client SCIM
+func CreateSCIMGroup(c *gin.Context) {
+	var input services.SCIMGroupInput
+	if !bindSCIM(c, &input) {
+		return
+	}
+
+	group, err := scimService.CreateGroup(c.Request.Context(), input)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Header("Location", group.Meta.Location)
+	respondSCIM(c, http.StatusCreated, group)
+}
+
+// ReplaceSCIMGroup mengganti nama dan anggota grup (PUT)
+func ReplaceSCIMGroup(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrRoleNotFound)
+		return
+	}
+	var input services.SCIMGroupInput
+	if !bindSCIM(c, &input) {
+		return
+	}
+
+	group, err := scimService.ReplaceGroup(c.Request.Context(), id, input)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// PatchSCIMGroup menambah atau mengeluarkan anggota grup dan mengganti namanya
+func PatchSCIMGroup(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrRoleNotFound)
+		return
+	}
+	var request services.SCIMPatchRequest
+	if !bindSCIM(c, &request) {
+		return
+	}
+
+	group, err := scimService.PatchGroup(c.Request.Context(), id, request)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// DeleteSCIMGroup menghapus role, anggotanya dipindahkan ke role default
+func DeleteSCIMGroup(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondSCIMError(c, services.ErrRoleNotFound)
+		return
+	}
+
+	if err := scimService.DeleteGroup(c.Request.Context(), id); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// scimListParams membaca parameter query list resource
+func scimListParams(c *gin.Context) services.SCIMListParams {
+	params := services.SCIMListParams{
+		Filter:             c.Query("filter"),
+		ExcludedAttributes: c.Query("excludedAttributes"),
+	}
+	params.StartIndex, _ = strconv.Atoi(c.Query("startIndex"))
+	if count, err := strconv.Atoi(c.Query("count")); err == nil {
+		params.Count = &count
+	}
+	return params
+}
+
+// bindSCIM membaca body JSON request SCIM, false jika body tidak valid (response sudah dikirim)
+func bindSCIM(c *gin.Context, target interface{}) bool {
+	if err := json.NewDecoder(c.Request.Body).Decode(target); err != nil {
+		respondSCIMError(c, &services.SCIMError{Status: http.StatusBadRequest, Type: services.SCIMInvalidSyntax, Detail: "Body request tidak valid"})
+		return false
+	}
+	return true
+}
+
+// respondSCIM mengirim response dengan Content-Type application/scim+json
+func respondSCIM(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(status, body)
+}
+
+// respondSCIMError mengirim error dalam format SCIM (RFC 7644 bagian 3.12). Error service
+// dipetakan ke HTTP status sesuai Kind, konflik dilaporkan sebagai scimType uniqueness.
+func respondSCIMError(c *gin.Context, err error) {
+	scimErr := &services.SCIMError{Status: http.StatusInternalServerError, Detail: "Gagal memproses permintaan SCIM"}
+	var serviceErr *services.Error
+	switch {
+	case errors.As(err, &scimErr):
+	case errors.As(err, &serviceErr):
+		scimErr = &services.SCIMError{Status: http.StatusInternalServerError, Detail: serviceErr.Message}
+		switch serviceErr.Kind {
+		case services.KindValidation:
+			scimErr.Status, scimErr.Type = http.StatusBadRequest, services.SCIMInvalidValue
+		case services.KindConflict:
+			scimErr.Status, scimErr.Type = http.StatusConflict, services.SCIMUniqueness
+		case services.KindNotFound:
+			scimErr.Status = http.StatusNotFound
+		case services.KindUnauthorized:
+			scimErr.Status = http.StatusUnauthorized
+		case services.KindForbidden:
+			scimErr.Status = http.StatusForbidden
+		}
+	}
+
+	body := gin.H{
+		"schemas": []string{services.SCIMSchemaError},
+		"status":  strconv.Itoa(scimErr.Status),
+		"detail":  scimErr.Detail,
+	}
+	if scimErr.Type != "" {
+		body["scimType"] = scimErr.Type
+	}
+	respondSCIM(c, scimErr.Status, body)
+}
@@ -0,0 +1,397 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+	"gorm.io/gorm"
+)
+
+// scimClient mengirim request SCIM dengan personal access token admin ber-scope scim
+type scimClient struct {
+	h     *testutil.Harness
+	token string
+}
+
+// newSCIMClient membuat admin beserta token provisioning-nya
+func newSCIMClient(t *testing.T, h *testutil.Harness) *scimClient {
+	t.Helper()
+	token, _ := createToken(t, h, h.CreateAdmin(t), map[string]interface{}{"name": "Okta", "scopes": []string{models.ScopeSCIM}})
+	return &scimClient{h: h, token: token}
+}
+
+// do mengirim request lalu men-decode response ke out (boleh nil) jika status sesuai
+func (c *scimClient) do(t *testing.T, method, path string, body interface{}, status int, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := c.h.Request(t, method, "/scim/v2"+path, body, c.token)
+	testutil.AssertStatus(t, rec, status)
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("decode %s %s: %v\n%s", method, path, err, rec.Body.String())
+		}
+	}
+	return rec
+}
+
+// assertSCIMError memeriksa response error format SCIM
+func assertSCIMError(t *testing.T, rec *httptest.ResponseRecorder, status int, scimType string) {
+	t.Helper()
+	testutil.AssertStatus(t, rec, status)
+	var body struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(body.Schemas) != 1 || body.Schemas[0] != services.SCIMSchemaError || body.Status != strconv.Itoa(status) || body.ScimType != scimType {
+		t.Fatalf("error = %+v, want status %d scimType %q\n%s", body, status, scimType, rec.Body.String())
+	}
+}
+
+// scimList adalah ListResponse dengan resource bertipe T
+type scimList[T any] struct {
+	TotalResults int `json:"totalResults"`
+	StartIndex   int `json:"startIndex"`
+	ItemsPerPage int `json:"itemsPerPage"`
+	Resources    []T `json:"Resources"`
+}
+
+// patchOp membuat body PATCH dengan operasi yang diberikan
+func patchOp(operations ...map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"schemas": []string{services.SCIMSchemaPatchOp}, "Operations": operations}
+}
+
+func TestSCIMAuth(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	testutil.AssertStatus(t, h.Request(t, http.MethodGet, "/scim/v2/Users", nil, ""), http.StatusUnauthorized)
+	testutil.AssertStatus(t, h.AuthRequest(t, h.CreateUser(t, testutil.UserAttrs{}), http.MethodGet, "/scim/v2/Users", nil), http.StatusForbidden)
+
+	// Token admin tanpa scope scim ditolak
+	other, _ := createToken(t, h, admin, map[string]interface{}{"name": "CI", "scopes": []string{models.ScopeUsersRead}})
+	testutil.AssertStatus(t, h.Request(t, http.MethodGet, "/scim/v2/Users", nil, other), http.StatusForbidden)
+
+	client := newSCIMClient(t, h)
+	rec := client.do(t, http.MethodGet, "/ServiceProviderConfig", nil, http.StatusOK, nil)
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/scim+json") {
+		t.Fatalf("content type = %q", contentType)
+	}
+}
+
+func TestSCIMDiscovery(t *testing.T) {
+	h := testutil.New(t)
+	client := newSCIMClient(t, h)
+
+	var config struct {
+		Patch  struct{ Supported bool }
+		Filter struct {
+			Supported  bool
+			MaxResults int
+		}
+	}
+	client.do(t, http.MethodGet, "/ServiceProviderConfig", nil, http.StatusOK, &config)
+	if !config.Patch.Supported || !config.Filter.Supported || config.Filter.MaxResults != 200 {
+		t.Fatalf("config = %+v", config)
+	}
+
+	var types scimList[struct{ ID, Endpoint, Schema string }]
+	client.do(t, http.MethodGet, "/ResourceTypes", nil, http.StatusOK, &types)
+	if types.TotalResults != 2 || types.Resources[0].Endpoint != "/Users" || types.Resources[1].Schema != services.SCIMSchemaGroup {
+		t.Fatalf("resource types = %+v", types)
+	}
+	client.do(t, http.MethodGet, "/ResourceTypes/Group", nil, http.StatusOK, nil)
+
+	var schema struct {
+		ID         string
+		Attributes []struct{ Name string }
+	}
+	client.do(t, http.MethodGet, "/Schemas/"+services.SCIMSchemaUser, nil, http.StatusOK, &schema)
+	if schema.ID != services.SCIMSchemaUser || len(schema.Attributes) == 0 {
+		t.Fatalf("schema = %+v", schema)
+	}
+	assertSCIMError(t, client.do(t, http.MethodGet, "/Schemas/urn:unknown", nil, http.StatusNotFound, nil), http.StatusNotFound, "")
+}
+
+func TestSCIMUsers(t *testing.T) {
+	h := testutil.New(t)
+	client := newSCIMClient(t, h)
+
+	var created services.SCIMUser
+	rec := client.do(t, http.MethodPost, "/Users", map[string]interface{}{
+		"schemas":    []string{services.SCIMSchemaUser},
+		"userName":   "sari@example.org",
+		"externalId": "okta-00u1",
+		"name":       map[string]string{"givenName": "Sari", "familyName": "Dewi"},
+		"password":   "Provisioned-Secret-2024",
+		"active":     true,
+	}, http.StatusCreated, &created)
+	if created.UserName != "sari@example.org" || created.DisplayName != "Sari Dewi" || created.ExternalID != "okta-00u1" || !created.Active {
+		t.Fatalf("created = %+v", created)
+	}
+	if rec.Header().Get("Location") != "http://localhost:8080/scim/v2/Users/"+created.ID || len(created.Groups) != 1 || created.Groups[0].Display != models.UserRoleName {
+		t.Fatalf("location = %q, groups = %+v", rec.Header().Get("Location"), created.Groups)
+	}
+	client.do(t, http.MethodPost, "/Users", map[string]interface{}{"userName": "budi@example.org"}, http.StatusCreated, nil)
+
+	t.Run("validasi dan keunikan", func(t *testing.T) {
+		rec := client.do(t, http.MethodPost, "/Users", map[string]interface{}{"userName": "sari@example.org"}, http.StatusConflict, nil)
+		assertSCIMError(t, rec, http.StatusConflict, services.SCIMUniqueness)
+		rec = client.do(t, http.MethodPost, "/Users", map[string]interface{}{"userName": "rina@example.org", "externalId": "okta-00u1"}, http.StatusConflict, nil)
+		assertSCIMError(t, rec, http.StatusConflict, services.SCIMUniqueness)
+		rec = client.do(t, http.MethodPost, "/Users", map[string]interface{}{"userName": "rina"}, http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidValue)
+		rec = client.do(t, http.MethodPost, "/Users", "{not json", http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidSyntax)
+	})
+
+	t.Run("filter dan pagination", func(t *testing.T) {
+		var list scimList[services.SCIMUser]
+		client.do(t, http.MethodGet, "/Users?filter="+url.QueryEscape(`userName eq "Sari@Example.org"`), nil, http.StatusOK, &list)
+		if list.TotalResults != 1 || list.Resources[0].ID != created.ID {
+			t.Fatalf("userName filter = %+v", list)
+		}
+		client.do(t, http.MethodGet, "/Users?filter="+url.QueryEscape(`externalId eq "okta-00u1"`), nil, http.StatusOK, &list)
+		if list.TotalResults != 1 || list.Resources[0].ID != created.ID {
+			t.Fatalf("externalId filter = %+v", list)
+		}
+		client.do(t, http.MethodGet, "/Users?filter="+url.QueryEscape(`userName eq "nobody@example.org"`), nil, http.StatusOK, &list)
+		if list.TotalResults != 0 || list.Resources == nil {
+			t.Fatalf("empty filter = %+v", list)
+		}
+
+		// Admin pemilik token + 2 user SCIM
+		client.do(t, http.MethodGet, "/Users?startIndex=2&count=1", nil, http.StatusOK, &list)
+		if list.TotalResults != 3 || list.StartIndex != 2 || list.ItemsPerPage != 1 || list.Resources[0].ID != created.ID {
+			t.Fatalf("page = %+v", list)
+		}
+
+		rec := client.do(t, http.MethodGet, "/Users?filter="+url.QueryEscape(`userName co "sari"`), nil, http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidFilter)
+		rec = client.do(t, http.MethodGet, "/Users?filter="+url.QueryEscape(`title eq "x"`), nil, http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidFilter)
+	})
+
+	t.Run("identitas SCIM tidak bisa dilepas user", func(t *testing.T) {
+		var data ssoLoginData
+		rec := h.Request(t, http.MethodPost, "/api/login", map[string]string{"email": "sari@example.org", "password": "Provisioned-Secret-2024"}, "")
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", &data)
+		var identities []models.UserIdentity
+		testutil.AssertSuccess(t, h.Request(t, http.MethodGet, "/api/me/identities", nil, data.Token), http.StatusOK, "Daftar identitas", &identities)
+		if len(identities) != 1 || identities[0].Provider != models.IdentityProviderSCIM {
+			t.Fatalf("identities = %+v", identities)
+		}
+		rec = h.Request(t, http.MethodDelete, "/api/me/identities/"+strconv.Itoa(int(identities[0].ID)), nil, data.Token)
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Identitas SCIM dikelola oleh identity provider dan tidak dapat dilepas")
+	})
+
+	t.Run("PATCH gaya Azure AD menonaktifkan user", func(t *testing.T) {
+		var patched services.SCIMUser
+		client.do(t, http.MethodPatch, "/Users/"+created.ID, patchOp(
+			map[string]interface{}{"op": "Replace", "path": "active", "value": "False"},
+			map[string]interface{}{"op": "Replace", "value": map[string]interface{}{"displayName": "Sari D.", services.SCIMSchemaUser + ":title": "Engineer"}},
+			map[string]interface{}{"op": "remove", "path": "externalId"},
+		), http.StatusOK, &patched)
+		if patched.Active || patched.DisplayName != "Sari D." || patched.ExternalID != "" {
+			t.Fatalf("patched = %+v", patched)
+		}
+		rec := h.Request(t, http.MethodPost, "/api/login", map[string]string{"email": "sari@example.org", "password": "Provisioned-Secret-2024"}, "")
+		testutil.AssertError(t, rec, http.StatusForbidden, "Akun sudah dinonaktifkan")
+
+		client.do(t, http.MethodPatch, "/Users/"+created.ID, patchOp(map[string]interface{}{"op": "replace", "path": "active", "value": true}), http.StatusOK, &patched)
+		if !patched.Active {
+			t.Fatal("user should be active again")
+		}
+
+		rec = client.do(t, http.MethodPatch, "/Users/"+created.ID, patchOp(map[string]interface{}{"op": "move", "path": "active"}), http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidSyntax)
+		rec = client.do(t, http.MethodPatch, "/Users/"+created.ID, patchOp(map[string]interface{}{"op": "remove", "path": "userName"}), http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMMutability)
+	})
+
+	t.Run("PUT dan DELETE", func(t *testing.T) {
+		var replaced services.SCIMUser
+		client.do(t, http.MethodPut, "/Users/"+created.ID, map[string]interface{}{
+			"userName":    "sari.dewi@example.org",
+			"displayName": "Sari Dewi",
+			"externalId":  "okta-00u9",
+		}, http.StatusOK, &replaced)
+		if replaced.UserName != "sari.dewi@example.org" || replaced.Emails[0].Value != "sari.dewi@example.org" || replaced.ExternalID != "okta-00u9" {
+			t.Fatalf("replaced = %+v", replaced)
+		}
+
+		// PUT mengganti seluruh resource: externalId dan nama yang tidak dikirim dikosongkan
+		var cleared services.SCIMUser
+		client.do(t, http.MethodPut, "/Users/"+created.ID, map[string]interface{}{"userName": "sari.dewi@example.org"}, http.StatusOK, &cleared)
+		if cleared.ExternalID != "" || cleared.DisplayName != "sari.dewi@example.org" || !cleared.Active {
+			t.Fatalf("cleared = %+v", cleared)
+		}
+		client.do(t, http.MethodPut, "/Users/"+created.ID, map[string]interface{}{"userName": "sari.dewi@example.org", "externalId": "okta-00u9"}, http.StatusOK, nil)
+		rec := client.do(t, http.MethodPut, "/Users/"+created.ID, map[string]interface{}{"displayName": "Sari"}, http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidValue)
+
+		client.do(t, http.MethodDelete, "/Users/"+created.ID, nil, http.StatusNoContent, nil)
+		assertSCIMError(t, client.do(t, http.MethodGet, "/Users/"+created.ID, nil, http.StatusNotFound, nil), http.StatusNotFound, "")
+
+		// externalId user yang dihapus bisa dipakai lagi
+		client.do(t, http.MethodPost, "/Users", map[string]interface{}{"userName": "sari.baru@example.org", "externalId": "okta-00u9"}, http.StatusCreated, nil)
+	})
+}
+
+func TestSCIMCreateUserRollsBackOnFailure(t *testing.T) {
+	h := testutil.New(t)
+	client := newSCIMClient(t, h)
+
+	// Simulasikan kegagalan saat menyimpan externalId setelah user dibuat
+	h.DB.Callback().Create().Before("gorm:create").Register("test:fail_identity", func(db *gorm.DB) {
+		if db.Statement.Table == "user_identities" {
+			db.AddError(errors.New("database down"))
+		}
+	})
+	client.do(t, http.MethodPost, "/Users", map[string]interface{}{"userName": "sari@example.org", "externalId": "okta-00u1"}, http.StatusInternalServerError, nil)
+
+	var count int64
+	h.DB.Unscoped().Model(&models.User{}).Where("email = ?", "sari@example.org").Count(&count)
+	if count != 0 {
+		t.Fatal("user should be removed when provisioning fails halfway")
+	}
+
+	// Client bisa mengulang POST setelah database pulih
+	h.DB.Callback().Create().Remove("test:fail_identity")
+	var created services.SCIMUser
+	client.do(t, http.MethodPost, "/Users", map[string]interface{}{"userName": "sari@example.org", "externalId": "okta-00u1"}, http.StatusCreated, &created)
+	if created.ExternalID != "okta-00u1" {
+		t.Fatalf("created = %+v", created)
+	}
+}
+
+func TestSCIMCreateGroupRollsBackOnFailure(t *testing.T) {
+	h := testutil.New(t)
+	client := newSCIMClient(t, h)
+	sari := h.CreateUser(t, testutil.UserAttrs{Email: "sari@example.org"})
+	budi := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.org"})
+	members := []map[string]string{{"value": strconv.Itoa(int(sari.ID))}, {"value": strconv.Itoa(int(budi.ID))}}
+
+	// Simulasikan kegagalan saat memindahkan anggota kedua, setelah anggota pertama dipindahkan
+	updates := 0
+	h.DB.Callback().Update().Before("gorm:update").Register("test:fail_second_member", func(db *gorm.DB) {
+		if db.Statement.Table == "users" {
+			if updates++; updates == 2 {
+				db.AddError(errors.New("database down"))
+			}
+		}
+	})
+	client.do(t, http.MethodPost, "/Groups", map[string]interface{}{"displayName": "engineering", "members": members}, http.StatusInternalServerError, nil)
+
+	var count int64
+	h.DB.Unscoped().Model(&models.Role{}).Where("name = ?", "engineering").Count(&count)
+	if count != 0 {
+		t.Fatal("role should be removed when provisioning fails halfway")
+	}
+	var current models.User
+	h.DB.First(&current, sari.ID)
+	if current.IDRole != sari.IDRole {
+		t.Fatalf("id_role = %d, want previous role %d", current.IDRole, sari.IDRole)
+	}
+
+	// Client bisa mengulang POST dengan nama yang sama setelah database pulih
+	h.DB.Callback().Update().Remove("test:fail_second_member")
+	var group services.SCIMGroup
+	client.do(t, http.MethodPost, "/Groups", map[string]interface{}{"displayName": "engineering", "members": members}, http.StatusCreated, &group)
+	if len(group.Members) != 2 {
+		t.Fatalf("group = %+v", group)
+	}
+}
+
+func TestSCIMGroups(t *testing.T) {
+	h := testutil.New(t)
+	client := newSCIMClient(t, h)
+	sari := h.CreateUser(t, testutil.UserAttrs{Email: "sari@example.org"})
+	budi := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.org"})
+	sariID, budiID := strconv.Itoa(int(sari.ID)), strconv.Itoa(int(budi.ID))
+
+	roleOf := func(t *testing.T, user models.User) string {
+		t.Helper()
+		var found models.User
+		h.DB.Preload("Role").First(&found, user.ID)
+		return found.Role.Name
+	}
+
+	var group services.SCIMGroup
+	client.do(t, http.MethodPost, "/Groups", map[string]interface{}{
+		"displayName": "engineering",
+		"members":     []map[string]string{{"value": sariID}},
+	}, http.StatusCreated, &group)
+	if group.DisplayName != "engineering" || len(group.Members) != 1 || group.Members[0].Value != sariID || roleOf(t, sari) != "engineering" {
+		t.Fatalf("group = %+v", group)
+	}
+	rec := client.do(t, http.MethodPost, "/Groups", map[string]interface{}{"displayName": "engineering"}, http.StatusConflict, nil)
+	assertSCIMError(t, rec, http.StatusConflict, services.SCIMUniqueness)
+	rec = client.do(t, http.MethodPost, "/Groups", map[string]interface{}{"displayName": "ops", "members": []map[string]string{{"value": "999"}}}, http.StatusBadRequest, nil)
+	assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidValue)
+
+	t.Run("filter dan excludedAttributes", func(t *testing.T) {
+		var list scimList[services.SCIMGroup]
+		client.do(t, http.MethodGet, "/Groups?filter="+url.QueryEscape(`displayName eq "Engineering"`), nil, http.StatusOK, &list)
+		if list.TotalResults != 1 || list.Resources[0].ID != group.ID || len(list.Resources[0].Members) != 1 {
+			t.Fatalf("filter = %+v", list)
+		}
+		var roles int64
+		h.DB.Model(&models.Role{}).Count(&roles)
+		var all scimList[services.SCIMGroup]
+		client.do(t, http.MethodGet, "/Groups?excludedAttributes=members", nil, http.StatusOK, &all)
+		if all.TotalResults != int(roles) || len(all.Resources) != int(roles) {
+			t.Fatalf("groups = %d, want %d", all.TotalResults, roles)
+		}
+		for _, resource := range all.Resources {
+			if len(resource.Members) != 0 {
+				t.Fatalf("members should be excluded: %+v", resource)
+			}
+		}
+	})
+
+	t.Run("PATCH anggota", func(t *testing.T) {
+		client.do(t, http.MethodPatch, "/Groups/"+group.ID, patchOp(
+			map[string]interface{}{"op": "Add", "path": "members", "value": []map[string]string{{"value": budiID}}},
+			map[string]interface{}{"op": "Remove", "path": `members[value eq "` + sariID + `"]`},
+			map[string]interface{}{"op": "Replace", "path": "displayName", "value": "platform"},
+		), http.StatusOK, &group)
+		if group.DisplayName != "platform" || len(group.Members) != 1 || group.Members[0].Value != budiID {
+			t.Fatalf("group = %+v", group)
+		}
+		// User yang dikeluarkan dari grup kembali ke role default
+		if roleOf(t, sari) != models.UserRoleName || roleOf(t, budi) != "platform" {
+			t.Fatalf("roles = %s, %s", roleOf(t, sari), roleOf(t, budi))
+		}
+
+		rec := client.do(t, http.MethodPatch, "/Groups/"+group.ID, patchOp(map[string]interface{}{"op": "replace", "path": `members[value eq "1"]`, "value": "x"}), http.StatusBadRequest, nil)
+		assertSCIMError(t, rec, http.StatusBadRequest, services.SCIMInvalidPath)
+	})
+
+	t.Run("role sistem dilindungi", func(t *testing.T) {
+		admin := h.SystemRole(t, models.AdminRoleName)
+		path := "/Groups/" + strconv.Itoa(int(admin.ID))
+		rec := client.do(t, http.MethodPut, path, map[string]interface{}{"displayName": "superuser"}, http.StatusForbidden, nil)
+		assertSCIMError(t, rec, http.StatusForbidden, "")
+		client.do(t, http.MethodDelete, path, nil, http.StatusForbidden, nil)
+	})
+
+	t.Run("DELETE memindahkan anggota ke role default", func(t *testing.T) {
+		client.do(t, http.MethodDelete, "/Groups/"+group.ID, nil, http.StatusNoContent, nil)
+		if roleOf(t, budi) != models.UserRoleName {
+			t.Fatalf("budi role = %s", roleOf(t, budi))
+		}
+		client.do(t, http.MethodGet, "/Groups/"+group.ID, nil, http.StatusNotFound, nil)
+	})
+}
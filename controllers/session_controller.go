@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"        // Framework web Gin
+	"golang-starter-kit/middleware"   // Identitas user dan session yang sedang login
+	"golang-starter-kit/repositories" // Filter riwayat login
+	"golang-starter-kit/services"     // Aturan bisnis (session)
+	"golang-starter-kit/utils"        // Helper (response)
+)
+
+// GetMySessions menampilkan session aktif milik user yang sedang login
+func GetMySessions(c *gin.Context) {
+	sessions, err := sessionService.List(c.Request.Context(), middleware.CurrentUserID(c), middleware.CurrentSessionID(c))
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data session")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar session", sessions))
+}
+
+// RevokeMySession mencabut salah satu session milik user yang sedang login
+func RevokeMySession(c *gin.Context) {
+	err := sessionService.Revoke(c.Request.Context(), middleware.CurrentUserID(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Gagal mencabut session")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Session berhasil dicabut", nil))
+}
+
+// RevokeMyOtherSessions mencabut semua session milik user kecuali session yang sedang dipakai
+func RevokeMyOtherSessions(c *gin.Context) {
+	revoked, err := sessionService.RevokeOthers(c.Request.Context(), middleware.CurrentUserID(c), middleware.CurrentSessionID(c))
+	if err != nil {
+		respondError(c, err, "Gagal mencabut session")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Session lain berhasil dicabut", gin.H{"revoked": revoked}))
+}
+
+// GetMyLogins menampilkan riwayat login user yang sedang login (?page=, ?per_page=)
+func GetMyLogins(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+	getLoginAttempts(c, repositories.LoginAttemptFilter{UserID: &userID})
+}
+
+// GetUserSessions menampilkan session aktif milik user tertentu (admin)
+func GetUserSessions(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserNotFound, "")
+		return
+	}
+
+	sessions, err := sessionService.List(c.Request.Context(), id, "")
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data session")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar session", sessions))
+}
+
+// RevokeUserSession mencabut session milik user tertentu (admin)
+func RevokeUserSession(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserNotFound, "")
+		return
+	}
+
+	if err := sessionService.Revoke(c.Request.Context(), id, c.Param("sid")); err != nil {
+		respondError(c, err, "Gagal mencabut session")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Session berhasil dicabut", nil))
+}
+
+// GetLoginAttempts menampilkan riwayat login semua user (admin), bisa difilter dengan
+// ?user_id=, ?email= dan ?success=true|false
+func GetLoginAttempts(c *gin.Context) {
+	filter := repositories.LoginAttemptFilter{Email: c.Query("email")}
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			respondError(c, services.ErrInvalidLoginFilter, "")
+			return
+		}
+		userID := uint(id)
+		filter.UserID = &userID
+	}
+	if value := c.Query("success"); value != "" {
+		success, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(c, services.ErrInvalidLoginFilter, "")
+			return
+		}
+		filter.Success = &success
+	}
+	getLoginAttempts(c, filter)
+}
+
+// getLoginAttempts mengirim satu halaman riwayat login sesuai filter
+func getLoginAttempts(c *gin.Context, filter repositories.LoginAttemptFilter) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+
+	result, err := sessionService.ListAttempts(c.Request.Context(), filter, page, perPage)
+	if err != nil {
+		respondError(c, err, "Gagal mengambil riwayat login")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Riwayat login", result))
+}
@@ -0,0 +1,193 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+	"golang-starter-kit/utils"
+)
+
+// login masuk lewat /api/login dengan user agent tertentu dan mengembalikan token-nya
+func login(t *testing.T, h *testutil.Harness, email, password, userAgent string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var data struct {
+		Token string `json:"token"`
+	}
+	if rec.Code == http.StatusOK {
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Login berhasil", &data)
+	}
+	return rec, data.Token
+}
+
+func TestSessionsListAndRevokeOthers(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+	_, laptop := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+	_, phone := login(t, h, user.Email, testutil.DefaultPassword, "Phone")
+
+	rec := h.Request(t, http.MethodGet, "/api/me/sessions", nil, laptop)
+	var sessions []models.Session
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar session", &sessions)
+	if len(sessions) != 2 {
+		t.Fatalf("len(sessions) = %d, want 2", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.UserAgent == "Laptop") {
+			t.Fatalf("wrong current flag: %+v", session)
+		}
+	}
+
+	rec = h.Request(t, http.MethodPost, "/api/me/sessions/revoke-others", nil, laptop)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Session lain berhasil dicabut", nil)
+
+	// Token dari session yang dicabut ditolak, session yang sedang dipakai tetap aktif
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, phone)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, laptop)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar session", &sessions)
+	if len(sessions) != 1 || !sessions[0].Current {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+}
+
+func TestRevokeSingleSession(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	other := h.CreateUser(t, testutil.UserAttrs{})
+	_, token := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+	_, otherToken := login(t, h, other.Email, testutil.DefaultPassword, "Laptop")
+
+	var otherSession models.Session
+	h.DB.Where("user_id = ?", other.ID).Order("created_at DESC").First(&otherSession)
+
+	// Session milik user lain tidak bisa dicabut
+	rec := h.Request(t, http.MethodDelete, "/api/me/sessions/"+otherSession.ID, nil, token)
+	testutil.AssertError(t, rec, http.StatusNotFound, services.ErrSessionNotFound.Message)
+
+	rec = h.Request(t, http.MethodDelete, "/api/me/sessions/"+otherSession.ID, nil, otherToken)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Session berhasil dicabut", nil)
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, otherToken)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestRevokedSessionSurvivesBlacklistClear(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	_, laptop := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+	_, phone := login(t, h, user.Email, testutil.DefaultPassword, "Phone")
+
+	rec := h.Request(t, http.MethodPost, "/api/me/sessions/revoke-others", nil, laptop)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	rec = h.Request(t, http.MethodPost, "/api/secret/clear-black-list", map[string]string{"password": "secret123"}, "")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, phone)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestSessionRevokedInDatabaseIsRejected(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	_, token := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+
+	rec := h.Request(t, http.MethodGet, "/api/me/sessions", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	// Pencabutan dari replica lain hanya tercatat di database, terlihat setelah RevocationCheckTTL
+	h.DB.Model(&models.Session{}).Where("user_id = ?", user.ID).Update("revoked_at", h.Clock.Now())
+	h.Clock.Advance(utils.RevocationCheckTTL)
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestLogoutEndsSession(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	_, first := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+	_, second := login(t, h, user.Email, testutil.DefaultPassword, "Phone")
+
+	rec := h.Request(t, http.MethodPost, "/api/logout", nil, first)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Berhasil logout", nil)
+
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, second)
+	var sessions []models.Session
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar session", &sessions)
+	if len(sessions) != 1 || sessions[0].UserAgent != "Phone" {
+		t.Fatalf("unexpected sessions after logout: %+v", sessions)
+	}
+}
+
+func TestLoginHistory(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+
+	rec, _ := login(t, h, user.Email, "salah123", "Laptop")
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	rec, _ = login(t, h, "hilang@example.com", "salah123", "Laptop")
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	_, token := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+
+	rec = h.Request(t, http.MethodGet, "/api/me/logins", nil, token)
+	var page struct {
+		Items []models.LoginAttempt `json:"items"`
+		Total int64                 `json:"total"`
+	}
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Riwayat login", &page)
+	if page.Total != 2 || !page.Items[0].Success || page.Items[0].SessionID == "" ||
+		page.Items[1].Reason != models.LoginFailedWrongPassword || page.Items[1].UserAgent != "Laptop" {
+		t.Fatalf("unexpected history: %+v", page)
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/admin/logins?success=false", nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Riwayat login", &page)
+	if page.Total != 2 || page.Items[0].Email != "hilang@example.com" || page.Items[0].UserID != nil {
+		t.Fatalf("unexpected failed logins: %+v", page)
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/admin/logins?success=kadang", nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, services.ErrInvalidLoginFilter.Message)
+
+	rec = h.Request(t, http.MethodGet, "/api/admin/logins", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+}
+
+func TestAdminManagesUserSessions(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	_, token := login(t, h, user.Email, testutil.DefaultPassword, "Laptop")
+
+	rec := h.AuthRequest(t, admin, http.MethodGet, fmt.Sprintf("/api/admin/users/%d/sessions", user.ID), nil)
+	var sessions []models.Session
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar session", &sessions)
+	if len(sessions) != 1 {
+		t.Fatalf("len(sessions) = %d, want 1", len(sessions))
+	}
+
+	rec = h.AuthRequest(t, admin, http.MethodDelete, fmt.Sprintf("/api/admin/users/%d/sessions/%s", user.ID, sessions[0].ID), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Session berhasil dicabut", nil)
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+
+	// Pencabutan session tercatat di audit log
+	var entry models.AuditLog
+	h.DB.Where("action = ?", "session.revoke").First(&entry)
+	if entry.TargetID != sessions[0].ID || entry.ActorID == nil || *entry.ActorID != admin.ID {
+		t.Fatalf("unexpected audit entry: %+v", entry)
+	}
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"      // Framework web Gin
+	"golang-starter-kit/middleware" // Identitas user yang sedang login
+	"golang-starter-kit/services"   // Aturan bisnis (SSO)
+	"golang-starter-kit/utils"      // Helper (response)
+)
+
+// SSOCallbackInput adalah parameter yang diterima halaman callback frontend dari identity provider
+type SSOCallbackInput struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// GetSSOProviders menampilkan identity provider yang bisa dipakai untuk login
+func GetSSOProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar provider SSO", ssoService.Providers()))
+}
+
+// StartSSOLogin memulai login SSO dan mengembalikan URL identity provider untuk dibuka browser
+func StartSSOLogin(c *gin.Context) {
+	start, err := ssoService.Start(c.Request.Context(), c.Param("provider"), nil)
+	if err != nil {
+		respondError(c, err, "Gagal memulai login SSO")
+		return
+	}
+
+	// Response success, frontend mengarahkan browser ke authorization_url
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Lanjutkan login di provider SSO", start))
+}
+
+// CompleteSSOLogin menukar code dari identity provider dengan token login (sama dengan Login)
+func CompleteSSOLogin(c *gin.Context) {
+	var input SSOCallbackInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	result, err := ssoService.Login(c.Request.Context(), c.Param("provider"), input.State, input.Code)
+	if err != nil {
+		respondError(c, err, "Gagal login")
+		return
+	}
+
+	// Kirim response sukses dengan data user dan token
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Login berhasil", loginData(result)))
+}
+
+// GetMyIdentities menampilkan identitas SSO yang ditautkan ke user yang sedang login
+func GetMyIdentities(c *gin.Context) {
+	identities, err := ssoService.Identities(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data identitas")
+		return
+	}
+
+	// Data berhasil di ambil
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Daftar identitas", identities))
+}
+
+// StartLinkMyIdentity memulai alur penautan identitas SSO ke user yang sedang login
+func StartLinkMyIdentity(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+	start, err := ssoService.Start(c.Request.Context(), c.Param("provider"), &userID)
+	if err != nil {
+		respondError(c, err, "Gagal memulai penautan identitas")
+		return
+	}
+
+	// Response success, frontend mengarahkan browser ke authorization_url
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Lanjutkan login di provider SSO", start))
+}
+
+// CompleteLinkMyIdentity menautkan identitas dari callback identity provider ke user yang sedang login
+func CompleteLinkMyIdentity(c *gin.Context) {
+	var input SSOCallbackInput
+
+	// Input Validation
+	ok, resp := utils.InputValidation(c, &input)
+	if !ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	identity, err := ssoService.Link(c.Request.Context(), middleware.CurrentUserID(c), c.Param("provider"), input.State, input.Code)
+	if err != nil {
+		respondError(c, err, "Gagal menautkan identitas")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Identitas berhasil ditautkan", identity))
+}
+
+// UnlinkMyIdentity melepas identitas SSO dari user yang sedang login
+func UnlinkMyIdentity(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserIdentityNotFound, "")
+		return
+	}
+
+	if err := ssoService.Unlink(c.Request.Context(), middleware.CurrentUserID(c), id); err != nil {
+		respondError(c, err, "Gagal melepas identitas")
+		return
+	}
+
+	// Response success
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("Identitas berhasil dilepas", nil))
+}
@@ -0,0 +1,220 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+	"golang-starter-kit/utils"
+)
+
+// newSSOHarness menjalankan identity provider lokal dan mendaftarkannya sebagai provider "corp".
+// env berisi pengaturan tambahan SSO_CORP_*.
+func newSSOHarness(t *testing.T, env map[string]string) (*testutil.Harness, *testutil.MockIdP) {
+	t.Helper()
+	idp := testutil.NewMockIdP(t, utils.Now)
+	t.Setenv("SSO_PROVIDERS", "corp")
+	t.Setenv("SSO_CORP_NAME", "Corporate SSO")
+	t.Setenv("SSO_CORP_ISSUER", idp.URL)
+	t.Setenv("SSO_CORP_CLIENT_ID", idp.ClientID)
+	t.Setenv("SSO_CORP_CLIENT_SECRET", idp.ClientSecret)
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return testutil.New(t), idp
+}
+
+// ssoLogin menjalankan seluruh alur login SSO sebagai identitas dengan claim tertentu
+func ssoLogin(t *testing.T, h *testutil.Harness, idp *testutil.MockIdP, claims map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var start struct {
+		AuthorizationURL string `json:"authorization_url"`
+	}
+	rec := h.Request(t, http.MethodPost, "/api/sso/corp/start", nil, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Lanjutkan login di provider SSO", &start)
+
+	code, state := idp.Login(t, start.AuthorizationURL, claims)
+	return h.Request(t, http.MethodPost, "/api/sso/corp/callback", map[string]string{"code": code, "state": state}, "")
+}
+
+// ssoLoginData adalah data response login yang dibutuhkan test
+type ssoLoginData struct {
+	Token string `json:"token"`
+	User  struct {
+		ID    uint   `json:"id"`
+		Email string `json:"email"`
+		Role  struct {
+			Name string `json:"name"`
+		} `json:"role"`
+	} `json:"user"`
+}
+
+func TestSSOLoginProvisionsUserWithMappedRole(t *testing.T) {
+	h, idp := newSSOHarness(t, map[string]string{
+		"SSO_CORP_ROLE_CLAIM": "groups",
+		"SSO_CORP_ROLE_MAP":   "it-admins:admin,staff:user",
+	})
+
+	var providers []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	testutil.AssertSuccess(t, h.Request(t, http.MethodGet, "/api/sso/providers", nil, ""), http.StatusOK, "Daftar provider SSO", &providers)
+	if len(providers) != 1 || providers[0].ID != "corp" || providers[0].Name != "Corporate SSO" {
+		t.Fatalf("providers = %+v", providers)
+	}
+
+	claims := map[string]interface{}{
+		"sub":            "corp-123",
+		"email":          "sari@corp.example",
+		"email_verified": true,
+		"name":           "Sari",
+		"groups":         []string{"staff", "it-admins"},
+	}
+	var first ssoLoginData
+	testutil.AssertSuccess(t, ssoLogin(t, h, idp, claims), http.StatusOK, "Login berhasil", &first)
+	if first.User.Email != "sari@corp.example" || first.User.Role.Name != models.AdminRoleName {
+		t.Fatalf("user = %+v", first.User)
+	}
+
+	// Token dari SSO sama dengan token login biasa
+	var identities []models.UserIdentity
+	rec := h.Request(t, http.MethodGet, "/api/me/identities", nil, first.Token)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar identitas", &identities)
+	if len(identities) != 1 || identities[0].Provider != "corp" || identities[0].Subject != "corp-123" {
+		t.Fatalf("identities = %+v", identities)
+	}
+
+	// Login berikutnya memakai user yang sama, role mengikuti grup terbaru
+	claims["groups"] = []string{"staff"}
+	var second ssoLoginData
+	testutil.AssertSuccess(t, ssoLogin(t, h, idp, claims), http.StatusOK, "Login berhasil", &second)
+	if second.User.ID != first.User.ID || second.User.Role.Name != models.UserRoleName {
+		t.Fatalf("second login user = %+v, first = %+v", second.User, first.User)
+	}
+	var users int64
+	h.DB.Model(&models.User{}).Where("email = ?", "sari@corp.example").Count(&users)
+	if users != 1 {
+		t.Fatalf("users = %d, want 1", users)
+	}
+
+	// User dari SSO tidak punya password lokal
+	rec = h.Request(t, http.MethodPost, "/api/login", map[string]string{"email": "sari@corp.example", "password": "anything"}, "")
+	testutil.AssertError(t, rec, http.StatusUnauthorized, "Email atau password salah")
+}
+
+func TestSSOCallbackRejectsInvalidState(t *testing.T) {
+	h, idp := newSSOHarness(t, nil)
+
+	var start struct {
+		AuthorizationURL string `json:"authorization_url"`
+	}
+	testutil.AssertSuccess(t, h.Request(t, http.MethodPost, "/api/sso/corp/start", nil, ""), http.StatusOK, "Lanjutkan login di provider SSO", &start)
+	code, state := idp.Login(t, start.AuthorizationURL, map[string]interface{}{"sub": "corp-1", "email": "a@corp.example"})
+
+	t.Run("state tidak dikenal", func(t *testing.T) {
+		rec := h.Request(t, http.MethodPost, "/api/sso/corp/callback", map[string]string{"code": code, "state": "tampered"}, "")
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Sesi login SSO tidak valid atau sudah kadaluwarsa")
+	})
+
+	t.Run("state hanya bisa dipakai sekali", func(t *testing.T) {
+		body := map[string]string{"code": code, "state": state}
+		testutil.AssertSuccess(t, h.Request(t, http.MethodPost, "/api/sso/corp/callback", body, ""), http.StatusOK, "Login berhasil", nil)
+		rec := h.Request(t, http.MethodPost, "/api/sso/corp/callback", body, "")
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Sesi login SSO tidak valid atau sudah kadaluwarsa")
+	})
+
+	t.Run("state kadaluwarsa", func(t *testing.T) {
+		testutil.AssertSuccess(t, h.Request(t, http.MethodPost, "/api/sso/corp/start", nil, ""), http.StatusOK, "Lanjutkan login di provider SSO", &start)
+		code, state := idp.Login(t, start.AuthorizationURL, map[string]interface{}{"sub": "corp-1"})
+		h.Clock.Advance(11 * time.Minute)
+		rec := h.Request(t, http.MethodPost, "/api/sso/corp/callback", map[string]string{"code": code, "state": state}, "")
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Sesi login SSO tidak valid atau sudah kadaluwarsa")
+	})
+
+	t.Run("provider tidak dikenal", func(t *testing.T) {
+		rec := h.Request(t, http.MethodPost, "/api/sso/other/start", nil, "")
+		testutil.AssertError(t, rec, http.StatusNotFound, "Provider SSO tidak ditemukan")
+	})
+}
+
+func TestSSOExistingEmail(t *testing.T) {
+	claims := map[string]interface{}{"sub": "corp-7", "email": "budi@example.com", "email_verified": true}
+
+	t.Run("tanpa link by email user harus menautkan sendiri", func(t *testing.T) {
+		h, idp := newSSOHarness(t, nil)
+		user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+
+		rec := ssoLogin(t, h, idp, claims)
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Email sudah terdaftar, login dengan password lalu tautkan identitas dari pengaturan akun")
+
+		// Tautkan dari pengaturan akun, setelah itu login SSO masuk ke user yang sama
+		var start struct {
+			AuthorizationURL string `json:"authorization_url"`
+		}
+		rec = h.AuthRequest(t, user, http.MethodPost, "/api/me/identities/corp/start", nil)
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Lanjutkan login di provider SSO", &start)
+		code, state := idp.Login(t, start.AuthorizationURL, claims)
+
+		// State penautan tidak bisa dipakai untuk login
+		rec = h.Request(t, http.MethodPost, "/api/sso/corp/callback", map[string]string{"code": code, "state": state}, "")
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Sesi login SSO tidak valid atau sudah kadaluwarsa")
+
+		rec = h.AuthRequest(t, user, http.MethodPost, "/api/me/identities/corp/start", nil)
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Lanjutkan login di provider SSO", &start)
+		code, state = idp.Login(t, start.AuthorizationURL, claims)
+		rec = h.AuthRequest(t, user, http.MethodPost, "/api/me/identities/corp/callback", map[string]string{"code": code, "state": state})
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Identitas berhasil ditautkan", nil)
+
+		var data ssoLoginData
+		testutil.AssertSuccess(t, ssoLogin(t, h, idp, claims), http.StatusOK, "Login berhasil", &data)
+		if data.User.ID != user.ID {
+			t.Fatalf("logged in as %d, want %d", data.User.ID, user.ID)
+		}
+	})
+
+	t.Run("link by email hanya untuk email terverifikasi", func(t *testing.T) {
+		h, idp := newSSOHarness(t, map[string]string{"SSO_CORP_LINK_BY_EMAIL": "true"})
+		user := h.CreateUser(t, testutil.UserAttrs{Email: "budi@example.com"})
+
+		unverified := map[string]interface{}{"sub": "corp-8", "email": "budi@example.com"}
+		testutil.AssertStatus(t, ssoLogin(t, h, idp, unverified), http.StatusBadRequest)
+
+		var data ssoLoginData
+		testutil.AssertSuccess(t, ssoLogin(t, h, idp, claims), http.StatusOK, "Login berhasil", &data)
+		if data.User.ID != user.ID {
+			t.Fatalf("logged in as %d, want %d", data.User.ID, user.ID)
+		}
+	})
+}
+
+func TestSSOWithoutProvisioning(t *testing.T) {
+	h, idp := newSSOHarness(t, map[string]string{"SSO_CORP_PROVISION": "false"})
+
+	rec := ssoLogin(t, h, idp, map[string]interface{}{"sub": "corp-9", "email": "new@corp.example"})
+	testutil.AssertError(t, rec, http.StatusForbidden, "Identitas ini belum terhubung ke akun mana pun")
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	h, idp := newSSOHarness(t, nil)
+
+	var data ssoLoginData
+	claims := map[string]interface{}{"sub": "corp-5", "email": "rina@corp.example"}
+	testutil.AssertSuccess(t, ssoLogin(t, h, idp, claims), http.StatusOK, "Login berhasil", &data)
+
+	var identities []models.UserIdentity
+	testutil.AssertSuccess(t, h.Request(t, http.MethodGet, "/api/me/identities", nil, data.Token), http.StatusOK, "Daftar identitas", &identities)
+	path := "/api/me/identities/" + strconv.Itoa(int(identities[0].ID))
+
+	// User tanpa password tidak boleh kehilangan satu-satunya cara login
+	rec := h.Request(t, http.MethodDelete, path, nil, data.Token)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Identitas terakhir tidak dapat dilepas karena akun tidak memiliki password")
+
+	other := h.CreateUser(t, testutil.UserAttrs{})
+	rec = h.AuthRequest(t, other, http.MethodDelete, path, nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "Identitas tidak ditemukan")
+}
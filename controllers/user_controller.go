@@ -2,20 +2,23 @@ package controllers
 
 import (
 	"net/http"
-	"time"
-	"github.com/gin-gonic/gin"   // Framework web Gin
-	"golang.org/x/crypto/bcrypt" // Untuk hashing password
-	"golang-starter-kit/models"  // Model database
-	"golang-starter-kit/utils"	 // Helper (response, jwt)
+
+	"github.com/gin-gonic/gin"      // Framework web Gin
+	"golang-starter-kit/middleware" // User yang sedang login
+	"golang-starter-kit/services"   // Aturan bisnis (user)
+	"golang-starter-kit/utils"      // Helper (response)
 )
 
-// GetUsers menampilkan semua user
+// GetUsers menampilkan semua user, admin bisa menambahkan ?with_deleted atau ?only_deleted
 func GetUsers(c *gin.Context) {
-	var users []models.User
+	scope, ok := deletedScope(c)
+	if !ok {
+		return
+	}
 
-	// Mengambil semua role yang belum dihapus (deleted_at IS NULL)
-	if err := models.DB.Where("deleted_at IS NULL").Find(&users).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal mengambil data user", nil))
+	users, err := userService.List(c.Request.Context(), scope)
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data user")
 		return
 	}
 
@@ -26,16 +29,14 @@ func GetUsers(c *gin.Context) {
 type CreateUserInput struct {
 	Name     string `json:"name" binding:"required,min=3"`
 	Email    string `json:"email" binding:"required,email,min=6"`
-	Password string `json:"password" binding:"required,min=6"`
-	IDRole   int    `json:"id_role" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	IDRole   int    `json:"id_role"` // Hanya dipakai jika yang membuat adalah admin
 }
 
 // CreateUser membuat user baru
 func CreateUser(c *gin.Context) {
-	var user models.User
 	var input CreateUserInput
 
-	// ------ Validasi ------ //
 	// Input Validation
 	ok, resp := utils.InputValidation(c, &input)
 	if !ok {
@@ -43,58 +44,32 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
-	// Validasi format password (hanya a-z, A-Z, 0-9, @, #, $)
-	if !utils.InputValidationPasswordCriteria(input.Password) {
-		c.JSON(http.StatusBadRequest, utils.APIResponseError(
-			"Password hanya boleh berisi huruf, angka, dan karakter @, #, $", nil))
-		return
-	}
-
-	// Cek apakah email sudah terdaftar
-	if err := models.DB.Where("email = ?", input.Email).First(&user).Error; err == nil {
-		c.JSON(http.StatusBadRequest, utils.APIResponseError("Email sudah terdaftar", nil))
-		return
-	}
-
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	user, err := userService.Create(c.Request.Context(), services.CreateUserParams{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: input.Password,
+		IDRole:   assignableRoleID(c, uint(input.IDRole)),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal mengenkripsi password", nil))
-		return
-	}
-	// ------ END Validasi ------ //
-
-	// Buat object user baru dengan data dari input
-	user = models.User{
-		Name:      input.Name,
-		Email:     input.Email,
-		Password:  string(hashedPassword),
-		IDRole:    uint(input.IDRole),
-		CreatedAt: time.Now(),
-	}
-
-	// Kondisi Create
-	if err := models.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal membuat user", nil))
+		respondError(c, err, "Gagal membuat user")
 		return
 	}
 
-	// Ambil user beserta role-nya
-	models.DB.Preload("Role").First(&user, user.ID)
-
 	// Response success
 	c.JSON(http.StatusOK, utils.APIResponseSuccess("User berhasil dibuat", user))
 }
 
 // GetUserByID menampilkan detail user berdasarkan ID
 func GetUserByID(c *gin.Context) {
-	id := c.Param("id")
-
-	var user models.User
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserNotFound, "")
+		return
+	}
 
-	// Kondisi data ada atau tidak
-	if err := models.DB.Where("deleted_at IS NULL").First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.APIResponseError("User tidak ditemukan", nil))
+	user, err := userService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Gagal mengambil data user")
 		return
 	}
 
@@ -104,20 +79,22 @@ func GetUserByID(c *gin.Context) {
 
 type UpdateUserInput struct {
 	Name     *string `json:"name" binding:"omitempty,min=3"`
-	Email    *string `json:"email" binding:"omitempty,email,min=6"`
-	Password *string `json:"password" binding:"omitempty,min=6"`
-	IDRole   *int    `json:"id_role"`
+	Email    *string `json:"email" binding:"omitempty,email,min=6"` // Hanya admin
+	Password *string `json:"password"`                              // Hanya admin
+	IDRole   *int    `json:"id_role"`                               // Hanya dipakai jika yang mengubah adalah admin
 }
 
-// UpdateUser mengubah data user
+// UpdateUser mengubah data user. Selain admin hanya boleh mengubah nama miliknya sendiri,
+// email dan password diubah lewat /api/me/email dan /api/me/password yang meminta konfirmasi.
 func UpdateUser(c *gin.Context) {
-	
-	id := c.Param("id")
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserNotFound, "")
+		return
+	}
 
-	var user models.User
 	var input UpdateUserInput
-	
-	// ------ Validasi Input JSON ------ //
+
 	// Validation Input
 	ok, resp := utils.InputValidation(c, &input)
 	if !ok {
@@ -125,83 +102,86 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	// Validasi format password (hanya a-z, A-Z, 0-9, @, #, $)
-	if !utils.InputValidationPasswordCriteria(*input.Password) {
-		c.JSON(http.StatusBadRequest, utils.APIResponseError(
-			"Password hanya boleh berisi huruf, angka, dan karakter @, #, $", nil))
-		return
-	}
-
-	// Cek apakah email sudah terdaftar
-	if input.Email != nil && *input.Email != "" {
-		var existing models.User
-		if err := models.DB.Where("email = ? AND id != ?", *input.Email, id).First(&existing).Error; err == nil {
-			c.JSON(http.StatusBadRequest, utils.APIResponseError("Email sudah terdaftar", nil))
+	if !middleware.IsAdmin(c) {
+		if id != middleware.CurrentUserID(c) {
+			respondError(c, services.ErrUpdateOtherUser, "")
 			return
 		}
-	}
-
-	// Check User
-	if err := models.DB.Where("deleted_at IS NULL").First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.APIResponseError("User tidak ditemukan", nil))
-		return
-	}
-	// ------ END Validasi Input JSON ------ //
-
-	// Buat object user baru dengan data dari input
-	if input.Name != nil && *input.Name != "" {
-		user.Name = *input.Name
-	}
-	if input.Email != nil && *input.Email != "" {
-		user.Email = *input.Email
-	}
-	if input.Password != nil && *input.Password != "" {
-		// Hash password
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*input.Password), bcrypt.DefaultCost)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal mengenkripsi password", nil))
+		if input.Email != nil || input.Password != nil {
+			respondError(c, services.ErrUseAccountEndpoints, "")
 			return
 		}
-		user.Password = string(hashedPassword)
+	}
+
+	params := services.UpdateUserParams{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: input.Password,
 	}
 	if input.IDRole != nil {
-		user.IDRole = uint(*input.IDRole)
+		if idRole := assignableRoleID(c, uint(*input.IDRole)); idRole != 0 {
+			params.IDRole = &idRole
+		}
 	}
 
-	// Kondisi Save
-	if err := models.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal mengupdate user", nil))
+	user, err := userService.Update(c.Request.Context(), id, params)
+	if err != nil {
+		respondError(c, err, "Gagal mengupdate user")
 		return
 	}
 
-	// Ambil user beserta role-nya
-	models.DB.Preload("Role").First(&user, user.ID)
-
 	// Response success
 	c.JSON(http.StatusOK, utils.APIResponseSuccess("User berhasil diupdate", user))
 }
 
 // Delete User (Soft Delete)
 func DeleteUser(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserNotFound, "")
+		return
+	}
 
-	var user models.User
+	if err := userService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err, "Gagal menghapus user")
+		return
+	}
 
-	// Check users
-	if err := models.DB.Where("deleted_at IS NULL").First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.APIResponseError("User tidak ditemukan", nil))
+	// Berhasil di delete
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("User berhasil dihapus", nil))
+}
+
+// RestoreUser mengembalikan user yang sudah di-soft delete
+func RestoreUser(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserNotFound, "")
+		return
+	}
+
+	user, err := userService.Restore(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Gagal mengembalikan user")
 		return
 	}
 
-	// Field model
-	now := time.Now()
+	// Berhasil di restore
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("User berhasil dikembalikan", user))
+}
 
-	// Kondisi check update deleted_at
-	if err := models.DB.Model(&user).Update("deleted_at", &now).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.APIResponseError("Gagal menghapus user", nil))
+// PurgeUser menghapus permanen user yang sudah di-soft delete
+func PurgeUser(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		respondError(c, services.ErrUserNotFound, "")
 		return
 	}
 
-	// Berhasil di delete
-	c.JSON(http.StatusOK, utils.APIResponseSuccess("User berhasil dihapus", nil))
-}
\ No newline at end of file
+	if err := userService.Purge(c.Request.Context(), id); err != nil {
+		respondError(c, err, "Gagal menghapus permanen user")
+		return
+	}
+
+	// Berhasil di hapus permanen
+	c.JSON(http.StatusOK, utils.APIResponseSuccess("User berhasil dihapus permanen", nil))
+}
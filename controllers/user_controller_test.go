@@ -0,0 +1,298 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+)
+
+func TestUserCRUD(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+
+	// Create
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/user/", map[string]interface{}{
+		"name":     "Siti",
+		"email":    "siti@example.com",
+		"password": "rahasia123",
+		"id_role":  admin.IDRole,
+	})
+	var created models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dibuat", &created)
+	if created.Role.ID != admin.IDRole {
+		t.Fatalf("role not preloaded: %+v", created.Role)
+	}
+
+	// List
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/user/", nil)
+	var users []models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar user", &users)
+	if len(users) != 2 {
+		t.Fatalf("len(users) = %d, want 2", len(users))
+	}
+
+	// Detail
+	path := fmt.Sprintf("/api/user/%d", created.ID)
+	rec = h.AuthRequest(t, admin, http.MethodGet, path, nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Detail user", nil)
+
+	// Update tanpa field password
+	rec = h.AuthRequest(t, admin, http.MethodPut, path, map[string]interface{}{"name": "Siti Aminah"})
+	var updated models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil diupdate", &updated)
+	if updated.Name != "Siti Aminah" {
+		t.Fatalf("name = %q, want %q", updated.Name, "Siti Aminah")
+	}
+
+	// Delete
+	rec = h.AuthRequest(t, admin, http.MethodDelete, path, nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dihapus", nil)
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, path, nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "User tidak ditemukan")
+}
+
+func TestUpdateUserEmailTaken(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	other := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, admin, http.MethodPut, fmt.Sprintf("/api/user/%d", admin.ID), map[string]interface{}{
+		"email": other.Email,
+	})
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Email sudah terdaftar")
+}
+
+func TestCreateUserInvalidPassword(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, admin, http.MethodPost, "/api/user/", map[string]interface{}{
+		"name":     "Siti",
+		"email":    "siti@example.com",
+		"password": "siti",
+		"id_role":  admin.IDRole,
+	})
+	resp := testutil.AssertError(t, rec, http.StatusBadRequest, "Password tidak memenuhi kebijakan")
+
+	// Semua aturan yang dilanggar dikembalikan sekaligus
+	var violations []services.PasswordViolation
+	if err := json.Unmarshal(resp.Data, &violations); err != nil {
+		t.Fatal(err)
+	}
+	rules := map[string]bool{}
+	for _, violation := range violations {
+		rules[violation.Rule] = true
+	}
+	if len(violations) != 2 || !rules[services.PasswordRuleMinLength] || !rules[services.PasswordRulePersonal] {
+		t.Fatalf("unexpected violations: %+v", violations)
+	}
+
+	// Passphrase dengan spasi dan simbol lain diterima
+	rec = h.AuthRequest(t, admin, http.MethodPost, "/api/user/", map[string]interface{}{
+		"name":     "Siti",
+		"email":    "siti@example.com",
+		"password": "kuda lari di pantai senja!",
+		"id_role":  admin.IDRole,
+	})
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dibuat", nil)
+}
+
+func TestGetUserNotFound(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, admin, http.MethodGet, "/api/user/999", nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "User tidak ditemukan")
+
+	rec = h.AuthRequest(t, admin, http.MethodGet, "/api/user/abc", nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "User tidak ditemukan")
+}
+
+func TestRegisterWithDeletedEmail(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	deleted := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, admin, http.MethodDelete, fmt.Sprintf("/api/user/%d", deleted.ID), nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dihapus", nil)
+
+	rec = h.Request(t, http.MethodPost, "/api/register", map[string]interface{}{
+		"name":     "Pengguna Baru",
+		"email":    deleted.Email,
+		"password": "rahasia123",
+		"id_role":  deleted.IDRole,
+	}, "")
+	testutil.AssertSuccess(t, rec, http.StatusOK, "Registrasi berhasil", nil)
+
+	// User lama tidak bisa dikembalikan karena email-nya sudah dipakai lagi
+	rec = h.AuthRequest(t, admin, http.MethodPost, fmt.Sprintf("/api/user/%d/restore", deleted.ID), nil)
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Email sudah terdaftar")
+}
+
+func TestListDeletedUsers(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+	deleted := h.CreateUser(t, testutil.UserAttrs{})
+	h.AuthRequest(t, admin, http.MethodDelete, fmt.Sprintf("/api/user/%d", deleted.ID), nil)
+
+	cases := []struct {
+		query string
+		want  int
+	}{
+		{"", 2},
+		{"?with_deleted", 3},
+		{"?with_deleted=true", 3},
+		{"?only_deleted=1", 1},
+	}
+	for _, tc := range cases {
+		rec := h.AuthRequest(t, admin, http.MethodGet, "/api/user/"+tc.query, nil)
+		var users []models.User
+		testutil.AssertSuccess(t, rec, http.StatusOK, "Daftar user", &users)
+		if len(users) != tc.want {
+			t.Fatalf("%q: len(users) = %d, want %d", tc.query, len(users), tc.want)
+		}
+	}
+
+	// Selain admin tidak boleh melihat data yang sudah dihapus
+	rec := h.AuthRequest(t, member, http.MethodGet, "/api/user/?with_deleted", nil)
+	testutil.AssertError(t, rec, http.StatusForbidden, "Hanya admin yang dapat melihat data yang sudah dihapus")
+}
+
+func TestRestoreAndPurgeUser(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+	target := h.CreateUser(t, testutil.UserAttrs{})
+	path := fmt.Sprintf("/api/user/%d", target.ID)
+
+	// Restore dan purge hanya untuk admin
+	rec := h.AuthRequest(t, member, http.MethodPost, path+"/restore", nil)
+	testutil.AssertStatus(t, rec, http.StatusForbidden)
+
+	// User yang belum dihapus tidak bisa di-restore atau di-purge
+	rec = h.AuthRequest(t, admin, http.MethodPost, path+"/restore", nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "User tidak ditemukan")
+	rec = h.AuthRequest(t, admin, http.MethodDelete, path+"/purge", nil)
+	testutil.AssertError(t, rec, http.StatusNotFound, "User tidak ditemukan")
+
+	h.AuthRequest(t, admin, http.MethodDelete, path, nil)
+	rec = h.AuthRequest(t, admin, http.MethodPost, path+"/restore", nil)
+	var restored models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dikembalikan", &restored)
+	if restored.DeletedAt.Valid {
+		t.Fatalf("restored user still deleted: %+v", restored.DeletedAt)
+	}
+
+	h.AuthRequest(t, admin, http.MethodDelete, path, nil)
+	rec = h.AuthRequest(t, admin, http.MethodDelete, path+"/purge", nil)
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dihapus permanen", nil)
+
+	var count int64
+	h.DB.Unscoped().Model(&models.User{}).Where("id = ?", target.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("purged user still exists")
+	}
+}
+
+func TestCreateUserWithUnknownRole(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	deletedRole := h.CreateRole(t, testutil.RoleAttrs{})
+	h.DB.Delete(&deletedRole)
+
+	for _, idRole := range []uint{999, deletedRole.ID} {
+		rec := h.AuthRequest(t, admin, http.MethodPost, "/api/user/", map[string]interface{}{
+			"name":     "Siti",
+			"email":    "siti@example.com",
+			"password": "rahasia123",
+			"id_role":  idRole,
+		})
+		testutil.AssertError(t, rec, http.StatusBadRequest, "Role tidak ditemukan")
+	}
+
+	rec := h.AuthRequest(t, admin, http.MethodPut, fmt.Sprintf("/api/user/%d", admin.ID), map[string]interface{}{
+		"id_role": 999,
+	})
+	testutil.AssertError(t, rec, http.StatusBadRequest, "Role tidak ditemukan")
+}
+
+func TestNonAdminCannotAssignRole(t *testing.T) {
+	h := testutil.New(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+	adminRole := h.SystemRole(t, models.AdminRoleName)
+	defaultRole := h.SystemRole(t, models.UserRoleName)
+
+	rec := h.AuthRequest(t, member, http.MethodPost, "/api/user/", map[string]interface{}{
+		"name":     "Siti",
+		"email":    "siti@example.com",
+		"password": "rahasia123",
+		"id_role":  adminRole.ID,
+	})
+	var created models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil dibuat", &created)
+	if created.IDRole != defaultRole.ID {
+		t.Fatalf("id_role = %d, want default role %d", created.IDRole, defaultRole.ID)
+	}
+
+	rec = h.AuthRequest(t, member, http.MethodPut, fmt.Sprintf("/api/user/%d", member.ID), map[string]interface{}{
+		"id_role": adminRole.ID,
+	})
+	var updated models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil diupdate", &updated)
+	if updated.IDRole != member.IDRole {
+		t.Fatalf("id_role changed to %d by non-admin", updated.IDRole)
+	}
+}
+
+func TestNonAdminCanOnlyRenameThemself(t *testing.T) {
+	h := testutil.New(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+	other := h.CreateUser(t, testutil.UserAttrs{})
+
+	rec := h.AuthRequest(t, member, http.MethodPut, fmt.Sprintf("/api/user/%d", other.ID), map[string]interface{}{
+		"name": "Diambil Alih",
+	})
+	testutil.AssertError(t, rec, http.StatusForbidden, "Hanya admin yang dapat mengubah data user lain")
+
+	// Email dan password sendiri hanya bisa diubah lewat /api/me yang meminta konfirmasi
+	for _, body := range []map[string]interface{}{
+		{"email": "baru@example.com"},
+		{"password": "Password-Baru-2024"},
+	} {
+		rec = h.AuthRequest(t, member, http.MethodPut, fmt.Sprintf("/api/user/%d", member.ID), body)
+		testutil.AssertError(t, rec, http.StatusForbidden, "Ubah email dan password sendiri melalui /api/me/email dan /api/me/password")
+	}
+
+	rec = h.AuthRequest(t, member, http.MethodPut, fmt.Sprintf("/api/user/%d", member.ID), map[string]interface{}{
+		"name": "Nama Baru",
+	})
+	var updated models.User
+	testutil.AssertSuccess(t, rec, http.StatusOK, "User berhasil diupdate", &updated)
+	if updated.Name != "Nama Baru" || updated.Email != member.Email {
+		t.Fatalf("unexpected user: %+v", updated)
+	}
+}
+
+func TestAdminPasswordResetRevokesSessions(t *testing.T) {
+	h := testutil.New(t)
+	admin := h.CreateAdmin(t)
+	member := h.CreateUser(t, testutil.UserAttrs{})
+	_, token := login(t, h, member.Email, testutil.DefaultPassword, "Firefox")
+
+	rec := h.AuthRequest(t, admin, http.MethodPut, fmt.Sprintf("/api/user/%d", member.ID), map[string]interface{}{
+		"password": "Password-Baru-2024",
+	})
+	testutil.AssertStatus(t, rec, http.StatusOK)
+
+	rec = h.Request(t, http.MethodGet, "/api/me/sessions", nil, token)
+	testutil.AssertStatus(t, rec, http.StatusUnauthorized)
+	rec, _ = login(t, h, member.Email, "Password-Baru-2024", "Firefox")
+	testutil.AssertStatus(t, rec, http.StatusOK)
+}
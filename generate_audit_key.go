@@ -0,0 +1,23 @@
+//go:build ignore
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+func main() {
+	// Generate pasangan key Ed25519 untuk tanda tangan checkpoint audit log
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		fmt.Println("Gagal generate key:", err)
+		return
+	}
+
+	// Yang disimpan di .env hanya seed 32 byte, public key dibagikan ke pihak yang memverifikasi arsip
+	fmt.Printf("AUDIT_SIGNING_KEY=%s\n", base64.StdEncoding.EncodeToString(privateKey.Seed()))
+	fmt.Printf("AUDIT_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(publicKey))
+}
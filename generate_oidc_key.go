@@ -0,0 +1,28 @@
+//go:build ignore
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+)
+
+func main() {
+	// Generate private key RSA untuk tanda tangan ID token OpenID Connect (RS256)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		fmt.Println("Gagal generate key:", err)
+		return
+	}
+
+	// Public key tidak perlu disimpan, relying party mengambilnya dari /oauth/jwks
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		fmt.Println("Gagal encode key:", err)
+		return
+	}
+	fmt.Printf("OIDC_SIGNING_KEY=%s\n", base64.StdEncoding.EncodeToString(der))
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/mailer"
+	"gorm.io/gorm"
+)
+
+// DefaultRegistry adalah Registry yang dipakai oleh worker aplikasi.
+// Package lain mendaftarkan handler-nya di sini sebelum worker dijalankan.
+var DefaultRegistry = NewRegistry()
+
+// InitJobs mendaftarkan handler bawaan dan membuat worker dari environment variable.
+// Dipanggil setelah mailer.InitMailer karena handler email memakai mailer default saat ini;
+// jika MAIL_VIA_JOBS=true, mailer default diganti sehingga email dikirim lewat antrian job.
+func InitJobs(db *gorm.DB) *Worker {
+	RegisterMailHandler(DefaultRegistry, mailer.Default())
+	if config.GetEnvBool("MAIL_VIA_JOBS", false) {
+		mailer.SetDefault(NewMailer(db))
+	}
+
+	return NewWorker(db, DefaultRegistry, WorkerConfig{
+		Concurrency:  config.GetEnvInt("JOBS_WORKERS", 2),
+		PollInterval: time.Duration(config.GetEnvInt("JOBS_POLL_SECONDS", 1)) * time.Second,
+		Backoff:      time.Duration(config.GetEnvInt("JOBS_BACKOFF_SECONDS", 10)) * time.Second,
+		MaxBackoff:   time.Duration(config.GetEnvInt("JOBS_MAX_BACKOFF_SECONDS", 3600)) * time.Second,
+		LockTimeout:  time.Duration(config.GetEnvInt("JOBS_LOCK_TIMEOUT_SECONDS", 900)) * time.Second,
+	})
+}
@@ -0,0 +1,101 @@
+// Package jobs adalah antrian job yang tahan restart, disimpan di tabel jobs.
+// Worker mengambil job dengan SELECT ... FOR UPDATE SKIP LOCKED sehingga beberapa
+// worker (atau beberapa replika aplikasi) bisa berjalan bersamaan tanpa mengambil job yang sama.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/utils"
+	"gorm.io/gorm"
+)
+
+// DefaultMaxAttempts adalah batas percobaan jika tidak diatur lewat WithMaxAttempts
+const DefaultMaxAttempts = 5
+
+// Handler memproses satu job. Error membuat job dicoba ulang atau masuk dead letter.
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Registry menyimpan handler berdasarkan tipe job
+type Registry struct {
+	handlers map[string]Handler
+}
+
+// NewRegistry membuat Registry kosong
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// Handle mendaftarkan handler mentah untuk tipe job
+func (r *Registry) Handle(jobType string, handler Handler) {
+	r.handlers[jobType] = handler
+}
+
+// Register mendaftarkan handler bertipe: payload JSON di-decode ke T sebelum fn dipanggil
+func Register[T any](r *Registry, jobType string, fn func(ctx context.Context, payload T) error) {
+	r.Handle(jobType, func(ctx context.Context, job *models.Job) error {
+		var payload T
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return fmt.Errorf("decode payload %s: %w", jobType, err)
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// handler mengambil handler untuk tipe job
+func (r *Registry) handler(jobType string) (Handler, bool) {
+	handler, ok := r.handlers[jobType]
+	return handler, ok
+}
+
+// Option mengatur job saat Enqueue
+type Option func(job *models.Job)
+
+// WithRunAt menjadwalkan job agar baru dijalankan pada waktu tertentu
+func WithRunAt(runAt time.Time) Option {
+	return func(job *models.Job) {
+		job.RunAt = runAt
+	}
+}
+
+// WithMaxAttempts mengatur batas percobaan sebelum job masuk dead letter
+func WithMaxAttempts(n int) Option {
+	return func(job *models.Job) {
+		job.MaxAttempts = n
+	}
+}
+
+// WithSensitivePayload menandai payload berisi data rahasia (contoh: link atau kode login di email)
+// sehingga dihapus dari tabel jobs begitu job selesai atau masuk dead letter
+func WithSensitivePayload() Option {
+	return func(job *models.Job) {
+		job.Sensitive = true
+	}
+}
+
+// Enqueue menyimpan job baru dengan payload yang di-encode sebagai JSON
+func Enqueue(ctx context.Context, db *gorm.DB, jobType string, payload interface{}, opts ...Option) (*models.Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{
+		Type:        jobType,
+		Payload:     string(raw),
+		Status:      models.JobPending,
+		RunAt:       utils.Now(),
+		MaxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+
+	"golang-starter-kit/mailer"
+	"gorm.io/gorm"
+)
+
+// MailJobType adalah tipe job untuk mengirim email
+const MailJobType = "mail.send"
+
+// Mailer adalah mailer.Mailer yang tidak langsung mengirim email, tetapi menyimpannya
+// sebagai job sehingga email tidak hilang jika aplikasi restart atau SMTP sedang down
+type Mailer struct {
+	db *gorm.DB
+}
+
+// NewMailer membuat Mailer berbasis antrian job
+func NewMailer(db *gorm.DB) *Mailer {
+	return &Mailer{db: db}
+}
+
+// Send menyimpan email sebagai job. Isi email bisa berisi link atau kode login sehingga
+// payload-nya ditandai rahasia dan dihapus setelah terkirim atau gagal permanen.
+func (m *Mailer) Send(ctx context.Context, msg mailer.Message) error {
+	_, err := Enqueue(ctx, m.db, MailJobType, msg, WithSensitivePayload())
+	return err
+}
+
+// RegisterMailHandler mendaftarkan handler yang mengirim job email lewat mailer m
+func RegisterMailHandler(r *Registry, m mailer.Mailer) {
+	Register(r, MailJobType, func(ctx context.Context, msg mailer.Message) error {
+		return m.Send(ctx, msg)
+	})
+}
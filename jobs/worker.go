@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkerConfig adalah pengaturan Worker
+type WorkerConfig struct {
+	Concurrency  int           // Jumlah job yang diproses bersamaan
+	PollInterval time.Duration // Jeda mengecek tabel jobs ketika antrian kosong
+	Backoff      time.Duration // Jeda retry pertama, berlipat dua setiap percobaan
+	MaxBackoff   time.Duration // Batas atas jeda retry
+	LockTimeout  time.Duration // Job "running" lebih lama dari ini dianggap ditinggal worker yang mati
+}
+
+// Worker mengambil job dari database dan menjalankan handler-nya
+type Worker struct {
+	db       *gorm.DB
+	registry *Registry
+	config   WorkerConfig
+	id       string
+}
+
+// NewWorker membuat Worker, nilai config yang kosong diisi default
+func NewWorker(db *gorm.DB, registry *Registry, config WorkerConfig) *Worker {
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 10 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Hour
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = 15 * time.Minute
+	}
+
+	hostname, _ := os.Hostname()
+	return &Worker{
+		db:       db,
+		registry: registry,
+		config:   config,
+		id:       fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), utils.NewID()[:8]),
+	}
+}
+
+// Start menjalankan worker sampai ctx dibatalkan
+func (w *Worker) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop memproses job terus-menerus, menunggu PollInterval saat antrian kosong
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		processed, err := w.RunOnce(ctx)
+		if err != nil {
+			log.Println("[jobs] gagal mengambil job:", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.config.PollInterval):
+		}
+	}
+}
+
+// RunOnce mengambil dan memproses satu job. Mengembalikan false jika tidak ada job yang siap.
+func (w *Worker) RunOnce(ctx context.Context) (bool, error) {
+	job, err := w.claim(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	runErr := w.run(ctx, job)
+	return true, w.finish(ctx, job, runErr)
+}
+
+// claim mengunci satu job yang siap dijalankan dan menandainya "running"
+func (w *Worker) claim(ctx context.Context) (*models.Job, error) {
+	var job models.Job
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := utils.Now()
+		query := tx.
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				models.JobPending, now, models.JobRunning, now.Add(-w.config.LockTimeout)).
+			Order("run_at, id")
+
+		// SQLite (dipakai saat test) tidak mendukung FOR UPDATE, transaksinya sudah serial
+		if tx.Dialector.Name() != "sqlite" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.First(&job).Error; err != nil {
+			return err
+		}
+
+		job.Status = models.JobRunning
+		job.Attempts++
+		job.LockedBy = w.id
+		job.LockedAt = &now
+		return tx.Save(&job).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// run menjalankan handler job, panic diubah menjadi error agar worker tetap hidup
+func (w *Worker) run(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := w.registry.handler(job.Type)
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// finish menyimpan hasil job: selesai, dijadwalkan ulang dengan backoff, atau dead letter.
+// Hasil hanya disimpan selama job masih dikunci worker ini. Jika handler berjalan lebih lama dari
+// LockTimeout dan job sudah diambil worker lain, hasilnya dibuang agar tidak menimpa job tersebut.
+func (w *Worker) finish(ctx context.Context, job *models.Job, runErr error) error {
+	now := utils.Now()
+	job.LockedBy = ""
+	job.LockedAt = nil
+
+	switch {
+	case runErr == nil:
+		job.Status = models.JobSucceeded
+		job.CompletedAt = &now
+		job.LastError = ""
+	case job.Attempts >= job.MaxAttempts:
+		job.Status = models.JobDead
+		job.LastError = runErr.Error()
+		log.Printf("[jobs] job #%d (%s) masuk dead letter: %v", job.ID, job.Type, runErr)
+	default:
+		job.Status = models.JobPending
+		job.RunAt = now.Add(w.backoff(job.Attempts))
+		job.LastError = runErr.Error()
+	}
+	if job.Sensitive && job.Status != models.JobPending {
+		job.Payload = ""
+	}
+
+	result := w.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND locked_by = ?", job.ID, w.id).
+		Updates(map[string]interface{}{
+			"status":       job.Status,
+			"payload":      job.Payload,
+			"run_at":       job.RunAt,
+			"last_error":   job.LastError,
+			"locked_by":    job.LockedBy,
+			"locked_at":    job.LockedAt,
+			"completed_at": job.CompletedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("[jobs] job #%d (%s) sudah diambil worker lain, hasil percobaan ke-%d dibuang", job.ID, job.Type, job.Attempts)
+	}
+	return nil
+}
+
+// backoff menghitung jeda sebelum percobaan berikutnya
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.config.Backoff
+	for i := 1; i < attempts && delay < w.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.config.MaxBackoff {
+		delay = w.config.MaxBackoff
+	}
+	return delay
+}
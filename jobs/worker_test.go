@@ -0,0 +1,233 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/jobs"
+	"golang-starter-kit/mailer"
+	"golang-starter-kit/models"
+	"golang-starter-kit/testutil"
+)
+
+type greetPayload struct {
+	Name string `json:"name"`
+}
+
+// runOnce memproses satu job dan gagal jika tidak ada job yang siap
+func runOnce(t *testing.T, worker *jobs.Worker) {
+	t.Helper()
+	processed, err := worker.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !processed {
+		t.Fatal("expected a job to be processed")
+	}
+}
+
+func reload(t *testing.T, h *testutil.Harness, id uint) models.Job {
+	t.Helper()
+	var job models.Job
+	if err := h.DB.First(&job, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestWorkerRunsTypedHandler(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	var got []string
+	jobs.Register(registry, "greet", func(ctx context.Context, payload greetPayload) error {
+		got = append(got, payload.Name)
+		return nil
+	})
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{})
+
+	job, err := jobs.Enqueue(context.Background(), h.DB, "greet", greetPayload{Name: "Budi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runOnce(t, worker)
+
+	if len(got) != 1 || got[0] != "Budi" {
+		t.Fatalf("handler got %v", got)
+	}
+	job2 := reload(t, h, job.ID)
+	if job2.Status != models.JobSucceeded || job2.Attempts != 1 || job2.CompletedAt == nil {
+		t.Fatalf("unexpected job: %+v", job2)
+	}
+
+	// Antrian kosong
+	if processed, _ := worker.RunOnce(context.Background()); processed {
+		t.Fatal("queue should be empty")
+	}
+}
+
+func TestWorkerRespectsRunAt(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, "greet", func(ctx context.Context, payload greetPayload) error { return nil })
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{})
+
+	_, err := jobs.Enqueue(context.Background(), h.DB, "greet", greetPayload{}, jobs.WithRunAt(h.Clock.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed, _ := worker.RunOnce(context.Background()); processed {
+		t.Fatal("scheduled job should not run early")
+	}
+
+	h.Clock.Advance(time.Hour)
+	runOnce(t, worker)
+}
+
+func TestWorkerRetriesWithBackoffThenDeadLetters(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, "flaky", func(ctx context.Context, payload greetPayload) error {
+		return errors.New("boom")
+	})
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{Backoff: time.Minute, MaxBackoff: 3 * time.Minute})
+
+	job, _ := jobs.Enqueue(context.Background(), h.DB, "flaky", greetPayload{}, jobs.WithMaxAttempts(4))
+
+	// Jeda retry: 1m, 2m, lalu dibatasi 3m
+	for _, delay := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		runOnce(t, worker)
+		current := reload(t, h, job.ID)
+		if current.Status != models.JobPending || current.LastError != "boom" {
+			t.Fatalf("unexpected job after failure: %+v", current)
+		}
+		if want := h.Clock.Now().Add(delay); !current.RunAt.Equal(want) {
+			t.Fatalf("run_at = %s, want %s", current.RunAt, want)
+		}
+		h.Clock.Advance(delay)
+	}
+
+	runOnce(t, worker)
+	if current := reload(t, h, job.ID); current.Status != models.JobDead || current.Attempts != 4 {
+		t.Fatalf("job should be dead lettered: %+v", current)
+	}
+}
+
+func TestWorkerFailsUnknownTypeAndRecoversPanic(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, "panic", func(ctx context.Context, payload greetPayload) error {
+		panic("kaboom")
+	})
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{})
+
+	unknown, _ := jobs.Enqueue(context.Background(), h.DB, "missing", nil, jobs.WithMaxAttempts(1))
+	panicking, _ := jobs.Enqueue(context.Background(), h.DB, "panic", greetPayload{}, jobs.WithMaxAttempts(1))
+	runOnce(t, worker)
+	runOnce(t, worker)
+
+	if job := reload(t, h, unknown.ID); job.Status != models.JobDead {
+		t.Fatalf("unknown job type should be dead: %+v", job)
+	}
+	if job := reload(t, h, panicking.ID); job.Status != models.JobDead || job.LastError != "panic: kaboom" {
+		t.Fatalf("panicking job should be dead: %+v", job)
+	}
+}
+
+func TestWorkerReclaimsStaleRunningJob(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, "greet", func(ctx context.Context, payload greetPayload) error { return nil })
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{LockTimeout: time.Minute})
+
+	// Job yang ditinggal worker yang mati di tengah proses
+	lockedAt := h.Clock.Now()
+	job := models.Job{Type: "greet", Payload: "{}", Status: models.JobRunning, RunAt: lockedAt, Attempts: 1, MaxAttempts: 3, LockedAt: &lockedAt}
+	h.DB.Create(&job)
+
+	if processed, _ := worker.RunOnce(context.Background()); processed {
+		t.Fatal("running job should stay locked until the lock times out")
+	}
+	h.Clock.Advance(2 * time.Minute)
+	runOnce(t, worker)
+	if current := reload(t, h, job.ID); current.Status != models.JobSucceeded || current.Attempts != 2 {
+		t.Fatalf("unexpected job: %+v", current)
+	}
+}
+
+func TestWorkerDoesNotOverwriteJobAfterLosingLock(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	var job *models.Job
+	jobs.Register(registry, "slow", func(ctx context.Context, payload greetPayload) error {
+		// Handler terlalu lama sehingga job diambil alih worker lain
+		h.DB.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{"locked_by": "other-worker", "attempts": 2})
+		return errors.New("too slow")
+	})
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{})
+
+	job, _ = jobs.Enqueue(context.Background(), h.DB, "slow", greetPayload{}, jobs.WithMaxAttempts(1))
+	runOnce(t, worker)
+
+	current := reload(t, h, job.ID)
+	if current.Status != models.JobRunning || current.LockedBy != "other-worker" || current.LastError != "" {
+		t.Fatalf("job taken over by another worker should be left alone: %+v", current)
+	}
+}
+
+func TestMailerEnqueuesAndHandlerSends(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	jobs.RegisterMailHandler(registry, h.Mailer)
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{})
+
+	msg := mailer.Message{To: "tamu@example.com", Subject: "Halo", Text: "Isi"}
+	if err := jobs.NewMailer(h.DB).Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := h.Mailer.Last("tamu@example.com"); ok {
+		t.Fatal("email should not be sent before the job runs")
+	}
+
+	runOnce(t, worker)
+	sent, ok := h.Mailer.Last("tamu@example.com")
+	if !ok || sent.Subject != "Halo" {
+		t.Fatalf("unexpected email: %+v", sent)
+	}
+
+	// Isi email bisa berisi link atau kode login, jadi tidak disimpan setelah terkirim
+	var job models.Job
+	h.DB.Where("type = ?", jobs.MailJobType).First(&job)
+	if !job.Sensitive || job.Payload != "" {
+		t.Fatalf("mail payload should be cleared: %+v", job)
+	}
+}
+
+func TestWorkerClearsSensitivePayloadOnlyWhenFinished(t *testing.T) {
+	h := testutil.New(t)
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, "flaky", func(ctx context.Context, payload greetPayload) error {
+		return errors.New("smtp down")
+	})
+	worker := jobs.NewWorker(h.DB, registry, jobs.WorkerConfig{Backoff: time.Second})
+
+	job, _ := jobs.Enqueue(context.Background(), h.DB, "flaky", greetPayload{Name: "Budi"}, jobs.WithMaxAttempts(2), jobs.WithSensitivePayload())
+	plain, _ := jobs.Enqueue(context.Background(), h.DB, "flaky", greetPayload{Name: "Budi"}, jobs.WithMaxAttempts(1))
+
+	// Payload tetap ada selama job masih akan dicoba ulang
+	runOnce(t, worker)
+	runOnce(t, worker)
+	if current := reload(t, h, job.ID); current.Status != models.JobPending || current.Payload == "" {
+		t.Fatalf("pending job should keep its payload: %+v", current)
+	}
+
+	h.Clock.Advance(time.Minute)
+	runOnce(t, worker)
+	if current := reload(t, h, job.ID); current.Status != models.JobDead || current.Payload != "" {
+		t.Fatalf("dead sensitive job should lose its payload: %+v", current)
+	}
+	if current := reload(t, h, plain.ID); current.Status != models.JobDead || current.Payload == "" {
+		t.Fatalf("dead job without secrets should keep its payload for retry: %+v", current)
+	}
+}
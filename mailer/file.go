@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang-starter-kit/utils"
+)
+
+// FileMailer menyimpan setiap email sebagai file .eml di sebuah folder,
+// bisa dibuka dengan mail client untuk mengecek tampilan email saat development
+type FileMailer struct {
+	dir string
+}
+
+// NewFileMailer membuat FileMailer yang menulis ke folder dir
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{dir: dir}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = defaultFrom()
+	}
+	body, err := buildMIME(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", utils.Now().Format("20060102-150405"), utils.NewID())
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0644)
+}
@@ -0,0 +1,96 @@
+// Package mailer menyediakan abstraksi pengiriman email beserta beberapa
+// implementasinya: SMTP, file-drop (.eml), log dan in-memory.
+package mailer
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-starter-kit/config"
+)
+
+// Message adalah email yang akan dikirim
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer mengirim email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	defaultMailer Mailer = LogMailer{}
+	defaultMutex  sync.RWMutex
+)
+
+// InitMailer memilih Mailer default berdasarkan env:
+//
+//	MAIL_DRIVER  log (default), memory, file, smtp
+//	MAIL_ASYNC   true untuk mengirim lewat Queue dengan retry
+func InitMailer() {
+	var m Mailer
+	switch strings.ToLower(config.GetEnv("MAIL_DRIVER", "log")) {
+	case "memory":
+		m = NewMemoryMailer()
+	case "file":
+		m = NewFileMailer(config.GetEnv("MAIL_DIR", "storage/mail"))
+	case "smtp":
+		m = NewSMTPMailer(SMTPConfig{
+			Host:     os.Getenv("MAIL_HOST"),
+			Port:     config.GetEnvInt("MAIL_PORT", 587),
+			Username: os.Getenv("MAIL_USERNAME"),
+			Password: os.Getenv("MAIL_PASSWORD"),
+		})
+	default:
+		m = LogMailer{}
+	}
+
+	if config.GetEnvBool("MAIL_ASYNC", false) {
+		m = NewQueue(m, QueueConfig{
+			Workers:    config.GetEnvInt("MAIL_QUEUE_WORKERS", 2),
+			Size:       config.GetEnvInt("MAIL_QUEUE_SIZE", 100),
+			MaxRetries: config.GetEnvInt("MAIL_QUEUE_RETRIES", 3),
+			Backoff:    time.Duration(config.GetEnvInt("MAIL_QUEUE_BACKOFF_SECONDS", 5)) * time.Second,
+		})
+	}
+	SetDefault(m)
+}
+
+// SetDefault mengganti Mailer default, nil mengembalikan ke LogMailer
+func SetDefault(m Mailer) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if m == nil {
+		m = LogMailer{}
+	}
+	defaultMailer = m
+}
+
+// Default mengembalikan Mailer default
+func Default() Mailer {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultMailer
+}
+
+// defaultFrom adalah alamat pengirim jika Message.From kosong
+func defaultFrom() string {
+	return config.GetEnv("MAIL_FROM", "no-reply@localhost")
+}
+
+// LogMailer hanya menulis email ke log, cocok untuk development
+type LogMailer struct{}
+
+func (LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("[mailer] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
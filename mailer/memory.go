@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// MemoryMailer menyimpan email di memori, dipakai untuk test
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryMailer membuat MemoryMailer kosong
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages mengembalikan salinan semua email yang sudah dikirim
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// Last mengembalikan email terakhir yang dikirim ke alamat to
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if strings.EqualFold(m.messages[i].To, to) {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// Reset menghapus semua email yang tersimpan
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
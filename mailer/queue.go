@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull dikembalikan Queue.Send jika antrian penuh
+var ErrQueueFull = errors.New("mail queue is full")
+
+// ErrQueueClosed dikembalikan Queue.Send setelah Queue ditutup
+var ErrQueueClosed = errors.New("mail queue is closed")
+
+// QueueConfig adalah pengaturan Queue
+type QueueConfig struct {
+	Workers    int           // Jumlah goroutine pengirim
+	Size       int           // Kapasitas antrian
+	MaxRetries int           // Jumlah percobaan ulang setelah percobaan pertama gagal
+	Backoff    time.Duration // Jeda sebelum retry pertama, berlipat dua setiap retry
+}
+
+// Queue mengirim email secara asinkron lewat Mailer lain dengan retry dan backoff.
+// Queue sendiri adalah Mailer sehingga bisa dipasang sebagai Mailer default.
+type Queue struct {
+	mailer Mailer
+	config QueueConfig
+	jobs   chan Message
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewQueue membuat Queue dan langsung menjalankan worker-nya
+func NewQueue(m Mailer, config QueueConfig) *Queue {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.Size < 1 {
+		config.Size = 1
+	}
+
+	q := &Queue{
+		mailer: m,
+		config: config,
+		jobs:   make(chan Message, config.Size),
+	}
+	for i := 0; i < config.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Send memasukkan email ke antrian tanpa menunggu email terkirim
+func (q *Queue) Send(ctx context.Context, msg Message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close berhenti menerima email baru dan menunggu antrian selesai diproses atau ctx berakhir
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work mengambil email dari antrian dan mengirimnya dengan retry
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for msg := range q.jobs {
+		if err := q.deliver(msg); err != nil {
+			log.Printf("[mailer] gagal mengirim email ke %s setelah %d percobaan: %v", msg.To, q.config.MaxRetries+1, err)
+		}
+	}
+}
+
+// deliver mengirim satu email, mencoba ulang dengan backoff eksponensial jika gagal
+func (q *Queue) deliver(msg Message) error {
+	backoff := q.config.Backoff
+	var err error
+	for attempt := 0; attempt <= q.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = q.mailer.Send(context.Background(), msg); err == nil {
+			return nil
+		}
+	}
+	return err
+}
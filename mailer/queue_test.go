@@ -0,0 +1,61 @@
+package mailer_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-starter-kit/mailer"
+)
+
+// flakyMailer gagal sebanyak failures kali sebelum berhasil
+type flakyMailer struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     []mailer.Message
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts++
+	if m.attempts <= m.failures {
+		return errors.New("smtp down")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestQueueRetriesUntilDelivered(t *testing.T) {
+	flaky := &flakyMailer{failures: 2}
+	queue := mailer.NewQueue(flaky, mailer.QueueConfig{Workers: 1, Size: 10, MaxRetries: 3, Backoff: time.Millisecond})
+
+	if err := queue.Send(context.Background(), mailer.Message{To: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if flaky.attempts != 3 || len(flaky.sent) != 1 {
+		t.Fatalf("attempts = %d, sent = %d; want 3 attempts and 1 email", flaky.attempts, len(flaky.sent))
+	}
+	if err := queue.Send(context.Background(), mailer.Message{}); err != mailer.ErrQueueClosed {
+		t.Fatalf("err = %v, want ErrQueueClosed", err)
+	}
+}
+
+func TestQueueGivesUpAfterMaxRetries(t *testing.T) {
+	flaky := &flakyMailer{failures: 10}
+	queue := mailer.NewQueue(flaky, mailer.QueueConfig{Workers: 1, Size: 10, MaxRetries: 2, Backoff: time.Millisecond})
+
+	queue.Send(context.Background(), mailer.Message{To: "a@example.com"})
+	queue.Close(context.Background())
+
+	if flaky.attempts != 3 || len(flaky.sent) != 0 {
+		t.Fatalf("attempts = %d, sent = %d; want 3 attempts and 0 emails", flaky.attempts, len(flaky.sent))
+	}
+}
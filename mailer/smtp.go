@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+)
+
+// SMTPConfig adalah pengaturan server SMTP. Username kosong berarti tanpa autentikasi,
+// cocok untuk SMTP catcher lokal seperti MailHog atau Mailpit.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// SMTPMailer mengirim email lewat server SMTP
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer membuat SMTPMailer
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = defaultFrom()
+	}
+	body, err := buildMIME(msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	addr := fmt.Sprintf("%s:%d", m.config.Host, m.config.Port)
+
+	// smtp.SendMail tidak mendukung context, jadi pembatalan dicek sebelum mengirim
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(addr, auth, msg.From, []string{msg.To}, body)
+}
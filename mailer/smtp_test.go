@@ -0,0 +1,107 @@
+package mailer_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang-starter-kit/mailer"
+)
+
+// smtpCatcher adalah server SMTP minimal yang menyimpan isi DATA, seperti MailHog
+func smtpCatcher(t *testing.T) (host string, port int, received chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received = make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	host, port, received := smtpCatcher(t)
+	m := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Port: port})
+
+	err := m.Send(context.Background(), mailer.Message{
+		From:    "app@example.com",
+		To:      "a@example.com",
+		Subject: "Halo",
+		Text:    "isi teks",
+		HTML:    "<p>isi html</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := <-received
+	for _, want := range []string{"To: a@example.com", "Subject: Halo", "multipart/alternative", "isi teks", "<p>isi html</p>"} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("message missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m := mailer.NewFileMailer(filepath.Join(dir, "mail"))
+
+	if err := m.Send(context.Background(), mailer.Message{To: "a@example.com", Subject: "Halo", Text: "isi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mail", "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("found %d .eml files, want 1", len(files))
+	}
+	body, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(body), "To: a@example.com") || !strings.Contains(string(body), "From: no-reply@localhost") {
+		t.Fatalf("unexpected .eml:\n%s", body)
+	}
+}
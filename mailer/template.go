@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+
+	"golang-starter-kit/config"
+)
+
+// Template email per locale ada di templates/<locale>/<nama>.txt dan <nama>.html.
+// File .txt wajib ada dan harus mendefinisikan blok {{define "subject"}}.
+// File .html opsional, jika tidak ada email dikirim sebagai teks biasa.
+//
+//go:embed templates
+var templateFS embed.FS
+
+// ErrTemplateNotFound dikembalikan Compose jika template tidak ada di locale manapun
+var ErrTemplateNotFound = errors.New("mail template not found")
+
+// DefaultLocale adalah locale yang dipakai jika locale yang diminta tidak punya template
+func DefaultLocale() string {
+	return config.GetEnv("MAIL_DEFAULT_LOCALE", "id")
+}
+
+// Compose merender template email untuk penerima to. Jika template tidak ada
+// untuk locale yang diminta, dipakai DefaultLocale.
+func Compose(locale, name, to string, data interface{}) (Message, error) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if !templateExists(locale, name) {
+		locale = DefaultLocale()
+	}
+	if !templateExists(locale, name) {
+		return Message{}, ErrTemplateNotFound
+	}
+	base := "templates/" + locale + "/" + name
+
+	textTmpl, err := texttemplate.ParseFS(templateFS, base+".txt")
+	if err != nil {
+		return Message{}, err
+	}
+	var subject, text bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := textTmpl.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+
+	msg := Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}
+
+	if _, err := fs.Stat(templateFS, base+".html"); err == nil {
+		htmlTmpl, err := htmltemplate.ParseFS(templateFS, base+".html")
+		if err != nil {
+			return Message{}, err
+		}
+		var html bytes.Buffer
+		if err := htmlTmpl.Execute(&html, data); err != nil {
+			return Message{}, err
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
+
+// templateExists mengecek apakah template teks ada untuk locale tertentu
+func templateExists(locale, name string) bool {
+	if locale == "" || strings.ContainsAny(locale+name, "/.") {
+		return false
+	}
+	_, err := fs.Stat(templateFS, "templates/"+locale+"/"+name+".txt")
+	return err == nil
+}
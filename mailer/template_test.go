@@ -0,0 +1,50 @@
+package mailer_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang-starter-kit/mailer"
+)
+
+func invitationData() map[string]interface{} {
+	return map[string]interface{}{
+		"Link":      "https://app.example.com/accept?token=<abc>",
+		"ExpiresAt": time.Date(2025, 1, 4, 9, 0, 0, 0, time.UTC),
+		"RoleName":  "editor",
+	}
+}
+
+func TestComposeLocalized(t *testing.T) {
+	msg, err := mailer.Compose("en", "invitation", "a@example.com", invitationData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "You're invited to join as editor" {
+		t.Fatalf("subject = %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "https://app.example.com/accept?token=<abc>") {
+		t.Fatalf("text body missing raw link: %s", msg.Text)
+	}
+	// html/template meng-escape data
+	if !strings.Contains(msg.HTML, "token=%3cabc%3e") {
+		t.Fatalf("html body not escaped: %s", msg.HTML)
+	}
+}
+
+func TestComposeFallsBackToDefaultLocale(t *testing.T) {
+	for _, locale := range []string{"", "fr", "../id"} {
+		msg, err := mailer.Compose(locale, "invitation", "a@example.com", invitationData())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Subject != "Undangan bergabung sebagai editor" {
+			t.Fatalf("%q: subject = %q", locale, msg.Subject)
+		}
+	}
+
+	if _, err := mailer.Compose("id", "tidak-ada", "a@example.com", nil); err != mailer.ErrTemplateNotFound {
+		t.Fatalf("err = %v, want ErrTemplateNotFound", err)
+	}
+}
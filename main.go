@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
 	"github.com/joho/godotenv"  // Untuk memuat variabel dari file .env
 	"golang-starter-kit/config" // Package untuk konfigurasi dan koneksi database
+	"golang-starter-kit/controllers" // Package controller (inisialisasi service)
+	"golang-starter-kit/jobs" // Package antrian job di database
+	"golang-starter-kit/mailer" // Package pengiriman email
 	"golang-starter-kit/models" // Package untuk model database (migrasi, dll)
 	"golang-starter-kit/routes" // Package untuk routing menggunakan Gin framework
+	"golang-starter-kit/scheduler" // Package task periodik (cron)
 	"golang-starter-kit/utils"  // Helper Blacklist
 )
 
@@ -21,8 +27,26 @@ func main() {
 	config.ConnectDB()
 	// Inisialisasi model database (migrasi, dll)
 	models.InitModel()
+	// Memilih driver pengiriman email
+	mailer.InitMailer()
+	// Menyiapkan worker antrian job (juga pengiriman email jika MAIL_VIA_JOBS=true)
+	worker := jobs.InitJobs(models.DB)
+	// Inisialisasi repository dan service yang dipakai controller
+	controllers.InitController()
 	// Memuat blacklist dari file
 	utils.InitBlacklist()
+	// Jalankan worker job, JOBS_WORKERS=0 berarti job diproses oleh proses lain
+	if config.GetEnvInt("JOBS_WORKERS", 2) > 0 {
+		go worker.Start(context.Background())
+	}
+	// Jalankan task periodik (compaction blacklist, cleanup token, retensi soft delete)
+	if config.GetEnvBool("SCHEDULER_ENABLED", true) {
+		tasks, err := scheduler.InitScheduler(models.DB, controllers.SharedUserService(), controllers.SharedSessionService(), controllers.SharedAuditService())
+		if err != nil {
+			log.Fatal(err)
+		}
+		go tasks.Start(context.Background())
+	}
 	// Setup routing menggunakan Gin framework
 	r := routes.SetupRoutes()
 
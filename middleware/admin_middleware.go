@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"        // Framework web Gin
+	"golang-starter-kit/models"       // Model database (nama role admin)
+	"golang-starter-kit/repositories" // Akses data user
+)
+
+// ContextIsAdmin menyimpan hasil pengecekan admin agar database tidak di-query berulang
+const ContextIsAdmin = "is_admin"
+
+// IsAdmin mengecek apakah user yang sedang login memiliki role admin.
+// Harus dipanggil setelah JWTAuth.
+func IsAdmin(c *gin.Context) bool {
+	if isAdmin, exists := c.Get(ContextIsAdmin); exists {
+		return isAdmin.(bool)
+	}
+
+	isAdmin := false
+	if userID := CurrentUserID(c); userID != 0 {
+		user, err := repositories.NewUserRepository(models.DB).FindByID(c.Request.Context(), userID)
+		isAdmin = err == nil && user.Role.Name == models.AdminRoleName
+	}
+	c.Set(ContextIsAdmin, isAdmin)
+	return isAdmin
+}
+
+// AdminOnly adalah middleware yang hanya meloloskan user dengan role admin
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
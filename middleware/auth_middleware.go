@@ -2,58 +2,139 @@ package middleware
 
 import (
 	"net/http"
-	"os"
 	"strings"
-	"github.com/gin-gonic/gin"     // Framework web Gin
-	"github.com/golang-jwt/jwt/v5" // Library JWT untuk parsing dan verifikasi token
-	"golang-starter-kit/utils"     // Helper Blacklist
+	"github.com/gin-gonic/gin"        // Framework web Gin
+	"github.com/golang-jwt/jwt/v5"    // Library JWT untuk membaca claims
+	"golang-starter-kit/models"       // Model database (Session)
+	"golang-starter-kit/repositories" // Akses data session
+	"golang-starter-kit/utils"        // Helper (blacklist, jwt)
 )
 
-// JWTAuth adalah middleware untuk memverifikasi JWT token yang dikirim oleh client
+// Key context yang diisi oleh JWTAuth
+const (
+	ContextUserID    = "user_id"
+	ContextEmail     = "email"
+	ContextSessionID = "session_id"
+)
+
+// JWTAuth adalah middleware untuk memverifikasi JWT token yang dikirim oleh client.
+// Personal access token (Authorization: Bearer pat_... atau X-API-Key) juga diterima.
 func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Ambil Authorization header dari request
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			// Jika header tidak ada, tolak permintaan
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		if message := authenticate(c); message != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": message})
 			c.Abort()
 			return
 		}
 
-		// Hapus prefix "Bearer " dari token string
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			// Jika token tidak diawali dengan "Bearer ", tolak permintaan
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
-			c.Abort()
-			return
-		}
+		// Lanjutkan ke handler berikutnya jika token valid
+		c.Next()
+	}
+}
 
-		// Cek apakah token sudah di-blacklist (misalnya setelah logout)
-		if utils.IsBlacklisted(tokenString) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been logged out"})
-			c.Abort()
+// OptionalJWTAuth sama seperti JWTAuth, tetapi request tanpa header Authorization
+// atau X-API-Key tetap diteruskan sebagai anonim. Token yang dikirim tetap harus valid.
+func OptionalJWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && c.GetHeader(APIKeyHeader) == "" {
+			c.Next()
 			return
 		}
+		JWTAuth()(c)
+	}
+}
 
-		// Parse token dan validasi menggunakan JWT secret
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Pastikan metode penandatanganan yang digunakan adalah HMAC
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+// authenticate memverifikasi token dari header Authorization dan menyimpan identitas
+// user ke context. Mengembalikan pesan error, atau string kosong jika token valid.
+func authenticate(c *gin.Context) string {
+	// Personal access token diperiksa terpisah dari JWT
+	if plain := apiTokenFrom(c); plain != "" {
+		return authenticateAPIToken(c, plain)
+	}
 
-		// Jika token tidak valid, tolak permintaan
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
+	// Ambil Authorization header dari request
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		// Jika header tidak ada, tolak permintaan
+		return "Authorization header required"
+	}
+
+	// Hapus prefix "Bearer " dari token string
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		// Jika token tidak diawali dengan "Bearer ", tolak permintaan
+		return "Bearer token required"
+	}
+
+	// Cek apakah token sudah di-blacklist (misalnya setelah logout)
+	if utils.IsBlacklisted(tokenString) {
+		return "Token has been logged out"
+	}
+
+	// Parse token dan validasi menggunakan JWT secret
+	token, err := utils.ParseJWT(tokenString)
+
+	// Jika token tidak valid, tolak permintaan
+	if err != nil || !token.Valid {
+		return "Invalid token"
+	}
+
+	// Simpan identitas user dari claims agar bisa dipakai handler berikutnya
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if id, ok := claims["id"].(float64); ok {
+			c.Set(ContextUserID, uint(id))
+			// Identitas user juga dibawa ke context request untuk audit log di layer service
+			c.Request = c.Request.WithContext(utils.WithRequestUserID(c.Request.Context(), uint(id)))
+		}
+		if email, ok := claims["email"].(string); ok {
+			c.Set(ContextEmail, email)
 		}
+		// Access token OAuth dibatasi scope-nya dan bisa dicabut per grant
+		if _, ok := claims["client_id"].(string); ok {
+			return authenticateOAuth(c, claims)
+		}
+		// Token dari session yang sudah dicabut (logout dari perangkat lain) ditolak
+		if sid, ok := claims["sid"].(string); ok && sid != "" {
+			if sessionRevoked(c, sid) {
+				return "Session has been revoked"
+			}
+			c.Set(ContextSessionID, sid)
+		}
+	}
+	return ""
+}
 
-		// Lanjutkan ke handler berikutnya jika token valid
-		c.Next()
+// sessionRevoked mengecek apakah session sudah dicabut. Database adalah sumber kebenaran, blacklist
+// hanya jalur cepat: session yang dicabut di replica lain atau hilang dari blacklist (misalnya setelah
+// blacklist dikosongkan) tetap ditolak. Status aktif dari database dipakai ulang selama
+// utils.RevocationCheckTTL agar tidak setiap request membaca database.
+func sessionRevoked(c *gin.Context, sid string) bool {
+	if utils.IsSessionRevoked(sid) {
+		return true
+	}
+	if utils.IsSessionKnownActive(sid) {
+		return false
+	}
+
+	// Session yang tidak ditemukan atau statusnya tidak bisa dipastikan dianggap dicabut
+	session, err := repositories.NewSessionRepository(models.DB).FindByID(c.Request.Context(), sid)
+	if err != nil {
+		return true
 	}
+	if session.RevokedAt != nil {
+		utils.RevokeSession(sid, session.ExpiresAt)
+		return true
+	}
+	utils.MarkSessionActive(sid)
+	return false
+}
+
+// CurrentUserID mengembalikan ID user yang sedang login, 0 jika tidak ada
+func CurrentUserID(c *gin.Context) uint {
+	return c.GetUint(ContextUserID)
+}
+
+// CurrentSessionID mengembalikan ID session dari token yang sedang dipakai, kosong jika token tanpa session
+func CurrentSessionID(c *gin.Context) string {
+	return c.GetString(ContextSessionID)
 }
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin" // Framework web Gin
+	"golang-starter-kit/utils" // Helper (request context, id)
+)
+
+// HeaderRequestID adalah header untuk melacak satu request di log dan audit
+const HeaderRequestID = "X-Request-ID"
+
+// ContextRequestID adalah key context gin yang berisi request ID
+const ContextRequestID = "request_id"
+
+// requestIDPattern membatasi request ID dari client agar aman disimpan dan ditampilkan
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestContext memberi setiap request sebuah request ID (dari header X-Request-ID atau dibuat baru)
+// dan menyimpan IP, user agent dan request ID ke context request agar bisa dibaca layer service
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = utils.NewID()
+		}
+		c.Set(ContextRequestID, requestID)
+		c.Header(HeaderRequestID, requestID)
+
+		c.Request = c.Request.WithContext(utils.WithRequestInfo(c.Request.Context(), utils.RequestInfo{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID,
+		}))
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// APITokenPrefix adalah awalan personal access token, membedakannya dari JWT di header Authorization
+const APITokenPrefix = "pat_"
+
+// Scope yang bisa dipilih untuk personal access token. Scope hanya membatasi akses,
+// token tidak pernah bisa melebihi hak role pemiliknya.
+const (
+	ScopeUsersRead   = "users:read"
+	ScopeUsersWrite  = "users:write"
+	ScopeRolesRead   = "roles:read"
+	ScopeRolesWrite  = "roles:write"
+	ScopeInvitations = "invitations"
+	ScopeAdmin       = "admin"
+	ScopeSCIM        = "scim" // Provisioning user dan grup lewat /scim/v2
+)
+
+// APITokenScopes adalah semua scope yang valid
+var APITokenScopes = []string{
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeRolesRead,
+	ScopeRolesWrite,
+	ScopeInvitations,
+	ScopeAdmin,
+	ScopeSCIM,
+}
+
+// APIToken adalah personal access token / API key milik user. Token asli hanya ditampilkan
+// sekali saat dibuat, yang disimpan hanya hash-nya.
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"size:16" json:"prefix"`         // Awal token untuk mengenali token di daftar
+	TokenHash  string     `gorm:"uniqueIndex;not null" json:"-"` // SHA-256 dari token
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"` // Disimpan sebagai JSON array
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:64" json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relation
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// ActiveAt mengecek apakah token belum dicabut dan belum kadaluwarsa pada waktu now
+func (t *APIToken) ActiveAt(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// HasScope mengecek apakah token memiliki scope tertentu
+func (t *APIToken) HasScope(scope string) bool {
+	return containsString(t.Scopes, scope)
+}
@@ -0,0 +1,130 @@
+// Koneksi ke DB1
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogImmutable dikembalikan jika ada yang mencoba mengubah atau menghapus audit log
+var ErrAuditLogImmutable = errors.New("audit log is immutable")
+
+// AuditLog adalah satu perubahan data. Setiap entry menyimpan hash isinya sendiri beserta
+// hash entry sebelumnya (hash chain), sehingga perubahan atau penghapusan entry lama
+// bisa dideteksi dengan menghitung ulang rantai hash.
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActorID    *uint     `gorm:"index" json:"actor_id"`                 // nil berarti anonim atau sistem
+	Action     string    `gorm:"size:100;not null;index" json:"action"` // contoh: user.create, role.delete
+	TargetType string    `gorm:"size:50;not null;index:idx_audit_logs_target,priority:1" json:"target_type"`
+	TargetID   string    `gorm:"size:64;index:idx_audit_logs_target,priority:2" json:"target_id"`
+	Changes    JSONText  `gorm:"type:text" json:"changes"` // {"field": {"before": ..., "after": ...}}
+	IP         string    `gorm:"size:64" json:"ip"`
+	UserAgent  string    `gorm:"size:255" json:"user_agent"`
+	RequestID  string    `gorm:"size:64;index" json:"request_id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	PrevHash   string    `gorm:"size:64;not null;default:''" json:"prev_hash"` // Hash entry sebelumnya, kosong untuk entry pertama
+	Hash       string    `gorm:"size:64;not null;default:'';index" json:"hash"`
+}
+
+// ComputeHash menghitung SHA-256 (hex) dari isi entry dan PrevHash.
+// ID tidak ikut di-hash karena baru diketahui setelah insert; urutan dijaga oleh PrevHash.
+func (a *AuditLog) ComputeHash() string {
+	content, _ := json.Marshal(struct {
+		PrevHash   string `json:"prev_hash"`
+		ActorID    *uint  `json:"actor_id"`
+		Action     string `json:"action"`
+		TargetType string `json:"target_type"`
+		TargetID   string `json:"target_id"`
+		Changes    string `json:"changes"`
+		IP         string `json:"ip"`
+		UserAgent  string `json:"user_agent"`
+		RequestID  string `json:"request_id"`
+		CreatedAt  string `json:"created_at"`
+	}{
+		PrevHash:   a.PrevHash,
+		ActorID:    a.ActorID,
+		Action:     a.Action,
+		TargetType: a.TargetType,
+		TargetID:   a.TargetID,
+		Changes:    string(a.Changes),
+		IP:         a.IP,
+		UserAgent:  a.UserAgent,
+		RequestID:  a.RequestID,
+		CreatedAt:  a.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// BeforeUpdate menolak perubahan audit log lewat GORM
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete menolak penghapusan audit log lewat GORM
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// AuditChainHead menyimpan ujung rantai hash audit log (selalu satu baris dengan ID 1).
+// Baris ini dikunci saat menambah entry agar penulisan dari beberapa replika tetap membentuk satu rantai.
+type AuditChainHead struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement:false" json:"id"`
+	LastID   uint   `gorm:"not null;default:0" json:"last_id"`
+	LastHash string `gorm:"size:64;not null;default:''" json:"last_hash"`
+}
+
+// AuditCheckpoint adalah tanda tangan Ed25519 atas ujung rantai audit log pada suatu waktu.
+// Checkpoint bisa diekspor dan diarsipkan di luar sistem sebagai bukti isi audit log.
+type AuditCheckpoint struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	LastID    uint      `gorm:"not null" json:"last_id"` // ID audit log terakhir yang tercakup
+	LastHash  string    `gorm:"size:64;not null" json:"last_hash"`
+	Count     int64     `gorm:"not null" json:"count"`               // Jumlah audit log dengan ID <= LastID
+	KeyID     string    `gorm:"size:16;not null" json:"key_id"`      // 8 byte pertama SHA-256 public key (hex)
+	Signature string    `gorm:"type:text;not null" json:"signature"` // Base64
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SigningPayload adalah pesan yang ditandatangani untuk checkpoint
+func (c *AuditCheckpoint) SigningPayload() []byte {
+	return []byte(fmt.Sprintf("audit-checkpoint:v1:%d:%s:%d:%s",
+		c.LastID, c.LastHash, c.Count, c.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+// backfillAuditChain mengisi hash untuk audit log yang dibuat sebelum hash chain ada,
+// lalu menyimpan ujung rantainya. Entry yang sudah punya hash tidak diubah.
+func backfillAuditChain(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		head := AuditChainHead{ID: 1}
+		if err := tx.FirstOrCreate(&head).Error; err != nil {
+			return err
+		}
+
+		var logs []AuditLog
+		if err := tx.Where("hash = ''").Order("id").Find(&logs).Error; err != nil {
+			return err
+		}
+		for _, entry := range logs {
+			entry.PrevHash = head.LastHash
+			entry.Hash = entry.ComputeHash()
+			// UpdateColumns melewati hook BeforeUpdate yang menolak perubahan audit log
+			err := tx.Model(&entry).UpdateColumns(map[string]interface{}{"prev_hash": entry.PrevHash, "hash": entry.Hash}).Error
+			if err != nil {
+				return err
+			}
+			head.LastID, head.LastHash = entry.ID, entry.Hash
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		return tx.Save(&head).Error
+	})
+}
@@ -0,0 +1,20 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// EmailChange adalah permintaan ganti email yang menunggu konfirmasi dari alamat baru
+type EmailChange struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	NewEmail    string     `gorm:"not null" json:"new_email"`
+	Locale      string     `gorm:"size:10" json:"locale"`
+	TokenHash   string     `gorm:"not null" json:"-"` // SHA-256 dari nonce pada link konfirmasi
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relation
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
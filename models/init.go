@@ -1,6 +1,8 @@
 package models
 
 import (
+	"log"
+
 	"golang-starter-kit/config" // Mengimpor koneksi database dari package config
 	"gorm.io/gorm"              // ORM (Object Relational Mapper) dari GORM
 )
@@ -12,4 +14,61 @@ var DB *gorm.DB
 func InitModel() {
 	// Ambil koneksi database dari package config dan simpan ke variabel global DB
 	DB = config.DB
+
+	// Samakan struktur tabel dengan model
+	if err := Migrate(DB); err != nil {
+		log.Fatal("Failed to migrate DB: ", err)
+	}
+}
+
+// Migrate membuat atau memperbarui tabel untuk semua model, melengkapi hash chain audit log
+// lama, lalu memastikan role sistem ada
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&Role{},
+		&User{},
+		&Invitation{},
+		&Job{},
+		&ScheduledTask{},
+		&AuditLog{},
+		&AuditChainHead{},
+		&AuditCheckpoint{},
+		&Session{},
+		&LoginAttempt{},
+		&EmailChange{},
+		&PasswordHistory{},
+		&PendingRegistration{},
+		&APIToken{},
+		&OAuthClient{},
+		&OAuthGrant{},
+		&OAuthAuthorizationCode{},
+		&OAuthConsent{},
+		&UserIdentity{},
+		&SSOLoginState{},
+		&LoginCode{},
+	)
+	if err != nil {
+		return err
+	}
+	if err := backfillAuditChain(db); err != nil {
+		return err
+	}
+	return seedSystemRoles(db)
+}
+
+// seedSystemRoles membuat role sistem yang belum ada dan menandai yang sudah ada sebagai role sistem
+func seedSystemRoles(db *gorm.DB) error {
+	for _, name := range SystemRoles {
+		var role Role
+		err := db.Where(Role{Name: name}).Attrs(Role{IsSystem: true}).FirstOrCreate(&role).Error
+		if err != nil {
+			return err
+		}
+		if !role.IsSystem {
+			if err := db.Model(&role).Update("is_system", true).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
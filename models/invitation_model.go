@@ -0,0 +1,45 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// Status undangan, dihitung dari kolom AcceptedAt, RevokedAt dan ExpiresAt
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+type Invitation struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Email      string     `gorm:"index;not null" json:"email"`
+	IDRole     uint       `gorm:"not null" json:"id_role"`
+	InvitedBy  uint       `json:"invited_by"`
+	Locale     string     `gorm:"size:10" json:"locale"` // Bahasa email undangan
+	TokenHash  string     `gorm:"not null" json:"-"` // SHA-256 dari nonce pada link undangan terakhir
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     time.Time  `json:"sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Status     string     `gorm:"-" json:"status"` // Diisi lewat StatusAt, tidak disimpan
+
+	// Relation
+	Role Role `gorm:"foreignKey:IDRole;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"role"`
+}
+
+// StatusAt mengembalikan status undangan pada waktu now
+func (i *Invitation) StatusAt(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
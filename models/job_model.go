@@ -0,0 +1,29 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// Status job di antrian
+const (
+	JobPending   = "pending"   // Menunggu dijalankan (termasuk menunggu retry)
+	JobRunning   = "running"   // Sedang diproses worker
+	JobSucceeded = "succeeded" // Selesai tanpa error
+	JobDead      = "dead"      // Gagal terus sampai batas percobaan habis (dead letter)
+)
+
+type Job struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Type        string     `gorm:"size:100;not null;index" json:"type"`
+	Payload     string     `gorm:"type:text;not null" json:"-"`             // JSON, bisa berisi data rahasia
+	Sensitive   bool       `gorm:"not null;default:false" json:"sensitive"` // Payload dihapus setelah job selesai atau dead
+	Status      string     `gorm:"size:20;not null;index:idx_jobs_status_run_at,priority:1" json:"status"`
+	RunAt       time.Time  `gorm:"not null;index:idx_jobs_status_run_at,priority:2" json:"run_at"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null" json:"max_attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error"`
+	LockedBy    string     `gorm:"size:100" json:"locked_by"`
+	LockedAt    *time.Time `json:"locked_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONText adalah dokumen JSON yang disimpan sebagai kolom text dan
+// dikirim apa adanya (bukan sebagai string) pada response API
+type JSONText json.RawMessage
+
+// Value menyimpan JSONText sebagai string, kosong disimpan sebagai NULL
+func (j JSONText) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan membaca JSONText dari database
+func (j *JSONText) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSONText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSONText", value)
+	}
+	return nil
+}
+
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSONText) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[:0], data...)
+	return nil
+}
@@ -0,0 +1,25 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// Alasan login gagal yang dicatat di LoginAttempt
+const (
+	LoginFailedUnknownEmail  = "unknown_email"
+	LoginFailedWrongPassword = "wrong_password"
+	LoginFailedDeactivated   = "deactivated"
+	LoginFailedInvalidCode   = "invalid_code" // Magic link atau kode login tanpa password salah
+)
+
+// LoginAttempt adalah riwayat percobaan login, berhasil maupun gagal
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    *uint     `gorm:"index" json:"user_id"` // nil jika email tidak terdaftar
+	Email     string    `gorm:"size:255;index" json:"email"`
+	Success   bool      `gorm:"not null" json:"success"`
+	Reason    string    `gorm:"size:50" json:"reason"` // Kosong jika berhasil
+	SessionID string    `gorm:"size:36" json:"session_id"`
+	IP        string    `gorm:"size:64" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,25 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// Cara login tanpa password yang dikirim lewat email
+const (
+	LoginCodeLink = "link" // Magic link berisi token bertanda tangan
+	LoginCodeOTP  = "code" // Kode 6 digit yang diketik user
+)
+
+// LoginCode adalah magic link atau kode login sekali pakai yang menunggu ditukar dengan JWT
+type LoginCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Method    string     `gorm:"size:10;not null" json:"method"`
+	CodeHash  string     `gorm:"not null" json:"-"` // SHA-256 dari nonce link atau kode
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relation
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,67 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// Grant type OAuth 2.0 yang didukung
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// Scope OpenID Connect, hanya bisa diminta oleh client OAuth
+const (
+	ScopeOpenID  = "openid"  // Wajib untuk menerima ID token
+	ScopeProfile = "profile" // Claim name dan updated_at
+	ScopeEmail   = "email"   // Claim email
+	ScopeRoles   = "roles"   // Claim roles dari Role user
+)
+
+// OIDCScopes adalah semua scope OpenID Connect
+var OIDCScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopeRoles}
+
+// OAuthScopes adalah semua scope yang boleh didaftarkan untuk client OAuth
+var OAuthScopes = append(append([]string{}, APITokenScopes...), OIDCScopes...)
+
+// OAuthClient adalah aplikasi yang terdaftar untuk meminta token lewat OAuth 2.0.
+// Client confidential memiliki secret (hanya hash-nya yang disimpan), client public
+// (SPA, aplikasi mobile) tidak memiliki secret dan wajib memakai PKCE.
+type OAuthClient struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ClientID     string    `gorm:"uniqueIndex;size:64;not null" json:"client_id"`
+	SecretHash   string    `json:"-"` // SHA-256 dari client secret, kosong untuk client public
+	Name         string    `gorm:"not null" json:"name"`
+	RedirectURIs []string  `gorm:"serializer:json" json:"redirect_uris"`
+	GrantTypes   []string  `gorm:"serializer:json" json:"grant_types"`
+	Scopes       []string  `gorm:"serializer:json" json:"scopes"` // Scope terbanyak yang boleh diminta client
+	Confidential bool      `json:"confidential"`
+	FirstParty   bool      `json:"first_party"` // Aplikasi milik sendiri, tidak perlu layar persetujuan
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName menghindari nama tabel bawaan GORM "o_auth_clients"
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// AllowsGrant mengecek apakah client boleh memakai grant type tertentu
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return containsString(c.GrantTypes, grantType)
+}
+
+// AllowsRedirectURI mengecek apakah redirect URI terdaftar untuk client (harus sama persis)
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return containsString(c.RedirectURIs, uri)
+}
+
+// containsString mengecek apakah list berisi value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// OAuthAuthorizationCode adalah kode sekali pakai dari endpoint authorize yang ditukar dengan token
+type OAuthAuthorizationCode struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	CodeHash      string     `gorm:"uniqueIndex;not null" json:"-"` // SHA-256 dari kode
+	OAuthClientID uint       `gorm:"column:oauth_client_id;not null;index" json:"oauth_client_id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	RedirectURI   string     `gorm:"not null" json:"redirect_uri"`
+	Scopes        []string   `gorm:"serializer:json" json:"scopes"`
+	CodeChallenge string     `gorm:"not null" json:"-"`       // PKCE S256
+	Nonce         string     `json:"-"`                       // Nonce OpenID Connect, dikembalikan di ID token
+	GrantID       string     `gorm:"size:36" json:"grant_id"` // Diisi saat kode ditukar, dicabut jika kode dipakai ulang
+	ExpiresAt     time.Time  `gorm:"index" json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// Relation
+	Client OAuthClient `gorm:"foreignKey:OAuthClientID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	User   User        `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// TableName menghindari nama tabel bawaan GORM "o_auth_authorization_codes"
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
@@ -0,0 +1,34 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// OAuthConsent adalah persetujuan user untuk client beserta scope yang sudah disetujui,
+// agar layar persetujuan tidak ditampilkan lagi untuk scope yang sama
+type OAuthConsent struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;uniqueIndex:idx_oauth_consent" json:"user_id"`
+	OAuthClientID uint      `gorm:"column:oauth_client_id;not null;uniqueIndex:idx_oauth_consent" json:"oauth_client_id"`
+	Scopes        []string  `gorm:"serializer:json" json:"scopes"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relation
+	Client OAuthClient `gorm:"foreignKey:OAuthClientID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	User   User        `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// TableName menghindari nama tabel bawaan GORM "o_auth_consents"
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+// Covers mengecek apakah persetujuan sudah mencakup semua scope yang diminta
+func (c *OAuthConsent) Covers(scopes []string) bool {
+	for _, scope := range scopes {
+		if !containsString(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// OAuthGrant adalah satu izin yang diterbitkan untuk client (keluarga token). ID-nya dibawa
+// sebagai claim "gid" di access token, sehingga semua token dari izin yang sama bisa dicabut sekaligus.
+type OAuthGrant struct {
+	ID                  string     `gorm:"primaryKey;size:36" json:"id"`
+	OAuthClientID       uint       `gorm:"column:oauth_client_id;not null;index" json:"oauth_client_id"`
+	UserID              *uint      `gorm:"index" json:"user_id"` // Kosong untuk grant client_credentials
+	Scopes              []string   `gorm:"serializer:json" json:"scopes"`
+	RefreshTokenHash    string     `gorm:"index" json:"-"` // SHA-256 dari refresh token aktif, diganti setiap refresh
+	PreviousRefreshHash string     `gorm:"index" json:"-"` // SHA-256 dari refresh token sebelum rotasi terakhir, untuk mendeteksi pemakaian ulang
+	ExpiresAt           time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt           *time.Time `json:"revoked_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+
+	// Relation
+	Client OAuthClient `gorm:"foreignKey:OAuthClientID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	User   *User       `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// TableName menghindari nama tabel bawaan GORM "o_auth_grants"
+func (OAuthGrant) TableName() string {
+	return "oauth_grants"
+}
+
+// ActiveAt mengecek apakah grant belum dicabut dan belum kadaluwarsa pada waktu now
+func (g *OAuthGrant) ActiveAt(now time.Time) bool {
+	return g.RevokedAt == nil && now.Before(g.ExpiresAt)
+}
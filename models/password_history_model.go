@@ -0,0 +1,15 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// PasswordHistory menyimpan hash password yang pernah dipakai user untuk mencegah pemakaian ulang
+type PasswordHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Hash      string    `gorm:"not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relation
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
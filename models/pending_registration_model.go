@@ -0,0 +1,19 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// PendingRegistration adalah registrasi yang menunggu konfirmasi email, dipakai jika
+// REGISTRATION_ENUMERATION_SAFE aktif. User baru dibuat setelah link konfirmasi dibuka.
+type PendingRegistration struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `json:"name"`
+	Email       string     `gorm:"not null;index" json:"email"`
+	Password    string     `gorm:"not null" json:"-"` // Hash password, sudah divalidasi saat registrasi
+	IDRole      uint       `json:"id_role"`           // 0 berarti role default
+	TokenHash   string     `gorm:"not null" json:"-"` // SHA-256 dari nonce pada link konfirmasi
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -1,12 +1,26 @@
 // Koneksi ke DB1
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nama role sistem yang dibuat otomatis saat migrasi
+const (
+	AdminRoleName = "admin" // Role dengan akses admin
+	UserRoleName  = "user"  // Role umum untuk user biasa
+)
 
 type Role struct {
-	ID        uint       `gorm:"primaryKey" json:"id"`
-	Name      string     `json:"name"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"-"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `json:"name"`
+	IsSystem  bool           `gorm:"not null;default:false" json:"is_system"` // Role sistem tidak bisa dihapus atau diganti nama
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
+
+// SystemRoles adalah daftar role yang wajib ada dan dilindungi
+var SystemRoles = []string{AdminRoleName, UserRoleName}
@@ -0,0 +1,17 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// ScheduledTask adalah status task periodik yang dijalankan scheduler. Baris ini juga
+// berfungsi sebagai leader lock: hanya replika yang memegang lease (LockedBy sampai
+// LockedUntil) yang boleh menjalankan task, dan LastRunAt mencegah jadwal yang sama
+// dijalankan dua kali oleh replika berbeda.
+type ScheduledTask struct {
+	Name        string     `gorm:"primaryKey;size:100" json:"name"`
+	LockedBy    string     `gorm:"size:100" json:"locked_by"`
+	LockedUntil *time.Time `json:"locked_until"`
+	LastRunAt   *time.Time `json:"last_run_at"` // Jadwal terakhir yang sudah dijalankan
+	LastError   string     `gorm:"type:text" json:"last_error"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// Session adalah satu login (keluarga token). ID-nya dibawa sebagai claim "sid" di JWT,
+// sehingga semua token dari login yang sama bisa dicabut sekaligus.
+type Session struct {
+	ID        string     `gorm:"primaryKey;size:36" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	IP        string     `gorm:"size:64" json:"ip"`
+	UserAgent string     `gorm:"size:255" json:"user_agent"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	Current   bool       `gorm:"-" json:"current"` // Diisi saat menampilkan session milik user yang sedang login
+}
+
+// ActiveAt mengecek apakah session belum dicabut dan belum kadaluwarsa pada waktu now
+func (s *Session) ActiveAt(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,17 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// SSOLoginState adalah satu percobaan login SSO yang menunggu callback dari identity provider
+type SSOLoginState struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	StateHash    string     `gorm:"uniqueIndex;not null" json:"-"` // SHA-256 dari parameter state
+	Provider     string     `gorm:"size:50;not null" json:"provider"`
+	CodeVerifier string     `gorm:"not null" json:"-"` // PKCE, dikirim saat menukar code
+	Nonce        string     `gorm:"not null" json:"-"` // Harus sama dengan claim nonce di ID token
+	UserID       *uint      `json:"user_id"`           // Diisi jika identitas akan ditautkan ke user yang sedang login
+	ExpiresAt    time.Time  `gorm:"index" json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
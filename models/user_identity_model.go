@@ -0,0 +1,26 @@
+// Koneksi ke DB1
+package models
+
+import "time"
+
+// IdentityProviderLDAP adalah provider UserIdentity untuk user dari directory LDAP. Subject berisi
+// ID entry di directory (misalnya entryUUID) sehingga user tetap dikenali walaupun DN-nya berubah.
+const IdentityProviderLDAP = "ldap"
+
+// IdentityProviderSCIM adalah provider UserIdentity yang menyimpan externalId dari client SCIM
+const IdentityProviderSCIM = "scim"
+
+// UserIdentity adalah identitas di identity provider eksternal (SSO) yang ditautkan ke user.
+// Satu user bisa punya beberapa identitas, satu identitas (provider + subject) hanya milik satu user.
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Provider    string     `gorm:"size:50;not null;uniqueIndex:idx_user_identities_subject" json:"provider"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_user_identities_subject" json:"subject"` // Claim "sub" dari ID token
+	Email       string     `gorm:"size:255" json:"email"`                                                    // Email terakhir dari identity provider
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Relation
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
@@ -1,18 +1,23 @@
 // Koneksi ke DB1
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
-	ID        uint       `gorm:"primaryKey" json:"id"`
-	IDRole    uint       `json:"id_role"`
-	Name      string     `json:"name"`
-	Email     string     `gorm:"unique" json:"email"`
-	Password  string     `json:"-"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"-"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	IDRole        uint           `gorm:"not null;index" json:"id_role"`
+	Name          string         `json:"name"`
+	Email         string         `gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL" json:"email"` // Unik hanya untuk user yang belum dihapus
+	Password      string         `json:"-"`
+	DeactivatedAt *time.Time     `gorm:"index" json:"deactivated_at"` // Akun dinonaktifkan (tidak bisa login), misalnya sudah tidak ada di LDAP
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	// Relation
-	Role Role `gorm:"foreignKey:IDRole;references:ID" json:"role"`
+	Role Role `gorm:"foreignKey:IDRole;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"role"`
 }
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// APITokenRepository mendefinisikan operasi database untuk model APIToken
+type APITokenRepository interface {
+	FindActiveByUser(ctx context.Context, userID uint, now time.Time) ([]models.APIToken, error)
+	FindByID(ctx context.Context, id uint) (*models.APIToken, error)
+	FindActiveByHash(ctx context.Context, hash string, now time.Time) (*models.APIToken, error)
+	Create(ctx context.Context, token *models.APIToken) error
+	Update(ctx context.Context, token *models.APIToken) error
+	Touch(ctx context.Context, id uint, usedAt time.Time, ip string) error
+}
+
+// apiTokenRepository adalah implementasi APITokenRepository menggunakan GORM
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository membuat APITokenRepository berbasis GORM
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+// FindActiveByUser mengambil token milik user yang belum dicabut dan belum kadaluwarsa, terbaru lebih dulu
+func (r *apiTokenRepository) FindActiveByUser(ctx context.Context, userID uint, now time.Time) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("id DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// FindByID mengambil token berdasarkan ID
+func (r *apiTokenRepository) FindByID(ctx context.Context, id uint) (*models.APIToken, error) {
+	var token models.APIToken
+	err := r.db.WithContext(ctx).First(&token, id).Error
+	return &token, translateError(err)
+}
+
+// FindActiveByHash mengambil token aktif berdasarkan hash, pemiliknya harus user yang belum dihapus dan masih aktif
+func (r *apiTokenRepository) FindActiveByHash(ctx context.Context, hash string, now time.Time) (*models.APIToken, error) {
+	var token models.APIToken
+	err := r.db.WithContext(ctx).
+		Joins("JOIN users ON users.id = api_tokens.user_id AND users.deleted_at IS NULL AND users.deactivated_at IS NULL").
+		Where("api_tokens.token_hash = ? AND api_tokens.revoked_at IS NULL AND api_tokens.expires_at > ?", hash, now).
+		First(&token).Error
+	return &token, translateError(err)
+}
+
+// Create menyimpan token baru
+func (r *apiTokenRepository) Create(ctx context.Context, token *models.APIToken) error {
+	return r.db.WithContext(ctx).Omit("User").Create(token).Error
+}
+
+// Update menyimpan perubahan token
+func (r *apiTokenRepository) Update(ctx context.Context, token *models.APIToken) error {
+	return r.db.WithContext(ctx).Omit("User").Save(token).Error
+}
+
+// Touch mencatat waktu dan IP terakhir token dipakai
+func (r *apiTokenRepository) Touch(ctx context.Context, id uint, usedAt time.Time, ip string) error {
+	return r.db.WithContext(ctx).Model(&models.APIToken{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": usedAt, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// AuditCheckpointRepository mendefinisikan operasi database untuk model AuditCheckpoint
+type AuditCheckpointRepository interface {
+	FindAll(ctx context.Context) ([]models.AuditCheckpoint, error)
+	FindLast(ctx context.Context) (*models.AuditCheckpoint, error)
+	Create(ctx context.Context, checkpoint *models.AuditCheckpoint) error
+}
+
+// auditCheckpointRepository adalah implementasi AuditCheckpointRepository menggunakan GORM
+type auditCheckpointRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditCheckpointRepository membuat AuditCheckpointRepository berbasis GORM
+func NewAuditCheckpointRepository(db *gorm.DB) AuditCheckpointRepository {
+	return &auditCheckpointRepository{db: db}
+}
+
+// FindAll mengambil semua checkpoint, urut dari yang terlama
+func (r *auditCheckpointRepository) FindAll(ctx context.Context) ([]models.AuditCheckpoint, error) {
+	var checkpoints []models.AuditCheckpoint
+	if err := r.db.WithContext(ctx).Order("id").Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
+
+// FindLast mengambil checkpoint terbaru
+func (r *auditCheckpointRepository) FindLast(ctx context.Context) (*models.AuditCheckpoint, error) {
+	var checkpoint models.AuditCheckpoint
+	err := r.db.WithContext(ctx).Order("id DESC").First(&checkpoint).Error
+	return &checkpoint, translateError(err)
+}
+
+// Create menyimpan checkpoint baru
+func (r *auditCheckpointRepository) Create(ctx context.Context, checkpoint *models.AuditCheckpoint) error {
+	return r.db.WithContext(ctx).Create(checkpoint).Error
+}
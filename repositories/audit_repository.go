@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// AuditFilter adalah filter audit log, field kosong berarti tidak difilter
+type AuditFilter struct {
+	ActorID    *uint
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time // Inklusif
+	To         *time.Time // Eksklusif
+}
+
+// AuditRepository mendefinisikan operasi database untuk model AuditLog.
+// Audit log hanya bisa ditambah, tidak ada operasi update maupun delete.
+type AuditRepository interface {
+	Append(ctx context.Context, log *models.AuditLog) error
+	Head(ctx context.Context) (*models.AuditChainHead, error)
+	CountUpTo(ctx context.Context, id uint) (int64, error)
+	FindPage(ctx context.Context, filter AuditFilter, offset, limit int) ([]models.AuditLog, int64, error)
+	Each(ctx context.Context, filter AuditFilter, batchSize int, fn func(logs []models.AuditLog) error) error
+}
+
+// auditRepository adalah implementasi AuditRepository menggunakan GORM
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository membuat AuditRepository berbasis GORM
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Append menyambung audit log ke ujung rantai hash: PrevHash diisi hash entry terakhir lalu
+// Hash dihitung. Baris ujung rantai dikunci selama transaksi agar penulisan bersamaan tidak bercabang.
+// CreatedAt harus sudah diisi karena ikut di-hash.
+func (r *auditRepository) Append(ctx context.Context, log *models.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		head := models.AuditChainHead{ID: 1}
+		if err := lockForUpdate(tx).FirstOrCreate(&head).Error; err != nil {
+			return err
+		}
+
+		log.PrevHash = head.LastHash
+		log.Hash = log.ComputeHash()
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+
+		head.LastID, head.LastHash = log.ID, log.Hash
+		return tx.Save(&head).Error
+	})
+}
+
+// Head mengambil ujung rantai hash audit log
+func (r *auditRepository) Head(ctx context.Context) (*models.AuditChainHead, error) {
+	head := models.AuditChainHead{ID: 1}
+	err := r.db.WithContext(ctx).FirstOrCreate(&head).Error
+	return &head, err
+}
+
+// CountUpTo menghitung audit log dengan ID <= id
+func (r *auditRepository) CountUpTo(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.AuditLog{}).Where("id <= ?", id).Count(&count).Error
+	return count, err
+}
+
+// FindPage mengambil audit log terbaru lebih dulu beserta jumlah total yang cocok dengan filter
+func (r *auditRepository) FindPage(ctx context.Context, filter AuditFilter, offset, limit int) ([]models.AuditLog, int64, error) {
+	query := filter.apply(r.db.WithContext(ctx).Model(&models.AuditLog{}))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// Each memanggil fn untuk setiap batch audit log yang cocok dengan filter, urut dari yang terlama
+func (r *auditRepository) Each(ctx context.Context, filter AuditFilter, batchSize int, fn func(logs []models.AuditLog) error) error {
+	var logs []models.AuditLog
+	return filter.apply(r.db.WithContext(ctx)).
+		FindInBatches(&logs, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(logs)
+		}).Error
+}
+
+// apply menerapkan AuditFilter ke query
+func (f AuditFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.ActorID != nil {
+		db = db.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.Action != "" {
+		db = db.Where("action = ?", f.Action)
+	}
+	if f.TargetType != "" {
+		db = db.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		db = db.Where("target_id = ?", f.TargetID)
+	}
+	if f.From != nil {
+		db = db.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		db = db.Where("created_at < ?", *f.To)
+	}
+	return db
+}
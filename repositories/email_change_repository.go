@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// EmailChangeRepository mendefinisikan operasi database untuk model EmailChange
+type EmailChangeRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.EmailChange, error)
+	Create(ctx context.Context, change *models.EmailChange) error
+	Update(ctx context.Context, change *models.EmailChange) error
+	DeletePendingByUser(ctx context.Context, userID uint) error
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// emailChangeRepository adalah implementasi EmailChangeRepository menggunakan GORM
+type emailChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailChangeRepository membuat EmailChangeRepository berbasis GORM
+func NewEmailChangeRepository(db *gorm.DB) EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+// FindByID mengambil permintaan ganti email berdasarkan ID
+func (r *emailChangeRepository) FindByID(ctx context.Context, id uint) (*models.EmailChange, error) {
+	var change models.EmailChange
+	err := r.db.WithContext(ctx).First(&change, id).Error
+	return &change, translateError(err)
+}
+
+// Create menyimpan permintaan ganti email baru
+func (r *emailChangeRepository) Create(ctx context.Context, change *models.EmailChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+// Update menyimpan perubahan permintaan ganti email
+func (r *emailChangeRepository) Update(ctx context.Context, change *models.EmailChange) error {
+	return r.db.WithContext(ctx).Omit("User").Save(change).Error
+}
+
+// DeletePendingByUser menghapus permintaan milik user yang belum dikonfirmasi, sehingga link lama tidak berlaku
+func (r *emailChangeRepository) DeletePendingByUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND confirmed_at IS NULL", userID).
+		Delete(&models.EmailChange{}).Error
+}
+
+// DeleteEndedBefore menghapus permintaan yang kadaluwarsa atau sudah dikonfirmasi sebelum cutoff
+func (r *emailChangeRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR confirmed_at < ?", cutoff, cutoff).
+		Delete(&models.EmailChange{})
+	return result.RowsAffected, result.Error
+}
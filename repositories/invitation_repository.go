@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// InvitationRepository mendefinisikan operasi database untuk model Invitation
+type InvitationRepository interface {
+	FindAll(ctx context.Context, status string, now time.Time) ([]models.Invitation, error)
+	FindByID(ctx context.Context, id uint) (*models.Invitation, error)
+	FindPendingByEmail(ctx context.Context, email string, now time.Time) (*models.Invitation, error)
+	Create(ctx context.Context, invitation *models.Invitation) error
+	Update(ctx context.Context, invitation *models.Invitation) error
+	DeleteClosedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// invitationRepository adalah implementasi InvitationRepository menggunakan GORM
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository membuat InvitationRepository berbasis GORM
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+// FindAll mengambil undangan terbaru lebih dulu, status kosong berarti semua status
+func (r *invitationRepository) FindAll(ctx context.Context, status string, now time.Time) ([]models.Invitation, error) {
+	query := r.db.WithContext(ctx).Preload("Role").Order("id DESC")
+	switch status {
+	case models.InvitationPending:
+		query = pendingInvitations(query, now)
+	case models.InvitationAccepted:
+		query = query.Where("accepted_at IS NOT NULL")
+	case models.InvitationRevoked:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NOT NULL")
+	case models.InvitationExpired:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	}
+
+	var invitations []models.Invitation
+	if err := query.Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// FindByID mengambil undangan berdasarkan ID beserta role-nya
+func (r *invitationRepository) FindByID(ctx context.Context, id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.WithContext(ctx).Preload("Role").First(&invitation, id).Error
+	return &invitation, translateError(err)
+}
+
+// FindPendingByEmail mengambil undangan yang masih aktif untuk email tertentu
+func (r *invitationRepository) FindPendingByEmail(ctx context.Context, email string, now time.Time) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := pendingInvitations(r.db.WithContext(ctx), now).Where("email = ?", email).First(&invitation).Error
+	return &invitation, translateError(err)
+}
+
+// Create menyimpan undangan baru
+func (r *invitationRepository) Create(ctx context.Context, invitation *models.Invitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+// Update menyimpan perubahan undangan
+func (r *invitationRepository) Update(ctx context.Context, invitation *models.Invitation) error {
+	return r.db.WithContext(ctx).Omit("Role").Save(invitation).Error
+}
+
+// DeleteClosedBefore menghapus undangan yang tidak diterima dan sudah dicabut atau
+// kadaluwarsa sebelum cutoff. Undangan yang diterima disimpan sebagai riwayat.
+func (r *invitationRepository) DeleteClosedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("accepted_at IS NULL AND (revoked_at < ? OR expires_at < ?)", cutoff, cutoff).
+		Delete(&models.Invitation{})
+	return result.RowsAffected, result.Error
+}
+
+// pendingInvitations memfilter undangan yang belum diterima, belum dicabut dan belum kadaluwarsa
+func pendingInvitations(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// JobFilter adalah filter untuk daftar job, field kosong berarti tidak difilter
+type JobFilter struct {
+	Status string
+	Type   string
+	Limit  int
+}
+
+// JobRepository mendefinisikan operasi database untuk model Job (inspeksi oleh admin).
+// Enqueue dan pengambilan job oleh worker ada di package jobs.
+type JobRepository interface {
+	FindAll(ctx context.Context, filter JobFilter) ([]models.Job, error)
+	FindByID(ctx context.Context, id uint) (*models.Job, error)
+	Update(ctx context.Context, job *models.Job) error
+}
+
+// jobRepository adalah implementasi JobRepository menggunakan GORM
+type jobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository membuat JobRepository berbasis GORM
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+// FindAll mengambil job terbaru lebih dulu
+func (r *jobRepository) FindAll(ctx context.Context, filter JobFilter) ([]models.Job, error) {
+	query := r.db.WithContext(ctx).Order("id DESC")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var jobs []models.Job
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FindByID mengambil job berdasarkan ID
+func (r *jobRepository) FindByID(ctx context.Context, id uint) (*models.Job, error) {
+	var job models.Job
+	err := r.db.WithContext(ctx).First(&job, id).Error
+	return &job, translateError(err)
+}
+
+// Update menyimpan perubahan job
+func (r *jobRepository) Update(ctx context.Context, job *models.Job) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptFilter adalah filter riwayat login, field kosong berarti tidak difilter
+type LoginAttemptFilter struct {
+	UserID  *uint
+	Email   string
+	Success *bool
+}
+
+// LoginAttemptRepository mendefinisikan operasi database untuk model LoginAttempt
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *models.LoginAttempt) error
+	FindPage(ctx context.Context, filter LoginAttemptFilter, offset, limit int) ([]models.LoginAttempt, int64, error)
+	CountFailedSince(ctx context.Context, userID uint, reason string, since time.Time) (int64, error)
+}
+
+// loginAttemptRepository adalah implementasi LoginAttemptRepository menggunakan GORM
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository membuat LoginAttemptRepository berbasis GORM
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// Create menyimpan percobaan login
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+// FindPage mengambil riwayat login terbaru lebih dulu beserta jumlah total yang cocok dengan filter
+func (r *loginAttemptRepository) FindPage(ctx context.Context, filter LoginAttemptFilter, offset, limit int) ([]models.LoginAttempt, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.LoginAttempt{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var attempts []models.LoginAttempt
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&attempts).Error; err != nil {
+		return nil, 0, err
+	}
+	return attempts, total, nil
+}
+
+// CountFailedSince menghitung percobaan login gagal milik user dengan alasan tertentu sejak waktu since
+func (r *loginAttemptRepository) CountFailedSince(ctx context.Context, userID uint, reason string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.LoginAttempt{}).
+		Where("user_id = ? AND success = ? AND reason = ? AND created_at >= ?", userID, false, reason, since).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// LoginCodeRepository mendefinisikan operasi database untuk model LoginCode
+type LoginCodeRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.LoginCode, error)
+	FindLatestByUser(ctx context.Context, userID uint) (*models.LoginCode, error)
+	Create(ctx context.Context, code *models.LoginCode) error
+	AddAttempt(ctx context.Context, code *models.LoginCode, maxAttempts int) (bool, error)
+	MarkUsed(ctx context.Context, code *models.LoginCode, usedAt time.Time) (bool, error)
+	DeletePendingByUser(ctx context.Context, userID uint) error
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// loginCodeRepository adalah implementasi LoginCodeRepository menggunakan GORM
+type loginCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginCodeRepository membuat LoginCodeRepository berbasis GORM
+func NewLoginCodeRepository(db *gorm.DB) LoginCodeRepository {
+	return &loginCodeRepository{db: db}
+}
+
+// FindByID mengambil link atau kode login berdasarkan ID
+func (r *loginCodeRepository) FindByID(ctx context.Context, id uint) (*models.LoginCode, error) {
+	var code models.LoginCode
+	err := r.db.WithContext(ctx).First(&code, id).Error
+	return &code, translateError(err)
+}
+
+// FindLatestByUser mengambil link atau kode login terakhir yang diminta user
+func (r *loginCodeRepository) FindLatestByUser(ctx context.Context, userID uint) (*models.LoginCode, error) {
+	var code models.LoginCode
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").First(&code).Error
+	return &code, translateError(err)
+}
+
+// Create menyimpan link atau kode login baru
+func (r *loginCodeRepository) Create(ctx context.Context, code *models.LoginCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+// AddAttempt menambah jumlah percobaan sebelum kode dicocokkan. Mengembalikan false jika kode
+// sudah dipakai atau batas percobaan sudah habis, termasuk oleh request lain yang berjalan bersamaan.
+func (r *loginCodeRepository) AddAttempt(ctx context.Context, code *models.LoginCode, maxAttempts int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.LoginCode{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ?", code.ID, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	code.Attempts++
+	return true, nil
+}
+
+// MarkUsed menandai link atau kode sudah dipakai. Mengembalikan false jika sudah dipakai request
+// lain lebih dulu, sehingga satu kode tidak bisa menerbitkan dua token.
+func (r *loginCodeRepository) MarkUsed(ctx context.Context, code *models.LoginCode, usedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.LoginCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	code.UsedAt = &usedAt
+	return true, nil
+}
+
+// DeletePendingByUser menghapus link dan kode milik user yang belum dipakai, sehingga yang lama tidak berlaku
+func (r *loginCodeRepository) DeletePendingByUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Delete(&models.LoginCode{}).Error
+}
+
+// DeleteEndedBefore menghapus link dan kode yang kadaluwarsa sebelum cutoff, sudah dipakai maupun belum
+func (r *loginCodeRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&models.LoginCode{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// OAuthClientRepository mendefinisikan operasi database untuk model OAuthClient
+type OAuthClientRepository interface {
+	FindAll(ctx context.Context) ([]models.OAuthClient, error)
+	FindByID(ctx context.Context, id uint) (*models.OAuthClient, error)
+	FindByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error)
+	Create(ctx context.Context, client *models.OAuthClient) error
+	Update(ctx context.Context, client *models.OAuthClient) error
+	Delete(ctx context.Context, client *models.OAuthClient) error
+}
+
+// oauthClientRepository adalah implementasi OAuthClientRepository menggunakan GORM
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientRepository membuat OAuthClientRepository berbasis GORM
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+// FindAll mengambil semua client
+func (r *oauthClientRepository) FindAll(ctx context.Context) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	if err := r.db.WithContext(ctx).Order("id").Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// FindByID mengambil client berdasarkan ID
+func (r *oauthClientRepository) FindByID(ctx context.Context, id uint) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.WithContext(ctx).First(&client, id).Error
+	return &client, translateError(err)
+}
+
+// FindByClientID mengambil client berdasarkan client_id yang dikirim di request OAuth
+func (r *oauthClientRepository) FindByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client).Error
+	return &client, translateError(err)
+}
+
+// Create menyimpan client baru
+func (r *oauthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+// Update menyimpan perubahan client
+func (r *oauthClientRepository) Update(ctx context.Context, client *models.OAuthClient) error {
+	return r.db.WithContext(ctx).Save(client).Error
+}
+
+// Delete menghapus client permanen beserta grant, kode dan persetujuan miliknya
+func (r *oauthClientRepository) Delete(ctx context.Context, client *models.OAuthClient) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.OAuthAuthorizationCode{}, &models.OAuthGrant{}, &models.OAuthConsent{}} {
+			if err := tx.Where("oauth_client_id = ?", client.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(client).Error
+	})
+}
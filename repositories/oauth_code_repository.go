@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// OAuthCodeRepository mendefinisikan operasi database untuk model OAuthAuthorizationCode
+type OAuthCodeRepository interface {
+	FindByHash(ctx context.Context, hash string) (*models.OAuthAuthorizationCode, error)
+	Create(ctx context.Context, code *models.OAuthAuthorizationCode) error
+	MarkUsed(ctx context.Context, code *models.OAuthAuthorizationCode, usedAt time.Time) (bool, error)
+	Update(ctx context.Context, code *models.OAuthAuthorizationCode) error
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// oauthCodeRepository adalah implementasi OAuthCodeRepository menggunakan GORM
+type oauthCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthCodeRepository membuat OAuthCodeRepository berbasis GORM
+func NewOAuthCodeRepository(db *gorm.DB) OAuthCodeRepository {
+	return &oauthCodeRepository{db: db}
+}
+
+// FindByHash mengambil kode berdasarkan hash-nya
+func (r *oauthCodeRepository) FindByHash(ctx context.Context, hash string) (*models.OAuthAuthorizationCode, error) {
+	var code models.OAuthAuthorizationCode
+	err := r.db.WithContext(ctx).Where("code_hash = ?", hash).First(&code).Error
+	return &code, translateError(err)
+}
+
+// Create menyimpan kode baru
+func (r *oauthCodeRepository) Create(ctx context.Context, code *models.OAuthAuthorizationCode) error {
+	return r.db.WithContext(ctx).Omit("Client", "User").Create(code).Error
+}
+
+// MarkUsed menandai kode sudah dipakai. Mengembalikan false jika kode sudah dipakai request
+// lain lebih dulu, sehingga dua request bersamaan tidak bisa menukar kode yang sama.
+func (r *oauthCodeRepository) MarkUsed(ctx context.Context, code *models.OAuthAuthorizationCode, usedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	code.UsedAt = &usedAt
+	return true, nil
+}
+
+// Update menyimpan perubahan kode
+func (r *oauthCodeRepository) Update(ctx context.Context, code *models.OAuthAuthorizationCode) error {
+	return r.db.WithContext(ctx).Omit("Client", "User").Save(code).Error
+}
+
+// DeleteEndedBefore menghapus kode yang kadaluwarsa sebelum cutoff
+func (r *oauthCodeRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&models.OAuthAuthorizationCode{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// OAuthConsentRepository mendefinisikan operasi database untuk model OAuthConsent
+type OAuthConsentRepository interface {
+	Find(ctx context.Context, userID, clientID uint) (*models.OAuthConsent, error)
+	Save(ctx context.Context, consent *models.OAuthConsent) error
+}
+
+// oauthConsentRepository adalah implementasi OAuthConsentRepository menggunakan GORM
+type oauthConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthConsentRepository membuat OAuthConsentRepository berbasis GORM
+func NewOAuthConsentRepository(db *gorm.DB) OAuthConsentRepository {
+	return &oauthConsentRepository{db: db}
+}
+
+// Find mengambil persetujuan user untuk client
+func (r *oauthConsentRepository) Find(ctx context.Context, userID, clientID uint) (*models.OAuthConsent, error) {
+	var consent models.OAuthConsent
+	err := r.db.WithContext(ctx).Where("user_id = ? AND oauth_client_id = ?", userID, clientID).First(&consent).Error
+	return &consent, translateError(err)
+}
+
+// Save membuat atau memperbarui persetujuan
+func (r *oauthConsentRepository) Save(ctx context.Context, consent *models.OAuthConsent) error {
+	return r.db.WithContext(ctx).Omit("Client", "User").Save(consent).Error
+}
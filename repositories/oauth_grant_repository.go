@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// OAuthGrantRepository mendefinisikan operasi database untuk model OAuthGrant
+type OAuthGrantRepository interface {
+	FindByID(ctx context.Context, id string) (*models.OAuthGrant, error)
+	FindByRefreshHash(ctx context.Context, hash string) (*models.OAuthGrant, error)
+	FindByPreviousRefreshHash(ctx context.Context, hash string) (*models.OAuthGrant, error)
+	FindActiveByClient(ctx context.Context, clientID uint, now time.Time) ([]models.OAuthGrant, error)
+	Create(ctx context.Context, grant *models.OAuthGrant) error
+	Update(ctx context.Context, grant *models.OAuthGrant) error
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// oauthGrantRepository adalah implementasi OAuthGrantRepository menggunakan GORM
+type oauthGrantRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthGrantRepository membuat OAuthGrantRepository berbasis GORM
+func NewOAuthGrantRepository(db *gorm.DB) OAuthGrantRepository {
+	return &oauthGrantRepository{db: db}
+}
+
+// FindByID mengambil grant berdasarkan ID
+func (r *oauthGrantRepository) FindByID(ctx context.Context, id string) (*models.OAuthGrant, error) {
+	var grant models.OAuthGrant
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&grant).Error
+	return &grant, translateError(err)
+}
+
+// FindByRefreshHash mengambil grant berdasarkan hash refresh token aktifnya
+func (r *oauthGrantRepository) FindByRefreshHash(ctx context.Context, hash string) (*models.OAuthGrant, error) {
+	var grant models.OAuthGrant
+	err := r.db.WithContext(ctx).Where("refresh_token_hash = ? AND refresh_token_hash <> ''", hash).First(&grant).Error
+	return &grant, translateError(err)
+}
+
+// FindByPreviousRefreshHash mengambil grant berdasarkan hash refresh token yang sudah dirotasi
+func (r *oauthGrantRepository) FindByPreviousRefreshHash(ctx context.Context, hash string) (*models.OAuthGrant, error) {
+	var grant models.OAuthGrant
+	err := r.db.WithContext(ctx).Where("previous_refresh_hash = ? AND previous_refresh_hash <> ''", hash).First(&grant).Error
+	return &grant, translateError(err)
+}
+
+// FindActiveByClient mengambil grant aktif milik client
+func (r *oauthGrantRepository) FindActiveByClient(ctx context.Context, clientID uint, now time.Time) ([]models.OAuthGrant, error) {
+	var grants []models.OAuthGrant
+	err := r.db.WithContext(ctx).
+		Where("oauth_client_id = ? AND revoked_at IS NULL AND expires_at > ?", clientID, now).
+		Find(&grants).Error
+	return grants, err
+}
+
+// Create menyimpan grant baru
+func (r *oauthGrantRepository) Create(ctx context.Context, grant *models.OAuthGrant) error {
+	return r.db.WithContext(ctx).Omit("Client", "User").Create(grant).Error
+}
+
+// Update menyimpan perubahan grant
+func (r *oauthGrantRepository) Update(ctx context.Context, grant *models.OAuthGrant) error {
+	return r.db.WithContext(ctx).Omit("Client", "User").Save(grant).Error
+}
+
+// DeleteEndedBefore menghapus grant yang kadaluwarsa atau dicabut sebelum cutoff
+func (r *oauthGrantRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).
+		Delete(&models.OAuthGrant{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// PasswordHistoryRepository mendefinisikan operasi database untuk riwayat password
+type PasswordHistoryRepository interface {
+	Create(ctx context.Context, entry *models.PasswordHistory) error
+	RecentHashes(ctx context.Context, userID uint, limit int) ([]string, error)
+	Prune(ctx context.Context, userID uint, keep int) error
+}
+
+// passwordHistoryRepository adalah implementasi PasswordHistoryRepository menggunakan GORM
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository membuat PasswordHistoryRepository berbasis GORM
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+// Create menyimpan hash password baru ke riwayat
+func (r *passwordHistoryRepository) Create(ctx context.Context, entry *models.PasswordHistory) error {
+	return r.db.WithContext(ctx).Omit("User").Create(entry).Error
+}
+
+// RecentHashes mengambil hash dari limit password terakhir milik user, terbaru lebih dulu
+func (r *passwordHistoryRepository) RecentHashes(ctx context.Context, userID uint, limit int) ([]string, error) {
+	var hashes []string
+	err := r.db.WithContext(ctx).Model(&models.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("hash", &hashes).Error
+	return hashes, err
+}
+
+// Prune menghapus riwayat password user selain keep entry terbaru
+func (r *passwordHistoryRepository) Prune(ctx context.Context, userID uint, keep int) error {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(keep).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if len(ids) > 0 {
+		query = query.Where("id NOT IN ?", ids)
+	}
+	return query.Delete(&models.PasswordHistory{}).Error
+}
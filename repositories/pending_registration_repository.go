@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// PendingRegistrationRepository mendefinisikan operasi database untuk model PendingRegistration
+type PendingRegistrationRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.PendingRegistration, error)
+	Create(ctx context.Context, registration *models.PendingRegistration) error
+	Update(ctx context.Context, registration *models.PendingRegistration) error
+	DeletePendingByEmail(ctx context.Context, email string) error
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// pendingRegistrationRepository adalah implementasi PendingRegistrationRepository menggunakan GORM
+type pendingRegistrationRepository struct {
+	db *gorm.DB
+}
+
+// NewPendingRegistrationRepository membuat PendingRegistrationRepository berbasis GORM
+func NewPendingRegistrationRepository(db *gorm.DB) PendingRegistrationRepository {
+	return &pendingRegistrationRepository{db: db}
+}
+
+// FindByID mengambil registrasi yang menunggu konfirmasi berdasarkan ID
+func (r *pendingRegistrationRepository) FindByID(ctx context.Context, id uint) (*models.PendingRegistration, error) {
+	var registration models.PendingRegistration
+	err := r.db.WithContext(ctx).First(&registration, id).Error
+	return &registration, translateError(err)
+}
+
+// Create menyimpan registrasi baru yang menunggu konfirmasi
+func (r *pendingRegistrationRepository) Create(ctx context.Context, registration *models.PendingRegistration) error {
+	return r.db.WithContext(ctx).Create(registration).Error
+}
+
+// Update menyimpan perubahan registrasi
+func (r *pendingRegistrationRepository) Update(ctx context.Context, registration *models.PendingRegistration) error {
+	return r.db.WithContext(ctx).Save(registration).Error
+}
+
+// DeletePendingByEmail menghapus registrasi untuk email yang belum dikonfirmasi, sehingga link lama tidak berlaku
+func (r *pendingRegistrationRepository) DeletePendingByEmail(ctx context.Context, email string) error {
+	return r.db.WithContext(ctx).
+		Where("email = ? AND confirmed_at IS NULL", email).
+		Delete(&models.PendingRegistration{}).Error
+}
+
+// DeleteEndedBefore menghapus registrasi yang kadaluwarsa atau sudah dikonfirmasi sebelum cutoff
+func (r *pendingRegistrationRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR confirmed_at < ?", cutoff, cutoff).
+		Delete(&models.PendingRegistration{})
+	return result.RowsAffected, result.Error
+}
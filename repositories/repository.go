@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound dikembalikan ketika data yang dicari tidak ada di database
+var ErrNotFound = errors.New("record not found")
+
+// DeletedScope menentukan apakah data yang sudah di-soft delete ikut diambil
+type DeletedScope int
+
+const (
+	// ExcludeDeleted hanya mengambil data yang belum dihapus (default GORM)
+	ExcludeDeleted DeletedScope = iota
+	// WithDeleted mengambil data yang belum maupun sudah dihapus
+	WithDeleted
+	// OnlyDeleted hanya mengambil data yang sudah dihapus
+	OnlyDeleted
+)
+
+// apply menerapkan DeletedScope ke query
+func (s DeletedScope) apply(db *gorm.DB) *gorm.DB {
+	switch s {
+	case WithDeleted:
+		return db.Unscoped()
+	case OnlyDeleted:
+		return db.Unscoped().Where("deleted_at IS NOT NULL")
+	default:
+		return db
+	}
+}
+
+// purgeDeletedBefore menghapus permanen data yang di-soft delete sebelum cutoff
+func purgeDeletedBefore(db *gorm.DB, model interface{}, cutoff time.Time) (int64, error) {
+	result := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(model)
+	return result.RowsAffected, result.Error
+}
+
+// lockForUpdate menambahkan SELECT ... FOR UPDATE pada query. SQLite (dipakai saat test)
+// tidak mendukung klausa ini dan transaksinya sudah serial, jadi klausa hanya dipakai di database lain.
+func lockForUpdate(db *gorm.DB) *gorm.DB {
+	if db.Dialector.Name() == "sqlite" {
+		return db
+	}
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// translateError mengubah error GORM menjadi error milik package repositories
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// RoleFilter adalah filter daftar role, field kosong berarti tidak difilter
+type RoleFilter struct {
+	ID   *uint
+	Name string // Dicocokkan tanpa membedakan huruf besar kecil
+}
+
+// RoleRepository mendefinisikan operasi database untuk model Role
+type RoleRepository interface {
+	FindAll(ctx context.Context, scope DeletedScope) ([]models.Role, error)
+	FindPage(ctx context.Context, filter RoleFilter, offset, limit int) ([]models.Role, int64, error)
+	FindByID(ctx context.Context, id uint) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, role *models.Role) error
+	Purge(ctx context.Context, role *models.Role) error
+	CountUsers(ctx context.Context, id uint) (int64, error)
+	ReassignAndDelete(ctx context.Context, role *models.Role, toID uint) (int64, error)
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// roleRepository adalah implementasi RoleRepository menggunakan GORM
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository membuat RoleRepository berbasis GORM
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// FindAll mengambil semua role sesuai DeletedScope
+func (r *roleRepository) FindAll(ctx context.Context, scope DeletedScope) ([]models.Role, error) {
+	var roles []models.Role
+	if err := scope.apply(r.db.WithContext(ctx)).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// FindPage mengambil role yang belum dihapus, urut dari ID terkecil, dan jumlah total yang cocok dengan filter
+func (r *roleRepository) FindPage(ctx context.Context, filter RoleFilter, offset, limit int) ([]models.Role, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Role{})
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.Name != "" {
+		query = query.Where("LOWER(name) = LOWER(?)", filter.Name)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var roles []models.Role
+	if err := query.Order("id").Offset(offset).Limit(limit).Find(&roles).Error; err != nil {
+		return nil, 0, err
+	}
+	return roles, total, nil
+}
+
+// FindByID mengambil role yang belum dihapus berdasarkan ID
+func (r *roleRepository) FindByID(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).First(&role, id).Error
+	return &role, translateError(err)
+}
+
+// FindByName mengambil role yang belum dihapus berdasarkan nama
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error
+	return &role, translateError(err)
+}
+
+// Create menyimpan role baru ke database
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+// Update menyimpan perubahan data role
+func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Save(role).Error
+}
+
+// Delete melakukan soft delete (kolom deleted_at diisi oleh GORM)
+func (r *roleRepository) Delete(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Delete(role).Error
+}
+
+// Purge menghapus role secara permanen
+func (r *roleRepository) Purge(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(role).Error
+}
+
+// CountUsers menghitung user aktif yang memakai role
+func (r *roleRepository) CountUsers(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("id_role = ?", id).Count(&count).Error
+	return count, err
+}
+
+// ReassignAndDelete memindahkan semua user (termasuk yang sudah dihapus) ke role toID,
+// lalu melakukan soft delete pada role dalam satu transaksi
+func (r *roleRepository) ReassignAndDelete(ctx context.Context, role *models.Role, toID uint) (int64, error) {
+	var moved int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.User{}).Where("id_role = ?", role.ID).Update("id_role", toID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+		return tx.Delete(role).Error
+	})
+	return moved, err
+}
+
+// PurgeDeletedBefore menghapus permanen role yang di-soft delete sebelum cutoff.
+// Role yang masih dipakai user (termasuk user yang sudah dihapus) tidak ikut dihapus.
+func (r *roleRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	db := r.db.WithContext(ctx).Where("NOT EXISTS (SELECT 1 FROM users WHERE users.id_role = roles.id)")
+	return purgeDeletedBefore(db, &models.Role{}, cutoff)
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// SessionRepository mendefinisikan operasi database untuk model Session
+type SessionRepository interface {
+	FindActiveByUser(ctx context.Context, userID uint, now time.Time) ([]models.Session, error)
+	FindByID(ctx context.Context, id string) (*models.Session, error)
+	Create(ctx context.Context, session *models.Session) error
+	Update(ctx context.Context, session *models.Session) error
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// sessionRepository adalah implementasi SessionRepository menggunakan GORM
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository membuat SessionRepository berbasis GORM
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// FindActiveByUser mengambil session user yang belum dicabut dan belum kadaluwarsa, terbaru lebih dulu
+func (r *sessionRepository) FindActiveByUser(ctx context.Context, userID uint, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// FindByID mengambil session berdasarkan ID
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error
+	return &session, translateError(err)
+}
+
+// Create menyimpan session baru
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// Update menyimpan perubahan session
+func (r *sessionRepository) Update(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+// DeleteEndedBefore menghapus session yang kadaluwarsa atau dicabut sebelum cutoff
+func (r *sessionRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).
+		Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
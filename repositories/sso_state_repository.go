@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// SSOStateRepository mendefinisikan operasi database untuk model SSOLoginState
+type SSOStateRepository interface {
+	FindByHash(ctx context.Context, hash string) (*models.SSOLoginState, error)
+	Create(ctx context.Context, state *models.SSOLoginState) error
+	MarkUsed(ctx context.Context, state *models.SSOLoginState, usedAt time.Time) (bool, error)
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ssoStateRepository adalah implementasi SSOStateRepository menggunakan GORM
+type ssoStateRepository struct {
+	db *gorm.DB
+}
+
+// NewSSOStateRepository membuat SSOStateRepository berbasis GORM
+func NewSSOStateRepository(db *gorm.DB) SSOStateRepository {
+	return &ssoStateRepository{db: db}
+}
+
+// FindByHash mengambil percobaan login berdasarkan hash state
+func (r *ssoStateRepository) FindByHash(ctx context.Context, hash string) (*models.SSOLoginState, error) {
+	var state models.SSOLoginState
+	err := r.db.WithContext(ctx).Where("state_hash = ?", hash).First(&state).Error
+	return &state, translateError(err)
+}
+
+// Create menyimpan percobaan login baru
+func (r *ssoStateRepository) Create(ctx context.Context, state *models.SSOLoginState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// MarkUsed menandai state sudah dipakai. Mengembalikan false jika state sudah dipakai request
+// lain lebih dulu, sehingga callback yang sama tidak bisa diproses dua kali.
+func (r *ssoStateRepository) MarkUsed(ctx context.Context, state *models.SSOLoginState, usedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.SSOLoginState{}).
+		Where("id = ? AND used_at IS NULL", state.ID).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	state.UsedAt = &usedAt
+	return true, nil
+}
+
+// DeleteEndedBefore menghapus percobaan login yang kadaluwarsa sebelum cutoff
+func (r *ssoStateRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&models.SSOLoginState{})
+	return result.RowsAffected, result.Error
+}
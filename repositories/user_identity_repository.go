@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository mendefinisikan operasi database untuk model UserIdentity
+type UserIdentityRepository interface {
+	FindByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error)
+	FindByID(ctx context.Context, id uint) (*models.UserIdentity, error)
+	FindBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	FindByProvider(ctx context.Context, provider string) ([]models.UserIdentity, error)
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	Update(ctx context.Context, identity *models.UserIdentity) error
+	Delete(ctx context.Context, identity *models.UserIdentity) error
+}
+
+// userIdentityRepository adalah implementasi UserIdentityRepository menggunakan GORM
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository membuat UserIdentityRepository berbasis GORM
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+// FindByUser mengambil semua identitas yang ditautkan ke user
+func (r *userIdentityRepository) FindByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+// FindByID mengambil identitas berdasarkan ID
+func (r *userIdentityRepository) FindByID(ctx context.Context, id uint) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).First(&identity, id).Error
+	return &identity, translateError(err)
+}
+
+// FindBySubject mengambil identitas berdasarkan provider dan claim sub
+func (r *userIdentityRepository) FindBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	return &identity, translateError(err)
+}
+
+// FindByProvider mengambil semua identitas dari satu provider
+func (r *userIdentityRepository) FindByProvider(ctx context.Context, provider string) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ?", provider).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+// Create menyimpan identitas baru
+func (r *userIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Omit("User").Create(identity).Error
+}
+
+// Update menyimpan perubahan identitas
+func (r *userIdentityRepository) Update(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Omit("User").Save(identity).Error
+}
+
+// Delete melepas identitas dari user
+func (r *userIdentityRepository) Delete(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Delete(identity).Error
+}
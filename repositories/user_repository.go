@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/models"
+	"gorm.io/gorm"
+)
+
+// UserFilter adalah filter daftar user, field kosong berarti tidak difilter
+type UserFilter struct {
+	ID     *uint
+	Email  string // Dicocokkan tanpa membedakan huruf besar kecil
+	RoleID *uint
+	// User yang punya identitas eksternal tertentu (provider dan subject UserIdentity)
+	IdentityProvider string
+	IdentitySubject  string
+}
+
+// UserRepository mendefinisikan operasi database untuk model User
+type UserRepository interface {
+	FindAll(ctx context.Context, scope DeletedScope) ([]models.User, error)
+	FindPage(ctx context.Context, filter UserFilter, offset, limit int) ([]models.User, int64, error)
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	FindDeletedByID(ctx context.Context, id uint) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	EmailExists(ctx context.Context, email string, excludeID uint) (bool, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, user *models.User) error
+	Restore(ctx context.Context, user *models.User) error
+	Purge(ctx context.Context, user *models.User) error
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// userRepository adalah implementasi UserRepository menggunakan GORM
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository membuat UserRepository berbasis GORM
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// FindAll mengambil semua user sesuai DeletedScope
+func (r *userRepository) FindAll(ctx context.Context, scope DeletedScope) ([]models.User, error) {
+	var users []models.User
+	if err := scope.apply(r.db.WithContext(ctx)).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindPage mengambil user yang belum dihapus beserta role-nya, urut dari ID terkecil, dan jumlah
+// total yang cocok dengan filter. limit negatif berarti semua user.
+func (r *userRepository) FindPage(ctx context.Context, filter UserFilter, offset, limit int) ([]models.User, int64, error) {
+	db := r.db.WithContext(ctx)
+	query := db.Model(&models.User{})
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.Email != "" {
+		query = query.Where("LOWER(email) = LOWER(?)", filter.Email)
+	}
+	if filter.RoleID != nil {
+		query = query.Where("id_role = ?", *filter.RoleID)
+	}
+	if filter.IdentityProvider != "" {
+		identities := db.Model(&models.UserIdentity{}).Select("user_id").
+			Where("provider = ? AND subject = ?", filter.IdentityProvider, filter.IdentitySubject)
+		query = query.Where("id IN (?)", identities)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	if err := query.Preload("Role").Order("id").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// FindByID mengambil user yang belum dihapus berdasarkan ID beserta role-nya
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Preload("Role").First(&user, id).Error
+	return &user, translateError(err)
+}
+
+// FindDeletedByID mengambil user yang sudah di-soft delete berdasarkan ID
+func (r *userRepository) FindDeletedByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	err := OnlyDeleted.apply(r.db.WithContext(ctx)).First(&user, id).Error
+	return &user, translateError(err)
+}
+
+// FindByEmail mengambil user yang belum dihapus berdasarkan email beserta role-nya
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Preload("Role").Where("email = ?", email).First(&user).Error
+	return &user, translateError(err)
+}
+
+// EmailExists mengecek apakah email sudah dipakai user aktif lain (excludeID = 0 berarti cek semua user)
+func (r *userRepository) EmailExists(ctx context.Context, email string, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&models.User{}).Where("email = ?", email)
+	if excludeID != 0 {
+		query = query.Where("id != ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Create menyimpan user baru ke database
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+// Update menyimpan perubahan data user
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Omit("Role").Save(user).Error
+}
+
+// Delete melakukan soft delete (kolom deleted_at diisi oleh GORM)
+func (r *userRepository) Delete(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Delete(user).Error
+}
+
+// Restore membatalkan soft delete
+func (r *userRepository) Restore(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Unscoped().Model(user).Update("deleted_at", nil).Error
+}
+
+// Purge menghapus user secara permanen
+func (r *userRepository) Purge(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(user).Error
+}
+
+// PurgeDeletedBefore menghapus permanen user yang di-soft delete sebelum cutoff
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return purgeDeletedBefore(r.db.WithContext(ctx), &models.User{}, cutoff)
+}
@@ -3,19 +3,92 @@ package routes
 import (
 	"golang-starter-kit/controllers" // Import package controllers untuk mengakses fungsi-fungsi controller
 	"golang-starter-kit/middleware"  // Import package middleware untuk mengakses middleware JWT
+	"golang-starter-kit/models"      // Import package models untuk nama scope token
 	"github.com/gin-gonic/gin" 	     // Import framework Gin untuk routing dan handling HTTP requests
 )
 
 func SetupRoutes() *gin.Engine {
 	r := gin.Default()
+	r.Use(middleware.RequestContext())
+
+	// OAuth 2.0 authorization server (format request dan response mengikuti RFC, bukan APIResponse)
+	oauth := r.Group("/oauth")
+	{
+		oauth.GET("/authorize", controllers.OAuthAuthorize) // Diteruskan ke halaman persetujuan di frontend
+		oauth.POST("/token", controllers.OAuthToken)
+		oauth.POST("/revoke", controllers.OAuthRevoke)
+		oauth.POST("/introspect", controllers.OAuthIntrospect)
+
+		// OpenID Connect
+		oauth.GET("/jwks", controllers.OAuthJWKS)
+		oauth.GET("/userinfo", middleware.JWTAuth(), controllers.OAuthUserInfo)
+		oauth.POST("/userinfo", middleware.JWTAuth(), controllers.OAuthUserInfo)
+	}
+	r.GET("/.well-known/openid-configuration", controllers.OpenIDConfiguration)
+
+	// SCIM 2.0 provisioning dari identity provider (format mengikuti RFC 7644, bukan APIResponse).
+	// Client memakai personal access token admin dengan scope scim.
+	scim := r.Group("/scim/v2", middleware.JWTAuth(), middleware.RequireScope(models.ScopeSCIM), middleware.AdminOnly())
+	{
+		scim.GET("/ServiceProviderConfig", controllers.GetSCIMServiceProviderConfig)
+		scim.GET("/ResourceTypes", controllers.GetSCIMResourceTypes)
+		scim.GET("/ResourceTypes/:id", controllers.GetSCIMResourceType)
+		scim.GET("/Schemas", controllers.GetSCIMSchemas)
+		scim.GET("/Schemas/:id", controllers.GetSCIMSchema)
+		scim.GET("/Users", controllers.GetSCIMUsers)
+		scim.POST("/Users", controllers.CreateSCIMUser)
+		scim.GET("/Users/:id", controllers.GetSCIMUser)
+		scim.PUT("/Users/:id", controllers.ReplaceSCIMUser)
+		scim.PATCH("/Users/:id", controllers.PatchSCIMUser)
+		scim.DELETE("/Users/:id", controllers.DeleteSCIMUser)
+		scim.GET("/Groups", controllers.GetSCIMGroups)
+		scim.POST("/Groups", controllers.CreateSCIMGroup)
+		scim.GET("/Groups/:id", controllers.GetSCIMGroup)
+		scim.PUT("/Groups/:id", controllers.ReplaceSCIMGroup)
+		scim.PATCH("/Groups/:id", controllers.PatchSCIMGroup)
+		scim.DELETE("/Groups/:id", controllers.DeleteSCIMGroup)
+	}
 
 	api := r.Group("/api")
 	{
 		// Public routes
 		// Auth
-		api.POST("/register", controllers.Register)
+		api.POST("/register", middleware.OptionalJWTAuth(), middleware.RequireScope(models.ScopeUsersWrite), controllers.Register)
+		api.POST("/register/confirm", controllers.ConfirmRegistration)
 		api.POST("/login", controllers.Login)
-		api.POST("/logout", middleware.JWTAuth(), controllers.Logout)
+		api.POST("/login/passwordless", controllers.RequestPasswordlessLogin)
+		api.POST("/login/passwordless/link", controllers.LoginWithMagicLink)
+		api.POST("/login/passwordless/code", controllers.LoginWithCode)
+		api.POST("/logout", middleware.JWTAuth(), middleware.RequireSession(), controllers.Logout)
+
+		// Login lewat identity provider eksternal (OpenID Connect)
+		api.GET("/sso/providers", controllers.GetSSOProviders)
+		api.POST("/sso/:provider/start", controllers.StartSSOLogin)
+		api.POST("/sso/:provider/callback", controllers.CompleteSSOLogin)
+
+		// Akun user yang sedang login
+		api.POST("/me/email/confirm", controllers.ConfirmMyEmail) // Dibuka dari link email, tanpa login
+		// Personal access token tidak bisa dipakai untuk mengelola akun (termasuk token itu sendiri)
+		me := api.Group("/me", middleware.JWTAuth(), middleware.RequireSession())
+		{
+			me.POST("/password", controllers.ChangeMyPassword)
+			me.POST("/email", controllers.ChangeMyEmail)
+			me.GET("/sessions", controllers.GetMySessions)
+			me.DELETE("/sessions/:id", controllers.RevokeMySession)
+			me.POST("/sessions/revoke-others", controllers.RevokeMyOtherSessions)
+			me.GET("/logins", controllers.GetMyLogins)
+			me.GET("/tokens", controllers.GetMyTokens)
+			me.POST("/tokens", controllers.CreateMyToken)
+			me.DELETE("/tokens/:id", controllers.RevokeMyToken)
+			me.GET("/identities", controllers.GetMyIdentities)
+			me.POST("/identities/:provider/start", controllers.StartLinkMyIdentity)
+			me.POST("/identities/:provider/callback", controllers.CompleteLinkMyIdentity)
+			me.DELETE("/identities/:id", controllers.UnlinkMyIdentity)
+		}
+
+		// Halaman persetujuan OAuth (hanya dari login biasa)
+		api.GET("/oauth/authorize", middleware.JWTAuth(), middleware.RequireSession(), controllers.GetOAuthAuthorization)
+		api.POST("/oauth/authorize", middleware.JWTAuth(), middleware.RequireSession(), controllers.ApproveOAuthAuthorization)
 
 		// Secret
 		secret := api.Group("/secret")
@@ -28,21 +101,53 @@ func SetupRoutes() *gin.Engine {
 		// User
 		user := api.Group("/user")
 		{
-			user.GET("/", middleware.JWTAuth(), controllers.GetUsers)
-			user.GET("/:id", middleware.JWTAuth(), controllers.GetUserByID)
-			user.POST("/", middleware.JWTAuth(), controllers.CreateUser)
-			user.PUT("/:id", middleware.JWTAuth(), controllers.UpdateUser)
-			user.DELETE("/:id", middleware.JWTAuth(), controllers.DeleteUser)
+			user.GET("/", middleware.JWTAuth(), middleware.RequireScope(models.ScopeUsersRead), controllers.GetUsers)
+			user.GET("/:id", middleware.JWTAuth(), middleware.RequireScope(models.ScopeUsersRead), controllers.GetUserByID)
+			user.POST("/", middleware.JWTAuth(), middleware.RequireScope(models.ScopeUsersWrite), controllers.CreateUser)
+			user.PUT("/:id", middleware.JWTAuth(), middleware.RequireScope(models.ScopeUsersWrite), controllers.UpdateUser)
+			user.DELETE("/:id", middleware.JWTAuth(), middleware.RequireScope(models.ScopeUsersWrite), controllers.DeleteUser)
+			user.POST("/:id/restore", middleware.JWTAuth(), middleware.RequireScope(models.ScopeUsersWrite), middleware.AdminOnly(), controllers.RestoreUser)
+			user.DELETE("/:id/purge", middleware.JWTAuth(), middleware.RequireScope(models.ScopeUsersWrite), middleware.AdminOnly(), controllers.PurgeUser)
+		}
+
+		// Invitation
+		invitation := api.Group("/invitation")
+		{
+			invitation.POST("/accept", controllers.AcceptInvitation)
+			invitation.GET("/", middleware.JWTAuth(), middleware.RequireScope(models.ScopeInvitations), middleware.AdminOnly(), controllers.GetInvitations)
+			invitation.POST("/", middleware.JWTAuth(), middleware.RequireScope(models.ScopeInvitations), middleware.AdminOnly(), controllers.CreateInvitation)
+			invitation.POST("/:id/resend", middleware.JWTAuth(), middleware.RequireScope(models.ScopeInvitations), middleware.AdminOnly(), controllers.ResendInvitation)
+			invitation.DELETE("/:id", middleware.JWTAuth(), middleware.RequireScope(models.ScopeInvitations), middleware.AdminOnly(), controllers.RevokeInvitation)
+		}
+
+		// Admin
+		admin := api.Group("/admin", middleware.JWTAuth(), middleware.RequireScope(models.ScopeAdmin), middleware.AdminOnly())
+		{
+			admin.GET("/jobs", controllers.GetJobs)
+			admin.GET("/jobs/:id", controllers.GetJobByID)
+			admin.POST("/jobs/:id/retry", controllers.RetryJob)
+			admin.GET("/users/:id/sessions", controllers.GetUserSessions)
+			admin.DELETE("/users/:id/sessions/:sid", controllers.RevokeUserSession)
+			admin.GET("/logins", controllers.GetLoginAttempts)
+			admin.GET("/audit", controllers.GetAuditLogs)
+			admin.GET("/audit/export", controllers.ExportAuditLogs)
+			admin.GET("/audit/verify", controllers.VerifyAuditLogs)
+			admin.GET("/audit/checkpoints", controllers.GetAuditCheckpoints)
+			admin.GET("/audit/checkpoints/export", controllers.ExportAuditCheckpoints)
+			admin.GET("/oauth/clients", controllers.GetOAuthClients)
+			admin.POST("/oauth/clients", controllers.CreateOAuthClient)
+			admin.DELETE("/oauth/clients/:id", controllers.DeleteOAuthClient)
+			admin.POST("/ldap/sync", controllers.SyncLDAP)
 		}
 
 		// Role
 		role := api.Group("/role")
 		{
-			role.GET("/", middleware.JWTAuth(), controllers.GetRoles)
-			role.GET("/:id", middleware.JWTAuth(), controllers.GetRoleByID)
-			role.POST("/", middleware.JWTAuth(), controllers.CreateRole)
-			role.PUT("/:id", middleware.JWTAuth(), controllers.UpdateRole)
-			role.DELETE("/:id", middleware.JWTAuth(), controllers.DeleteRole)
+			role.GET("/", middleware.JWTAuth(), middleware.RequireScope(models.ScopeRolesRead), controllers.GetRoles)
+			role.GET("/:id", middleware.JWTAuth(), middleware.RequireScope(models.ScopeRolesRead), controllers.GetRoleByID)
+			role.POST("/", middleware.JWTAuth(), middleware.RequireScope(models.ScopeRolesWrite), controllers.CreateRole)
+			role.PUT("/:id", middleware.JWTAuth(), middleware.RequireScope(models.ScopeRolesWrite), controllers.UpdateRole)
+			role.DELETE("/:id", middleware.JWTAuth(), middleware.RequireScope(models.ScopeRolesWrite), controllers.DeleteRole)
 		}
 	}
 
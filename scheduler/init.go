@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/services"
+	"golang-starter-kit/utils"
+	"gorm.io/gorm"
+)
+
+// InitScheduler membuat Scheduler dan mendaftarkan task pemeliharaan bawaan.
+// Jadwal setiap task diatur lewat environment variable, nilai "off" berarti task dimatikan.
+// users, sessions dan audit adalah service bersama dari InitController agar task memakai
+// password policy, pencabutan session dan audit log yang sama dengan request HTTP.
+func InitScheduler(db *gorm.DB, users *services.UserService, sessions *services.SessionService, audit *services.AuditService) (*Scheduler, error) {
+	s := New(db, Config{
+		TickInterval: time.Duration(config.GetEnvInt("SCHEDULER_TICK_SECONDS", 30)) * time.Second,
+		LeaseTTL:     time.Duration(config.GetEnvInt("SCHEDULER_LEASE_SECONDS", 600)) * time.Second,
+	})
+
+	// Hapus token kadaluwarsa dari blacklist agar blacklist.json tidak terus membesar.
+	// Blacklist disimpan per proses sehingga task ini dijalankan di setiap replika.
+	err := s.registerLocalFromEnv("blacklist.compact", "SCHEDULE_BLACKLIST_COMPACT", "*/15 * * * *", func(ctx context.Context) error {
+		if removed := utils.CompactBlacklist(); removed > 0 {
+			log.Printf("[scheduler] blacklist: %d token kadaluwarsa dihapus", removed)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Hapus undangan, session, permintaan ganti email, registrasi, token OAuth, percobaan login SSO dan kode login tanpa password yang sudah kadaluwarsa atau dicabut
+	cleanup := services.NewCleanupService(
+		repositories.NewInvitationRepository(db),
+		repositories.NewSessionRepository(db),
+		repositories.NewEmailChangeRepository(db),
+		repositories.NewPendingRegistrationRepository(db),
+		repositories.NewOAuthGrantRepository(db),
+		repositories.NewOAuthCodeRepository(db),
+		repositories.NewSSOStateRepository(db),
+		repositories.NewLoginCodeRepository(db),
+		time.Duration(config.GetEnvInt("TOKEN_CLEANUP_KEEP_DAYS", 30))*24*time.Hour,
+	)
+	err = s.registerFromEnv("tokens.cleanup", "SCHEDULE_TOKEN_CLEANUP", "0 3 * * *", func(ctx context.Context) error {
+		result, err := cleanup.Run(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("[scheduler] token cleanup: %d undangan, %d session, %d ganti email, %d registrasi, %d grant OAuth, %d kode OAuth, %d login SSO, %d kode login dihapus",
+			result.Invitations, result.Sessions, result.EmailChanges, result.Registrations, result.OAuthGrants, result.OAuthCodes, result.SSOStates, result.LoginCodes)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Hapus permanen data soft delete yang melewati masa retensi
+	if days := config.GetEnvInt("SOFT_DELETE_RETENTION_DAYS", 0); days > 0 {
+		retention := services.NewRetentionService(
+			repositories.NewUserRepository(db),
+			repositories.NewRoleRepository(db),
+			time.Duration(days)*24*time.Hour,
+		)
+		err = s.registerFromEnv("soft_delete.retention", "SCHEDULE_SOFT_DELETE_RETENTION", "@hourly", func(ctx context.Context) error {
+			result, err := retention.Run(ctx)
+			if err != nil {
+				return err
+			}
+			log.Printf("[scheduler] retention: %d user, %d role dihapus permanen", result.Users, result.Roles)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Tanda tangani ujung rantai audit log secara berkala jika AUDIT_SIGNING_KEY diisi
+	if audit.SigningEnabled() {
+		err = s.registerFromEnv("audit.checkpoint", "SCHEDULE_AUDIT_CHECKPOINT", "@hourly", func(ctx context.Context) error {
+			checkpoint, err := audit.Checkpoint(ctx)
+			if err != nil {
+				return err
+			}
+			if checkpoint != nil {
+				log.Printf("[scheduler] audit checkpoint #%d sampai audit log #%d", checkpoint.ID, checkpoint.LastID)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Sinkronkan user dari directory LDAP jika LDAP_URL diisi
+	ldapConfig, err := config.LoadLDAPConfig()
+	if err != nil {
+		return nil, err
+	}
+	if ldapConfig.Enabled() {
+		ldap := services.NewLDAPService(
+			repositories.NewUserIdentityRepository(db),
+			repositories.NewUserRepository(db),
+			repositories.NewRoleRepository(db),
+			users,
+			sessions,
+			ldapConfig,
+			audit,
+		)
+		err = s.registerFromEnv("ldap.sync", "SCHEDULE_LDAP_SYNC", "@hourly", func(ctx context.Context) error {
+			result, err := ldap.Sync(ctx)
+			if err != nil {
+				return err
+			}
+			log.Printf("[scheduler] ldap sync: %d user dibuat, %d diperbarui, %d dinonaktifkan, %d gagal",
+				result.Created, result.Updated, result.Deactivated, result.Failed)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// registerFromEnv mendaftarkan task dengan jadwal dari env key, fallback dipakai jika env tidak diisi
+func (s *Scheduler) registerFromEnv(name, key, fallback string, run TaskFunc) error {
+	spec := config.GetEnv(key, fallback)
+	if spec == "off" {
+		return nil
+	}
+	return s.Register(name, spec, run)
+}
+
+// registerLocalFromEnv sama dengan registerFromEnv untuk task yang dijalankan di setiap replika
+func (s *Scheduler) registerLocalFromEnv(name, key, fallback string, run TaskFunc) error {
+	spec := config.GetEnv(key, fallback)
+	if spec == "off" {
+		return nil
+	}
+	return s.RegisterLocal(name, spec, run)
+}
@@ -0,0 +1,205 @@
+// Package scheduler menjalankan task periodik berdasarkan ekspresi cron (5 field, contoh: "*/15 * * * *").
+// Setiap task memakai leader lock di tabel scheduled_tasks sehingga jika aplikasi berjalan
+// di beberapa replika, setiap jadwal hanya dijalankan oleh satu replika. Task yang didaftarkan
+// lewat RegisterLocal mengurus state milik proses sendiri sehingga dijalankan di setiap replika.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang-starter-kit/models"
+	"golang-starter-kit/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskFunc adalah pekerjaan yang dijalankan oleh scheduler
+type TaskFunc func(ctx context.Context) error
+
+// task adalah TaskFunc beserta jadwalnya
+type task struct {
+	name     string
+	schedule cron.Schedule
+	run      TaskFunc
+	next     time.Time // Jadwal berikutnya yang belum dijalankan
+	local    bool      // Dijalankan di setiap replika tanpa leader lock
+}
+
+// Config adalah pengaturan Scheduler
+type Config struct {
+	TickInterval time.Duration // Jeda mengecek task yang sudah jatuh tempo
+	LeaseTTL     time.Duration // Lama lock dipegang, harus lebih lama dari durasi task terlama
+}
+
+// Scheduler menyimpan task yang terdaftar dan menjalankannya sesuai jadwal
+type Scheduler struct {
+	db     *gorm.DB
+	config Config
+	owner  string
+
+	mutex sync.Mutex
+	tasks []*task
+}
+
+// New membuat Scheduler, nilai config yang kosong diisi default
+func New(db *gorm.DB, config Config) *Scheduler {
+	if config.TickInterval <= 0 {
+		config.TickInterval = 30 * time.Second
+	}
+	if config.LeaseTTL <= 0 {
+		config.LeaseTTL = 10 * time.Minute
+	}
+
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		db:     db,
+		config: config,
+		owner:  fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), utils.NewID()[:8]),
+	}
+}
+
+// Register mendaftarkan task dengan ekspresi cron standar 5 field atau deskriptor seperti "@hourly"
+func (s *Scheduler) Register(name, spec string, run TaskFunc) error {
+	return s.register(name, spec, run, false)
+}
+
+// RegisterLocal mendaftarkan task yang dijalankan di setiap replika tanpa leader lock,
+// untuk pekerjaan atas state di memori atau file lokal proses ini
+func (s *Scheduler) RegisterLocal(name, spec string, run TaskFunc) error {
+	return s.register(name, spec, run, true)
+}
+
+// register menyimpan task beserta jadwal berikutnya
+func (s *Scheduler) register(name, spec string, run TaskFunc, local bool) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("task %s: invalid cron expression %q: %w", name, spec, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tasks = append(s.tasks, &task{
+		name:     name,
+		schedule: schedule,
+		run:      run,
+		next:     schedule.Next(utils.Now()),
+		local:    local,
+	})
+	return nil
+}
+
+// Start menjalankan RunDue setiap TickInterval sampai ctx dibatalkan
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunDue(ctx)
+		}
+	}
+}
+
+// RunDue menjalankan task yang jadwalnya sudah lewat dan mengembalikan nama task yang dijalankan
+// oleh replika ini. Jadwal yang terlewat (misalnya saat aplikasi mati) hanya dijalankan sekali.
+func (s *Scheduler) RunDue(ctx context.Context) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var ran []string
+	now := utils.Now()
+	for _, t := range s.tasks {
+		if now.Before(t.next) {
+			continue
+		}
+		due := t.next
+		t.next = t.schedule.Next(now)
+
+		if t.local {
+			if err := s.run(ctx, t); err != nil {
+				log.Printf("[scheduler] task %s gagal: %v", t.name, err)
+			}
+			ran = append(ran, t.name)
+			continue
+		}
+
+		acquired, err := s.acquire(ctx, t.name, due)
+		if err != nil {
+			log.Printf("[scheduler] gagal mengambil lock task %s: %v", t.name, err)
+			continue
+		}
+		if !acquired {
+			// Jadwal ini sedang atau sudah dijalankan oleh replika lain
+			continue
+		}
+
+		runErr := s.run(ctx, t)
+		if runErr != nil {
+			log.Printf("[scheduler] task %s gagal: %v", t.name, runErr)
+		}
+		if err := s.release(ctx, t.name, due, runErr); err != nil {
+			log.Printf("[scheduler] gagal melepas lock task %s: %v", t.name, err)
+		}
+		ran = append(ran, t.name)
+	}
+	return ran
+}
+
+// run menjalankan task, panic diubah menjadi error agar scheduler tetap hidup
+func (s *Scheduler) run(ctx context.Context, t *task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return t.run(ctx)
+}
+
+// acquire mengambil lease task untuk jadwal due. Gagal jika lease masih dipegang replika
+// lain atau jadwal due sudah pernah dijalankan.
+func (s *Scheduler) acquire(ctx context.Context, name string, due time.Time) (bool, error) {
+	db := s.db.WithContext(ctx)
+
+	// Pastikan baris task ada, replika lain yang lebih dulu membuatnya diabaikan
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ScheduledTask{Name: name}).Error
+	if err != nil {
+		return false, err
+	}
+
+	now := utils.Now()
+	lockedUntil := now.Add(s.config.LeaseTTL)
+	result := db.Model(&models.ScheduledTask{}).
+		Where("name = ?", name).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Where("last_run_at IS NULL OR last_run_at < ?", due).
+		Updates(map[string]interface{}{
+			"locked_by":    s.owner,
+			"locked_until": lockedUntil,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// release melepas lease dan mencatat hasil jadwal due
+func (s *Scheduler) release(ctx context.Context, name string, due time.Time, runErr error) error {
+	lastError := ""
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+	return s.db.WithContext(ctx).Model(&models.ScheduledTask{}).
+		Where("name = ? AND locked_by = ?", name, s.owner).
+		Updates(map[string]interface{}{
+			"locked_by":    "",
+			"locked_until": nil,
+			"last_run_at":  due,
+			"last_error":   lastError,
+		}).Error
+}
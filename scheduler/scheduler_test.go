@@ -0,0 +1,157 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/scheduler"
+	"golang-starter-kit/testutil"
+)
+
+func TestSchedulerRunsTaskOnCronSchedule(t *testing.T) {
+	h := testutil.New(t)
+	s := scheduler.New(h.DB, scheduler.Config{})
+	runs := 0
+	if err := s.Register("count", "*/15 * * * *", func(ctx context.Context) error {
+		runs++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jam 09:00 tepat: jadwal berikutnya 09:15
+	if ran := s.RunDue(context.Background()); len(ran) != 0 {
+		t.Fatalf("nothing should run yet, ran %v", ran)
+	}
+	h.Clock.Advance(15 * time.Minute)
+	if ran := s.RunDue(context.Background()); len(ran) != 1 || ran[0] != "count" {
+		t.Fatalf("ran = %v, want [count]", ran)
+	}
+	s.RunDue(context.Background())
+
+	// Jadwal yang terlewat saat aplikasi tidak berjalan hanya dijalankan sekali
+	h.Clock.Advance(time.Hour)
+	s.RunDue(context.Background())
+	if runs != 2 {
+		t.Fatalf("runs = %d, want 2", runs)
+	}
+
+	var state models.ScheduledTask
+	h.DB.First(&state, "name = ?", "count")
+	if state.LastRunAt == nil || !state.LastRunAt.Equal(testutil.DefaultTime.Add(30*time.Minute)) || state.LockedUntil != nil {
+		t.Fatalf("unexpected task state: %+v", state)
+	}
+}
+
+func TestSchedulerRunsEachScheduleOnceAcrossReplicas(t *testing.T) {
+	h := testutil.New(t)
+	runs := 0
+	task := func(ctx context.Context) error {
+		runs++
+		return nil
+	}
+
+	first := scheduler.New(h.DB, scheduler.Config{})
+	second := scheduler.New(h.DB, scheduler.Config{})
+	first.Register("shared", "@hourly", task)
+	second.Register("shared", "@hourly", task)
+
+	h.Clock.Advance(time.Hour)
+	first.RunDue(context.Background())
+	if ran := second.RunDue(context.Background()); len(ran) != 0 {
+		t.Fatalf("second replica should skip a schedule that already ran, ran %v", ran)
+	}
+	if runs != 1 {
+		t.Fatalf("runs = %d, want 1", runs)
+	}
+}
+
+func TestSchedulerRunsLocalTaskOnEveryReplica(t *testing.T) {
+	h := testutil.New(t)
+	runs := 0
+	task := func(ctx context.Context) error {
+		runs++
+		return nil
+	}
+
+	first := scheduler.New(h.DB, scheduler.Config{})
+	second := scheduler.New(h.DB, scheduler.Config{})
+	first.RegisterLocal("local", "@hourly", task)
+	second.RegisterLocal("local", "@hourly", task)
+
+	h.Clock.Advance(time.Hour)
+	first.RunDue(context.Background())
+	if ran := second.RunDue(context.Background()); len(ran) != 1 || ran[0] != "local" {
+		t.Fatalf("ran = %v, want [local]", ran)
+	}
+	if runs != 2 {
+		t.Fatalf("runs = %d, want 2", runs)
+	}
+
+	// Task lokal tidak memakai leader lock di tabel scheduled_tasks
+	var count int64
+	h.DB.Model(&models.ScheduledTask{}).Where("name = ?", "local").Count(&count)
+	if count != 0 {
+		t.Fatalf("local task should not create a lease row, found %d", count)
+	}
+}
+
+func TestSchedulerSkipsTaskLockedByAnotherReplica(t *testing.T) {
+	h := testutil.New(t)
+	s := scheduler.New(h.DB, scheduler.Config{})
+	runs := 0
+	s.Register("locked", "@hourly", func(ctx context.Context) error {
+		runs++
+		return nil
+	})
+
+	// Replika lain sedang menjalankan task ini dan lease-nya masih berlaku
+	lockedUntil := h.Clock.Now().Add(2 * time.Hour)
+	h.DB.Create(&models.ScheduledTask{Name: "locked", LockedBy: "other", LockedUntil: &lockedUntil})
+
+	h.Clock.Advance(time.Hour)
+	s.RunDue(context.Background())
+	if runs != 0 {
+		t.Fatal("task should not run while another replica holds the lease")
+	}
+
+	// Lease kadaluwarsa (replika lain mati), jadwal berikutnya diambil alih
+	h.Clock.Advance(time.Hour)
+	s.RunDue(context.Background())
+	if runs != 1 {
+		t.Fatalf("runs = %d, want 1 after the lease expires", runs)
+	}
+}
+
+func TestSchedulerRecordsTaskError(t *testing.T) {
+	h := testutil.New(t)
+	s := scheduler.New(h.DB, scheduler.Config{})
+	s.Register("failing", "@hourly", func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+	s.Register("panicking", "@hourly", func(ctx context.Context) error {
+		panic("kaboom")
+	})
+
+	h.Clock.Advance(time.Hour)
+	if ran := s.RunDue(context.Background()); len(ran) != 2 {
+		t.Fatalf("ran = %v, want both tasks", ran)
+	}
+
+	var states []models.ScheduledTask
+	h.DB.Order("name").Find(&states)
+	if len(states) != 2 || states[0].LastError != "boom" || states[1].LastError != "panic: kaboom" {
+		t.Fatalf("unexpected task states: %+v", states)
+	}
+}
+
+func TestSchedulerRejectsInvalidCronExpression(t *testing.T) {
+	h := testutil.New(t)
+	s := scheduler.New(h.DB, scheduler.Config{})
+	if err := s.Register("bad", "every minute", func(ctx context.Context) error { return nil }); err == nil {
+		t.Fatal("expected an error for an invalid cron expression")
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/mailer"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// emailChangeTokenPurpose membedakan token konfirmasi email baru dari token bertanda tangan lain
+const emailChangeTokenPurpose = "email_change"
+
+// AccountService berisi aturan bisnis untuk perubahan akun oleh user sendiri (password dan email)
+type AccountService struct {
+	users        repositories.UserRepository
+	emailChanges repositories.EmailChangeRepository
+	userService  *UserService
+	sessions     *SessionService
+	mailer       mailer.Mailer
+	config       config.AccountConfig
+	audit        *AuditService
+}
+
+// NewAccountService membuat AccountService baru
+func NewAccountService(
+	users repositories.UserRepository,
+	emailChanges repositories.EmailChangeRepository,
+	userService *UserService,
+	sessions *SessionService,
+	mail mailer.Mailer,
+	config config.AccountConfig,
+	audit *AuditService,
+) *AccountService {
+	return &AccountService{
+		users:        users,
+		emailChanges: emailChanges,
+		userService:  userService,
+		sessions:     sessions,
+		mailer:       mail,
+		config:       config,
+		audit:        audit,
+	}
+}
+
+// ChangePasswordParams adalah data untuk mengganti password user yang sedang login
+type ChangePasswordParams struct {
+	UserID          uint
+	SessionID       string // Session yang sedang dipakai, tidak ikut dicabut
+	CurrentPassword string
+	NewPassword     string
+}
+
+// ChangePassword mengganti password setelah password saat ini dicocokkan, lalu mencabut
+// semua session lain milik user. Mengembalikan jumlah session yang dicabut.
+func (s *AccountService) ChangePassword(ctx context.Context, params ChangePasswordParams) (int, error) {
+	user, err := s.userService.Get(ctx, params.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkPassword(user, params.CurrentPassword); err != nil {
+		return 0, err
+	}
+
+	// Password baru dicek terhadap kebijakan, termasuk riwayat password
+	if err := s.userService.setPassword(ctx, user, params.NewPassword); err != nil {
+		return 0, err
+	}
+	if err := s.users.Update(ctx, user); err != nil {
+		return 0, err
+	}
+	if err := s.userService.passwords.Remember(ctx, user.ID, user.Password); err != nil {
+		return 0, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.change_password", TargetType: "user", TargetID: user.ID})
+
+	return s.sessions.RevokeOthers(ctx, user.ID, params.SessionID)
+}
+
+// RequestEmailChangeParams adalah data untuk meminta ganti email user yang sedang login
+type RequestEmailChangeParams struct {
+	UserID   uint
+	Password string
+	NewEmail string
+	Locale   string // Bahasa email konfirmasi, kosong berarti locale default mailer
+}
+
+// RequestEmailChange membuat permintaan ganti email dan mengirim link konfirmasi ke alamat baru.
+// Email user baru berubah setelah link dikonfirmasi, permintaan sebelumnya tidak berlaku lagi.
+func (s *AccountService) RequestEmailChange(ctx context.Context, params RequestEmailChangeParams) (*models.EmailChange, error) {
+	user, err := s.userService.Get(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPassword(user, params.Password); err != nil {
+		return nil, err
+	}
+
+	newEmail := strings.TrimSpace(params.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, ErrEmailUnchanged
+	}
+	if err := s.userService.ensureEmailAvailable(ctx, newEmail, user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.emailChanges.DeletePendingByUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	nonce, err := utils.NewToken(24)
+	if err != nil {
+		return nil, err
+	}
+	change := &models.EmailChange{
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		Locale:    params.Locale,
+		TokenHash: utils.HashToken(nonce),
+		ExpiresAt: utils.Now().Add(s.config.EmailChangeTTL),
+	}
+	if err := s.emailChanges.Create(ctx, change); err != nil {
+		return nil, err
+	}
+
+	payload := fmt.Sprintf("%d:%s", change.ID, nonce)
+	token := utils.SignToken(emailChangeTokenPurpose, payload, change.ExpiresAt)
+	err = s.send(ctx, change.Locale, "email_change", change.NewEmail, map[string]interface{}{
+		"Name":      user.Name,
+		"NewEmail":  change.NewEmail,
+		"Link":      s.config.EmailConfirmURL + "?token=" + url.QueryEscape(token),
+		"ExpiresAt": change.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.request_email_change", TargetType: "user", TargetID: user.ID, After: change})
+	return change, nil
+}
+
+// ConfirmEmailChange memverifikasi link konfirmasi, mengganti email user lalu mengirim
+// pemberitahuan ke alamat lama
+func (s *AccountService) ConfirmEmailChange(ctx context.Context, token string) (*models.User, error) {
+	change, err := s.verifyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.Get(ctx, change.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrEmailChangeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Email bisa saja sudah dipakai user lain sejak permintaan dibuat
+	if err := s.userService.ensureEmailAvailable(ctx, change.NewEmail, user.ID); err != nil {
+		return nil, err
+	}
+
+	before := *user
+	oldEmail := user.Email
+	user.Email = change.NewEmail
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	now := utils.Now()
+	change.ConfirmedAt = &now
+	if err := s.emailChanges.Update(ctx, change); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.change_email", TargetType: "user", TargetID: user.ID, Before: &before, After: user})
+
+	// Email sudah berganti, kegagalan mengirim pemberitahuan hanya di-log
+	err = s.send(ctx, change.Locale, "email_changed", oldEmail, map[string]interface{}{
+		"Name":      user.Name,
+		"OldEmail":  oldEmail,
+		"NewEmail":  user.Email,
+		"ChangedAt": now,
+	})
+	if err != nil {
+		log.Printf("[account] gagal mengirim pemberitahuan ganti email ke %s: %v", oldEmail, err)
+	}
+	return user, nil
+}
+
+// verifyToken memeriksa tanda tangan token lalu mencocokkan nonce dengan permintaan yang masih menunggu
+func (s *AccountService) verifyToken(ctx context.Context, token string) (*models.EmailChange, error) {
+	payload, err := utils.VerifySignedToken(emailChangeTokenPurpose, token)
+	if errors.Is(err, utils.ErrSignedTokenExpired) {
+		return nil, ErrEmailChangeExpired
+	}
+	if err != nil {
+		return nil, ErrEmailChangeInvalid
+	}
+
+	idPart, nonce, ok := strings.Cut(payload, ":")
+	id, parseErr := strconv.ParseUint(idPart, 10, 64)
+	if !ok || parseErr != nil {
+		return nil, ErrEmailChangeInvalid
+	}
+
+	change, err := s.emailChanges.FindByID(ctx, uint(id))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrEmailChangeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(nonce)), []byte(change.TokenHash)) != 1 {
+		return nil, ErrEmailChangeInvalid
+	}
+	if change.ConfirmedAt != nil {
+		return nil, ErrEmailChangeInvalid
+	}
+	if !utils.Now().Before(change.ExpiresAt) {
+		return nil, ErrEmailChangeExpired
+	}
+	return change, nil
+}
+
+// send merender template email lalu mengirimnya
+func (s *AccountService) send(ctx context.Context, locale, name, to string, data map[string]interface{}) error {
+	msg, err := mailer.Compose(locale, name, to, data)
+	if err != nil {
+		return wrap(ErrSendAccountEmail, err)
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return wrap(ErrSendAccountEmail, err)
+	}
+	return nil
+}
+
+// checkPassword mencocokkan password saat ini milik user
+func checkPassword(user *models.User, password string) error {
+	if ok, _ := utils.VerifyPassword(user.Password, password); !ok {
+		return ErrCurrentPasswordWrong
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// apiTokenBytes adalah jumlah byte acak pada personal access token
+const apiTokenBytes = 32
+
+// apiTokenPrefixLength adalah panjang awal token yang disimpan untuk mengenali token
+const apiTokenPrefixLength = len(models.APITokenPrefix) + 8
+
+// APITokenService mengelola personal access token / API key milik user
+type APITokenService struct {
+	tokens repositories.APITokenRepository
+	config config.APITokenConfig
+	audit  *AuditService
+}
+
+// NewAPITokenService membuat APITokenService baru
+func NewAPITokenService(tokens repositories.APITokenRepository, config config.APITokenConfig, audit *AuditService) *APITokenService {
+	return &APITokenService{tokens: tokens, config: config, audit: audit}
+}
+
+// CreateAPITokenParams adalah data untuk membuat token baru
+type CreateAPITokenParams struct {
+	UserID        uint
+	Name          string
+	Scopes        []string
+	ExpiresInDays int // 0 = masa berlaku default
+}
+
+// NewAPIToken adalah token yang baru dibuat. Token asli hanya dikembalikan sekali ini.
+type NewAPIToken struct {
+	Token    string           `json:"token"`
+	APIToken *models.APIToken `json:"api_token"`
+}
+
+// List mengambil token aktif milik user
+func (s *APITokenService) List(ctx context.Context, userID uint) ([]models.APIToken, error) {
+	return s.tokens.FindActiveByUser(ctx, userID, utils.Now())
+}
+
+// Create membuat token baru dengan scope dan masa berlaku yang dipilih user
+func (s *APITokenService) Create(ctx context.Context, params CreateAPITokenParams) (*NewAPIToken, error) {
+	scopes, err := normalizeScopes(params.Scopes, models.APITokenScopes)
+	if err != nil {
+		return nil, err
+	}
+
+	days := params.ExpiresInDays
+	if days == 0 {
+		days = s.config.DefaultDays
+	}
+	if days < 0 || days > s.config.MaxDays {
+		return nil, ErrInvalidTokenExpiry
+	}
+
+	random, err := utils.NewToken(apiTokenBytes)
+	if err != nil {
+		return nil, wrap(ErrGenerateToken, err)
+	}
+	plain := models.APITokenPrefix + random
+
+	token := &models.APIToken{
+		UserID:    params.UserID,
+		Name:      strings.TrimSpace(params.Name),
+		Prefix:    plain[:apiTokenPrefixLength],
+		TokenHash: utils.HashToken(plain),
+		Scopes:    scopes,
+		ExpiresAt: utils.Now().Add(time.Duration(days) * 24 * time.Hour),
+	}
+	if err := s.tokens.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEntry{Action: "api_token.create", TargetType: "api_token", TargetID: token.ID, After: token})
+	return &NewAPIToken{Token: plain, APIToken: token}, nil
+}
+
+// Revoke mencabut token aktif milik user
+func (s *APITokenService) Revoke(ctx context.Context, userID, id uint) error {
+	token, err := s.tokens.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrAPITokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if token.UserID != userID || !token.ActiveAt(utils.Now()) {
+		return ErrAPITokenNotFound
+	}
+
+	before := *token
+	now := utils.Now()
+	token.RevokedAt = &now
+	if err := s.tokens.Update(ctx, token); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "api_token.revoke", TargetType: "api_token", TargetID: token.ID, Before: &before, After: token})
+	return nil
+}
+
+// normalizeScopes memvalidasi scope terhadap daftar allowed dan membuang duplikat, urutan dipertahankan
+func normalizeScopes(scopes, allowed []string) ([]string, error) {
+	// Daftar scope yang valid ikut dikirim agar client tahu pilihan yang tersedia
+	invalid := withDetails(ErrInvalidTokenScope, map[string][]string{"allowed_scopes": allowed})
+	if len(scopes) == 0 {
+		return nil, invalid
+	}
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !containsScope(allowed, scope) {
+			return nil, invalid
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
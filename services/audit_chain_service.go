@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// Jenis data pada AuditChainBreak
+const (
+	AuditBreakEntry      = "audit_log"
+	AuditBreakHead       = "head"
+	AuditBreakCheckpoint = "checkpoint"
+)
+
+// AuditChainBreak adalah satu ketidakcocokan yang ditemukan saat verifikasi
+type AuditChainBreak struct {
+	Type   string `json:"type"`
+	ID     uint   `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// AuditVerifyReport adalah hasil verifikasi rantai hash dan checkpoint audit log
+type AuditVerifyReport struct {
+	Checked            int64             `json:"checked"`
+	LastHash           string            `json:"last_hash"`
+	Checkpoints        int               `json:"checkpoints"`
+	SignaturesVerified bool              `json:"signatures_verified"` // false jika public key tidak dikonfigurasi
+	Valid              bool              `json:"valid"`
+	Breaks             []AuditChainBreak `json:"breaks"`
+}
+
+// addBreak mencatat ketidakcocokan pada report
+func (r *AuditVerifyReport) addBreak(kind string, id uint, reason string) {
+	r.Breaks = append(r.Breaks, AuditChainBreak{Type: kind, ID: id, Reason: reason})
+	r.Valid = false
+}
+
+// Verify menelusuri seluruh audit log dari yang terlama, menghitung ulang hash setiap entry dan
+// mencocokkan PrevHash dengan entry sebelumnya. Ujung rantai dan semua checkpoint juga diperiksa,
+// sehingga entry yang diubah, disisipkan atau dihapus (termasuk di akhir rantai) terdeteksi.
+func (s *AuditService) Verify(ctx context.Context) (*AuditVerifyReport, error) {
+	report := &AuditVerifyReport{Valid: true, Breaks: []AuditChainBreak{}, SignaturesVerified: s.config.PublicKey != nil}
+
+	checkpoints, err := s.checkpoints.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Checkpoints = len(checkpoints)
+	byLastID := make(map[uint][]models.AuditCheckpoint)
+	for _, checkpoint := range checkpoints {
+		s.verifySignature(report, &checkpoint)
+		byLastID[checkpoint.LastID] = append(byLastID[checkpoint.LastID], checkpoint)
+	}
+
+	var lastID uint
+	err = s.logs.Each(ctx, repositories.AuditFilter{}, auditExportBatch, func(logs []models.AuditLog) error {
+		for i := range logs {
+			entry := &logs[i]
+			report.Checked++
+
+			if entry.PrevHash != report.LastHash {
+				report.addBreak(AuditBreakEntry, entry.ID, "prev_hash tidak cocok dengan entry sebelumnya (entry dihapus atau disisipkan)")
+			}
+			if entry.ComputeHash() != entry.Hash {
+				report.addBreak(AuditBreakEntry, entry.ID, "isi entry tidak cocok dengan hash-nya (entry diubah)")
+			}
+
+			for _, checkpoint := range byLastID[entry.ID] {
+				if checkpoint.LastHash != entry.Hash || checkpoint.Count != report.Checked {
+					report.addBreak(AuditBreakCheckpoint, checkpoint.ID, "rantai tidak cocok dengan checkpoint")
+				}
+			}
+			delete(byLastID, entry.ID)
+
+			report.LastHash = entry.Hash
+			lastID = entry.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, missing := range byLastID {
+		for _, checkpoint := range missing {
+			report.addBreak(AuditBreakCheckpoint, checkpoint.ID, "entry terakhir checkpoint tidak ditemukan")
+		}
+	}
+
+	head, err := s.logs.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.LastID != lastID || head.LastHash != report.LastHash {
+		report.addBreak(AuditBreakHead, head.LastID, "ujung rantai tidak cocok dengan entry terakhir (entry terakhir dihapus)")
+	}
+	return report, nil
+}
+
+// verifySignature memeriksa tanda tangan checkpoint jika public key dikonfigurasi
+func (s *AuditService) verifySignature(report *AuditVerifyReport, checkpoint *models.AuditCheckpoint) {
+	if s.config.PublicKey == nil {
+		return
+	}
+	if checkpoint.KeyID != auditKeyID(s.config.PublicKey) {
+		report.addBreak(AuditBreakCheckpoint, checkpoint.ID, "checkpoint ditandatangani dengan key lain")
+		return
+	}
+	signature, err := base64.StdEncoding.DecodeString(checkpoint.Signature)
+	if err != nil || !ed25519.Verify(s.config.PublicKey, checkpoint.SigningPayload(), signature) {
+		report.addBreak(AuditBreakCheckpoint, checkpoint.ID, "tanda tangan checkpoint tidak valid")
+	}
+}
+
+// SigningEnabled menandakan AUDIT_SIGNING_KEY diisi sehingga checkpoint bisa dibuat
+func (s *AuditService) SigningEnabled() bool {
+	return s.config.SigningKey != nil
+}
+
+// Checkpoint menandatangani ujung rantai audit log saat ini. Mengembalikan nil jika
+// tidak ada entry baru sejak checkpoint terakhir.
+func (s *AuditService) Checkpoint(ctx context.Context) (*models.AuditCheckpoint, error) {
+	if s.config.SigningKey == nil {
+		return nil, ErrAuditSigningDisabled
+	}
+
+	head, err := s.logs.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.LastID == 0 {
+		return nil, nil
+	}
+	last, err := s.checkpoints.FindLast(ctx)
+	if err == nil && last.LastID == head.LastID {
+		return nil, nil
+	}
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+
+	count, err := s.logs.CountUpTo(ctx, head.LastID)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &models.AuditCheckpoint{
+		LastID:    head.LastID,
+		LastHash:  head.LastHash,
+		Count:     count,
+		KeyID:     auditKeyID(s.config.SigningKey.Public().(ed25519.PublicKey)),
+		CreatedAt: utils.Now().UTC().Truncate(time.Microsecond),
+	}
+	checkpoint.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.config.SigningKey, checkpoint.SigningPayload()))
+	if err := s.checkpoints.Create(ctx, checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// ListCheckpoints mengambil semua checkpoint, urut dari yang terlama
+func (s *AuditService) ListCheckpoints(ctx context.Context) ([]models.AuditCheckpoint, error) {
+	return s.checkpoints.FindAll(ctx)
+}
+
+// ExportCheckpoints menulis semua checkpoint ke w sebagai JSON Lines untuk diarsipkan di luar sistem
+func (s *AuditService) ExportCheckpoints(ctx context.Context, w io.Writer) error {
+	checkpoints, err := s.checkpoints.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	for i := range checkpoints {
+		if err := encoder.Encode(&checkpoints[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditKeyID adalah 8 byte pertama SHA-256 public key (hex), untuk mengenali key yang dipakai
+func auditKeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
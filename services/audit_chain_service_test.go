@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+)
+
+// newAuditService membuat AuditService dengan key Ed25519 deterministik
+func newAuditService(h *testutil.Harness) (*services.AuditService, ed25519.PrivateKey) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	audit := services.NewAuditService(
+		repositories.NewAuditRepository(h.DB),
+		repositories.NewAuditCheckpointRepository(h.DB),
+		config.AuditConfig{SigningKey: key, PublicKey: key.Public().(ed25519.PublicKey)},
+	)
+	return audit, key
+}
+
+// recordEntries mencatat n audit log
+func recordEntries(h *testutil.Harness, audit *services.AuditService, n int) {
+	for i := 1; i <= n; i++ {
+		audit.Record(context.Background(), services.AuditEntry{
+			Action:     "role.create",
+			TargetType: "role",
+			TargetID:   uint(i),
+			After:      &models.Role{ID: uint(i), Name: "role"},
+		})
+		h.Clock.Advance(time.Second)
+	}
+}
+
+func verify(t *testing.T, audit *services.AuditService) *services.AuditVerifyReport {
+	t.Helper()
+	report, err := audit.Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestAuditChainVerifies(t *testing.T) {
+	h := testutil.New(t)
+	audit, _ := newAuditService(h)
+	recordEntries(h, audit, 3)
+
+	report := verify(t, audit)
+	if !report.Valid || report.Checked != 3 || len(report.Breaks) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	var logs []models.AuditLog
+	h.DB.Order("id").Find(&logs)
+	if logs[0].PrevHash != "" || logs[1].PrevHash != logs[0].Hash || logs[2].PrevHash != logs[1].Hash {
+		t.Fatalf("entries are not chained: %+v", logs)
+	}
+}
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	h := testutil.New(t)
+	audit, _ := newAuditService(h)
+	recordEntries(h, audit, 4)
+
+	// Isi entry ke-2 diubah langsung di database (melewati hook)
+	h.DB.Model(&models.AuditLog{ID: 2}).UpdateColumn("action", "role.delete")
+	// Entry ke-3 dihapus langsung di database
+	h.DB.Exec("DELETE FROM audit_logs WHERE id = 3")
+
+	report := verify(t, audit)
+	if report.Valid || len(report.Breaks) != 2 {
+		t.Fatalf("expected two breaks: %+v", report)
+	}
+	if b := report.Breaks[0]; b.Type != services.AuditBreakEntry || b.ID != 2 {
+		t.Fatalf("modified entry not reported: %+v", b)
+	}
+	if b := report.Breaks[1]; b.Type != services.AuditBreakEntry || b.ID != 4 {
+		t.Fatalf("deleted entry not reported at its successor: %+v", b)
+	}
+}
+
+func TestAuditChainDetectsTruncatedTail(t *testing.T) {
+	h := testutil.New(t)
+	audit, _ := newAuditService(h)
+	recordEntries(h, audit, 3)
+
+	h.DB.Exec("DELETE FROM audit_logs WHERE id = 3")
+
+	report := verify(t, audit)
+	if report.Valid || len(report.Breaks) != 1 || report.Breaks[0].Type != services.AuditBreakHead {
+		t.Fatalf("expected head break: %+v", report)
+	}
+}
+
+func TestAuditCheckpointSignsChainHead(t *testing.T) {
+	h := testutil.New(t)
+	audit, _ := newAuditService(h)
+	recordEntries(h, audit, 2)
+
+	checkpoint, err := audit.Checkpoint(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint == nil || checkpoint.LastID != 2 || checkpoint.Count != 2 || checkpoint.Signature == "" {
+		t.Fatalf("unexpected checkpoint: %+v", checkpoint)
+	}
+
+	// Tidak ada entry baru, tidak ada checkpoint baru
+	if again, err := audit.Checkpoint(context.Background()); err != nil || again != nil {
+		t.Fatalf("expected no new checkpoint, got %+v, %v", again, err)
+	}
+
+	recordEntries(h, audit, 1)
+	if report := verify(t, audit); !report.Valid || report.Checkpoints != 1 || !report.SignaturesVerified {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// Tanda tangan checkpoint yang diubah terdeteksi
+	h.DB.Model(&models.AuditCheckpoint{}).Where("id = ?", checkpoint.ID).Update("count", 5)
+	report := verify(t, audit)
+	if report.Valid || len(report.Breaks) != 2 {
+		t.Fatalf("expected signature and count breaks: %+v", report)
+	}
+	for _, b := range report.Breaks {
+		if b.Type != services.AuditBreakCheckpoint || b.ID != checkpoint.ID {
+			t.Fatalf("unexpected break: %+v", b)
+		}
+	}
+}
+
+func TestAuditCheckpointRequiresSigningKey(t *testing.T) {
+	h := testutil.New(t)
+	audit := services.NewAuditService(
+		repositories.NewAuditRepository(h.DB),
+		repositories.NewAuditCheckpointRepository(h.DB),
+		config.AuditConfig{},
+	)
+	if _, err := audit.Checkpoint(context.Background()); err != services.ErrAuditSigningDisabled {
+		t.Fatalf("err = %v, want ErrAuditSigningDisabled", err)
+	}
+}
+
+func TestMigrateBackfillsAuditChain(t *testing.T) {
+	h := testutil.New(t)
+	audit, _ := newAuditService(h)
+
+	// Audit log lama yang dibuat sebelum hash chain ada
+	for i := 0; i < 2; i++ {
+		h.DB.Create(&models.AuditLog{Action: "user.create", TargetType: "user", TargetID: "1", CreatedAt: h.Clock.Now()})
+	}
+	h.DB.Exec("DELETE FROM audit_chain_heads")
+	if err := models.Migrate(h.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	recordEntries(h, audit, 1)
+	if report := verify(t, audit); !report.Valid || report.Checked != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// auditExportBatch adalah jumlah audit log yang dibaca sekali query saat export dan verifikasi
+const auditExportBatch = 500
+
+// auditIgnoredFields tidak dicatat pada diff karena selalu berubah di setiap update
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// AuditService mencatat dan menampilkan audit log perubahan data
+type AuditService struct {
+	logs        repositories.AuditRepository
+	checkpoints repositories.AuditCheckpointRepository
+	config      config.AuditConfig
+}
+
+// NewAuditService membuat AuditService baru
+func NewAuditService(logs repositories.AuditRepository, checkpoints repositories.AuditCheckpointRepository, config config.AuditConfig) *AuditService {
+	return &AuditService{logs: logs, checkpoints: checkpoints, config: config}
+}
+
+// AuditEntry adalah satu perubahan yang dicatat. Before nil berarti data baru dibuat,
+// After nil berarti data dihapus.
+type AuditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   interface{} // ID data yang berubah, disimpan sebagai string
+	Before     interface{}
+	After      interface{}
+}
+
+// Record menyimpan audit log dengan actor, IP, user agent dan request ID dari context.
+// Kegagalan mencatat hanya di-log agar perubahan yang sudah tersimpan tidak dilaporkan gagal.
+// Record pada AuditService nil tidak melakukan apa-apa.
+func (s *AuditService) Record(ctx context.Context, entry AuditEntry) {
+	if s == nil {
+		return
+	}
+
+	changes, err := auditDiff(entry.Before, entry.After)
+	if err != nil {
+		log.Printf("[audit] gagal membuat diff %s: %v", entry.Action, err)
+	}
+
+	info := utils.RequestInfoFrom(ctx)
+	auditLog := &models.AuditLog{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   fmt.Sprint(entry.TargetID),
+		Changes:    changes,
+		IP:         info.IP,
+		UserAgent:  truncate(info.UserAgent, 255),
+		RequestID:  info.RequestID,
+		// Dibulatkan ke mikrodetik (presisi timestamp PostgreSQL) agar hash tetap sama setelah dibaca ulang
+		CreatedAt: utils.Now().UTC().Truncate(time.Microsecond),
+	}
+	if info.UserID != 0 {
+		actorID := info.UserID
+		auditLog.ActorID = &actorID
+	}
+	if err := s.logs.Append(ctx, auditLog); err != nil {
+		log.Printf("[audit] gagal menyimpan %s %s#%v: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
+
+// AuditPage adalah satu halaman audit log
+type AuditPage struct {
+	Items   []models.AuditLog `json:"items"`
+	Page    int               `json:"page"`
+	PerPage int               `json:"per_page"`
+	Total   int64             `json:"total"`
+}
+
+// List mengambil audit log per halaman, page dimulai dari 1
+func (s *AuditService) List(ctx context.Context, filter repositories.AuditFilter, page, perPage int) (*AuditPage, error) {
+	page, perPage, offset := paginate(page, perPage)
+	logs, total, err := s.logs.FindPage(ctx, filter, offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditPage{Items: logs, Page: page, PerPage: perPage, Total: total}, nil
+}
+
+// Export menulis semua audit log yang cocok dengan filter ke w sebagai JSON Lines (satu entry per baris)
+func (s *AuditService) Export(ctx context.Context, filter repositories.AuditFilter, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return s.logs.Each(ctx, filter, auditExportBatch, func(logs []models.AuditLog) error {
+		for i := range logs {
+			if err := encoder.Encode(&logs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// auditDiff membandingkan representasi JSON before dan after lalu mengembalikan field yang berubah
+// dalam bentuk {"field": {"before": ..., "after": ...}}. Field relasi (object) dan field yang
+// tidak diekspos ke JSON (misalnya password) tidak dicatat.
+func auditDiff(before, after interface{}) (models.JSONText, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	type change struct {
+		Before interface{} `json:"before"`
+		After  interface{} `json:"after"`
+	}
+	changes := make(map[string]change)
+	for key, value := range afterFields {
+		if old, ok := beforeFields[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = change{Before: beforeFields[key], After: value}
+		}
+	}
+	for key, value := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			changes[key] = change{Before: value}
+		}
+	}
+
+	raw, err := json.Marshal(changes)
+	return models.JSONText(raw), err
+}
+
+// auditFields mengubah value menjadi map field JSON tanpa field relasi dan field yang diabaikan
+func auditFields(value interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if value == nil {
+		return fields, nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for key, field := range fields {
+		if _, isObject := field.(map[string]interface{}); isObject || auditIgnoredFields[key] {
+			delete(fields, key)
+		}
+	}
+	return fields, nil
+}
+
+// truncate memotong s agar muat di kolom dengan panjang maksimal n
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang-starter-kit/config"
+	"golang-starter-kit/mailer"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// registrationTokenPurpose membedakan token konfirmasi registrasi dari token bertanda tangan lain
+const registrationTokenPurpose = "registration"
+
+// AuthService berisi aturan bisnis untuk registrasi, login dan logout
+type AuthService struct {
+	users         repositories.UserRepository
+	registrations repositories.PendingRegistrationRepository
+	userService   *UserService
+	sessions      *SessionService
+	ldap          *LDAPService // nil atau tidak dikonfigurasi = hanya password lokal
+	mailer        mailer.Mailer
+	policy        config.RegistrationPolicy
+}
+
+// NewAuthService membuat AuthService baru
+func NewAuthService(
+	users repositories.UserRepository,
+	registrations repositories.PendingRegistrationRepository,
+	userService *UserService,
+	sessions *SessionService,
+	ldap *LDAPService,
+	mail mailer.Mailer,
+	policy config.RegistrationPolicy,
+) *AuthService {
+	return &AuthService{
+		users:         users,
+		registrations: registrations,
+		userService:   userService,
+		sessions:      sessions,
+		ldap:          ldap,
+		mailer:        mail,
+		policy:        policy,
+	}
+}
+
+// LoginResult adalah hasil login yang berhasil
+type LoginResult struct {
+	Token     string
+	ExpiresAt time.Time
+	SessionID string
+	User      *models.User
+}
+
+// Register mendaftarkan user baru sesuai RegistrationPolicy. Selebihnya aturannya
+// sama dengan pembuatan user oleh admin. Caller yang tidak berhak memberi role
+// harus mengosongkan params.IDRole agar user mendapat role default.
+//
+// Jika policy.EnumerationSafe aktif, user belum dibuat (hasil nil) dan link konfirmasi dikirim
+// ke email tersebut, lihat registerPending.
+func (s *AuthService) Register(ctx context.Context, params CreateUserParams) (*models.User, error) {
+	switch s.policy.Mode {
+	case config.RegistrationClosed:
+		return nil, ErrRegistrationClosed
+	case config.RegistrationInviteOnly:
+		return nil, ErrRegistrationInviteOnly
+	}
+
+	if !s.policy.EmailDomainAllowed(params.Email) {
+		return nil, ErrEmailDomainNotAllowed
+	}
+
+	if s.policy.EnumerationSafe {
+		return nil, s.registerPending(ctx, params)
+	}
+	return s.userService.Create(ctx, params)
+}
+
+// registerPending menyimpan registrasi yang menunggu konfirmasi lalu mengirim link ke email.
+// Jika email sudah terdaftar, pemilik email hanya mendapat pemberitahuan. Kedua jalur melakukan
+// pekerjaan yang sama (hash password dan kirim email) sehingga response dan waktunya tidak
+// membedakan email yang terdaftar dan yang tidak.
+func (s *AuthService) registerPending(ctx context.Context, params CreateUserParams) error {
+	email := strings.TrimSpace(params.Email)
+	err := s.userService.passwords.Validate(ctx, params.Password, PasswordSubject{Name: params.Name, Email: email})
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := hashPassword(params.Password)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.users.EmailExists(ctx, email, 0)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return s.sendRegistrationEmail(ctx, "registration_exists", email, map[string]interface{}{
+			"Name": params.Name,
+		})
+	}
+
+	if err := s.registrations.DeletePendingByEmail(ctx, email); err != nil {
+		return err
+	}
+	nonce, err := utils.NewToken(24)
+	if err != nil {
+		return err
+	}
+	registration := &models.PendingRegistration{
+		Name:      params.Name,
+		Email:     email,
+		Password:  hashedPassword,
+		IDRole:    params.IDRole,
+		TokenHash: utils.HashToken(nonce),
+		ExpiresAt: utils.Now().Add(s.policy.ConfirmTTL),
+	}
+	if err := s.registrations.Create(ctx, registration); err != nil {
+		return err
+	}
+
+	token := utils.SignToken(registrationTokenPurpose, fmt.Sprintf("%d:%s", registration.ID, nonce), registration.ExpiresAt)
+	return s.sendRegistrationEmail(ctx, "registration_confirm", email, map[string]interface{}{
+		"Name":      params.Name,
+		"Link":      s.policy.ConfirmURL + "?token=" + url.QueryEscape(token),
+		"ExpiresAt": registration.ExpiresAt,
+	})
+}
+
+// ConfirmRegistration membuat user dari registrasi yang menunggu konfirmasi
+func (s *AuthService) ConfirmRegistration(ctx context.Context, token string) (*models.User, error) {
+	registration, err := s.verifyRegistrationToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Email bisa saja sudah dipakai (misalnya lewat undangan) sejak registrasi dibuat
+	user, err := s.userService.createWithHash(ctx, CreateUserParams{
+		Name:   registration.Name,
+		Email:  registration.Email,
+		IDRole: registration.IDRole,
+	}, registration.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.Now()
+	registration.ConfirmedAt = &now
+	if err := s.registrations.Update(ctx, registration); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// verifyRegistrationToken memeriksa tanda tangan token lalu mencocokkan nonce dengan registrasi yang masih menunggu
+func (s *AuthService) verifyRegistrationToken(ctx context.Context, token string) (*models.PendingRegistration, error) {
+	payload, err := utils.VerifySignedToken(registrationTokenPurpose, token)
+	if errors.Is(err, utils.ErrSignedTokenExpired) {
+		return nil, ErrRegistrationExpired
+	}
+	if err != nil {
+		return nil, ErrRegistrationInvalid
+	}
+
+	idPart, nonce, ok := strings.Cut(payload, ":")
+	id, parseErr := strconv.ParseUint(idPart, 10, 64)
+	if !ok || parseErr != nil {
+		return nil, ErrRegistrationInvalid
+	}
+
+	registration, err := s.registrations.FindByID(ctx, uint(id))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrRegistrationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(nonce)), []byte(registration.TokenHash)) != 1 {
+		return nil, ErrRegistrationInvalid
+	}
+	if registration.ConfirmedAt != nil {
+		return nil, ErrRegistrationInvalid
+	}
+	if !utils.Now().Before(registration.ExpiresAt) {
+		return nil, ErrRegistrationExpired
+	}
+	return registration, nil
+}
+
+// sendRegistrationEmail merender template email registrasi lalu mengirimnya
+func (s *AuthService) sendRegistrationEmail(ctx context.Context, name, to string, data map[string]interface{}) error {
+	msg, err := mailer.Compose("", name, to, data)
+	if err != nil {
+		return wrap(ErrSendRegistration, err)
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return wrap(ErrSendRegistration, err)
+	}
+	return nil
+}
+
+// Login memverifikasi email dan password, membuat session lalu menerbitkan JWT.
+// Setiap percobaan, berhasil maupun gagal, dicatat di riwayat login. Semua kegagalan memakai
+// ErrInvalidCredentials agar response tidak membocorkan email mana yang terdaftar.
+//
+// Jika LDAP dikonfigurasi, user dari LDAP dan email yang belum terdaftar diverifikasi ke directory
+// (lihat loginLDAP), user lokal lainnya tetap memakai password lokal.
+func (s *AuthService) Login(ctx context.Context, email, password string) (*LoginResult, error) {
+	// Check Email ada atau tidak
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	found := err == nil
+	if s.ldap.Enabled() {
+		managed := !found
+		if found {
+			if managed, err = s.ldap.Manages(ctx, user.ID); err != nil {
+				return nil, err
+			}
+		}
+		if managed {
+			return s.loginLDAP(ctx, email, password)
+		}
+	}
+	if !found {
+		// Tetap verifikasi ke hash palsu agar waktu response sama dengan email yang terdaftar
+		utils.VerifyDummyPassword(password)
+		s.sessions.RecordAttempt(ctx, email, nil, models.LoginFailedUnknownEmail, "")
+		return nil, ErrInvalidCredentials
+	}
+
+	// Cek apakah password yang diinput cocok dengan password yang di-hash di database
+	ok, rehash := utils.VerifyPassword(user.Password, password)
+	if !ok {
+		s.sessions.RecordAttempt(ctx, email, &user.ID, models.LoginFailedWrongPassword, "")
+		return nil, ErrInvalidCredentials
+	}
+	if rehash {
+		s.rehash(ctx, user, password)
+	}
+	return s.completeLogin(ctx, email, user)
+}
+
+// loginLDAP memverifikasi email dan password ke directory LDAP. User lokal dibuat atau diperbarui
+// dari entry directory sebelum session dibuat.
+func (s *AuthService) loginLDAP(ctx context.Context, email, password string) (*LoginResult, error) {
+	user, err := s.ldap.Authenticate(ctx, email, password)
+	if errors.Is(err, ErrInvalidCredentials) {
+		var userID *uint
+		reason := models.LoginFailedUnknownEmail
+		if existing, findErr := s.users.FindByEmail(ctx, email); findErr == nil {
+			userID, reason = &existing.ID, models.LoginFailedWrongPassword
+		}
+		s.sessions.RecordAttempt(ctx, email, userID, reason, "")
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.completeLogin(ctx, email, user)
+}
+
+// completeLogin menolak user yang dinonaktifkan, lalu memulai session untuk user yang sudah terverifikasi
+func (s *AuthService) completeLogin(ctx context.Context, email string, user *models.User) (*LoginResult, error) {
+	// Dicek setelah password benar agar status akun tidak terlihat oleh orang yang tidak tahu password-nya
+	if user.DeactivatedAt != nil {
+		s.sessions.RecordAttempt(ctx, email, &user.ID, models.LoginFailedDeactivated, "")
+		return nil, ErrAccountDeactivated
+	}
+
+	result, err := startLogin(ctx, s.sessions, user)
+	if err != nil {
+		return nil, err
+	}
+	s.sessions.RecordAttempt(ctx, email, &user.ID, "", result.SessionID)
+	return result, nil
+}
+
+// startLogin membuat session baru untuk user lalu menerbitkan JWT-nya. Dipakai oleh semua
+// cara login (password, LDAP, SSO) agar token yang diterbitkan selalu sama.
+func startLogin(ctx context.Context, sessions *SessionService, user *models.User) (*LoginResult, error) {
+	// Session baru untuk login ini, ID-nya dibawa di token sebagai claim sid
+	expiresAt := utils.Now().Add(utils.JWTLifetime)
+	session, err := sessions.Start(ctx, user, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate token JWT berdasarkan ID, email dan session user
+	token, err := utils.GenerateJWT(user.ID, user.Email, session.ID)
+	if err != nil {
+		return nil, wrap(ErrGenerateToken, err)
+	}
+
+	return &LoginResult{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		SessionID: session.ID,
+		User:      user,
+	}, nil
+}
+
+// rehash memperbarui hash password yang dibuat dengan algoritma atau parameter lama.
+// Password asli hanya tersedia saat login, kegagalan cukup di-log agar login tetap berhasil.
+func (s *AuthService) rehash(ctx context.Context, user *models.User, password string) {
+	hashed, err := utils.HashPassword(password)
+	if err == nil {
+		user.Password = hashed
+		err = s.users.Update(ctx, user)
+	}
+	if err != nil {
+		log.Printf("[auth] gagal memperbarui hash password user %d: %v", user.ID, err)
+	}
+}
+
+// Logout memasukkan token ke blacklist sampai waktu kadaluwarsanya dan mengakhiri session-nya
+func (s *AuthService) Logout(ctx context.Context, tokenString string) error {
+	// Parse dan validasi token JWT
+	token, err := utils.ParseJWT(tokenString)
+	if err != nil || !token.Valid {
+		return ErrInvalidToken
+	}
+
+	// Ambil waktu kadaluarsa dari token, dan tambahkan ke blacklist
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if exp, ok := claims["exp"].(float64); ok {
+			utils.AddToBlacklist(tokenString, time.Unix(int64(exp), 0))
+		}
+		// Session dari login ini ikut berakhir
+		if sid, ok := claims["sid"].(string); ok && sid != "" {
+			return s.sessions.End(ctx, sid)
+		}
+	}
+	return nil
+}
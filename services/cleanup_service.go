@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// CleanupService menghapus token yang sudah tidak bisa dipakai (undangan, session, permintaan ganti
+// email, registrasi, grant dan authorization code OAuth, percobaan login SSO, link dan kode login tanpa password yang kadaluwarsa, dicabut atau sudah dipakai) setelah melewati masa simpan, agar tabel tidak terus membesar
+type CleanupService struct {
+	invitations   repositories.InvitationRepository
+	sessions      repositories.SessionRepository
+	emailChanges  repositories.EmailChangeRepository
+	registrations repositories.PendingRegistrationRepository
+	oauthGrants   repositories.OAuthGrantRepository
+	oauthCodes    repositories.OAuthCodeRepository
+	ssoStates     repositories.SSOStateRepository
+	loginCodes    repositories.LoginCodeRepository
+	keep          time.Duration
+}
+
+// NewCleanupService membuat CleanupService, keep adalah lama data disimpan setelah tidak berlaku
+func NewCleanupService(
+	invitations repositories.InvitationRepository,
+	sessions repositories.SessionRepository,
+	emailChanges repositories.EmailChangeRepository,
+	registrations repositories.PendingRegistrationRepository,
+	oauthGrants repositories.OAuthGrantRepository,
+	oauthCodes repositories.OAuthCodeRepository,
+	ssoStates repositories.SSOStateRepository,
+	loginCodes repositories.LoginCodeRepository,
+	keep time.Duration,
+) *CleanupService {
+	return &CleanupService{
+		invitations:   invitations,
+		sessions:      sessions,
+		emailChanges:  emailChanges,
+		registrations: registrations,
+		oauthGrants:   oauthGrants,
+		oauthCodes:    oauthCodes,
+		ssoStates:     ssoStates,
+		loginCodes:    loginCodes,
+		keep:          keep,
+	}
+}
+
+// CleanupResult adalah jumlah baris yang dihapus oleh Run
+type CleanupResult struct {
+	Invitations   int64 `json:"invitations"`
+	Sessions      int64 `json:"sessions"`
+	EmailChanges  int64 `json:"email_changes"`
+	Registrations int64 `json:"registrations"`
+	OAuthGrants   int64 `json:"oauth_grants"`
+	OAuthCodes    int64 `json:"oauth_codes"`
+	SSOStates     int64 `json:"sso_states"`
+	LoginCodes    int64 `json:"login_codes"`
+}
+
+// Run menghapus token yang berhenti berlaku sebelum (sekarang - masa simpan)
+func (s *CleanupService) Run(ctx context.Context) (*CleanupResult, error) {
+	cutoff := utils.Now().Add(-s.keep)
+	result := &CleanupResult{}
+
+	var err error
+	if result.Invitations, err = s.invitations.DeleteClosedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.Sessions, err = s.sessions.DeleteEndedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.EmailChanges, err = s.emailChanges.DeleteEndedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.Registrations, err = s.registrations.DeleteEndedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.OAuthGrants, err = s.oauthGrants.DeleteEndedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.OAuthCodes, err = s.oauthCodes.DeleteEndedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.SSOStates, err = s.ssoStates.DeleteEndedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.LoginCodes, err = s.loginCodes.DeleteEndedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+)
+
+func TestCleanupDeletesClosedInvitations(t *testing.T) {
+	h := testutil.New(t)
+	role := h.SystemRole(t, models.UserRoleName)
+	now := h.Clock.Now()
+	revokedAt := now.Add(-40 * 24 * time.Hour)
+	acceptedAt := now.Add(-60 * 24 * time.Hour)
+
+	invitations := []models.Invitation{
+		{Email: "expired@example.com", ExpiresAt: now.Add(-31 * 24 * time.Hour)},
+		{Email: "revoked@example.com", ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+		{Email: "accepted@example.com", ExpiresAt: now.Add(-50 * 24 * time.Hour), AcceptedAt: &acceptedAt},
+		{Email: "recent@example.com", ExpiresAt: now.Add(-24 * time.Hour)},
+		{Email: "pending@example.com", ExpiresAt: now.Add(24 * time.Hour)},
+	}
+	for i := range invitations {
+		invitations[i].IDRole = role.ID
+		invitations[i].TokenHash = "hash"
+		if err := h.DB.Create(&invitations[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleanup := services.NewCleanupService(
+		repositories.NewInvitationRepository(h.DB),
+		repositories.NewSessionRepository(h.DB),
+		repositories.NewEmailChangeRepository(h.DB),
+		repositories.NewPendingRegistrationRepository(h.DB),
+		repositories.NewOAuthGrantRepository(h.DB),
+		repositories.NewOAuthCodeRepository(h.DB),
+		repositories.NewSSOStateRepository(h.DB),
+		repositories.NewLoginCodeRepository(h.DB),
+		30*24*time.Hour,
+	)
+	result, err := cleanup.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Undangan yang diterima disimpan sebagai riwayat, yang baru kadaluwarsa belum dihapus
+	if result.Invitations != 2 {
+		t.Fatalf("deleted %d invitations, want 2", result.Invitations)
+	}
+	var remaining int64
+	h.DB.Model(&models.Invitation{}).Count(&remaining)
+	if remaining != 3 {
+		t.Fatalf("remaining = %d, want 3", remaining)
+	}
+}
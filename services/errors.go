@@ -0,0 +1,131 @@
+package services
+
+// ErrorKind mengelompokkan error service agar controller bisa memilih HTTP status yang sesuai
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindUnauthorized
+	KindForbidden
+)
+
+// Error adalah error bertipe yang dikembalikan oleh layer service
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Err     error
+	Details interface{} // Data tambahan untuk response, contoh: daftar pelanggaran kebijakan password
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is membuat errors.Is cocok untuk error dengan Kind dan Message yang sama,
+// sehingga error yang dibungkus (wrap) tetap bisa dibandingkan dengan sentinel di bawah
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Message == e.Message
+}
+
+// wrap membuat salinan sentinel error dengan error asal sebagai penyebab
+func wrap(sentinel *Error, err error) *Error {
+	return &Error{Kind: sentinel.Kind, Message: sentinel.Message, Err: err}
+}
+
+// withDetails membuat salinan sentinel error dengan data tambahan untuk response
+func withDetails(sentinel *Error, details interface{}) *Error {
+	return &Error{Kind: sentinel.Kind, Message: sentinel.Message, Details: details}
+}
+
+// Daftar error yang dikembalikan oleh service
+var (
+	ErrUserNotFound            = &Error{Kind: KindNotFound, Message: "User tidak ditemukan"}
+	ErrUpdateOtherUser         = &Error{Kind: KindForbidden, Message: "Hanya admin yang dapat mengubah data user lain"}
+	ErrUseAccountEndpoints     = &Error{Kind: KindForbidden, Message: "Ubah email dan password sendiri melalui /api/me/email dan /api/me/password"}
+	ErrRoleNotFound            = &Error{Kind: KindNotFound, Message: "Role tidak ditemukan"}
+	ErrInvalidRole             = &Error{Kind: KindValidation, Message: "Role tidak ditemukan"}
+	ErrInvalidReassignRole     = &Error{Kind: KindValidation, Message: "Role tujuan reassign tidak valid"}
+	ErrRoleInUse               = &Error{Kind: KindConflict, Message: "Role masih dipakai oleh user, gunakan ?reassign_to= untuk memindahkan user ke role lain"}
+	ErrSystemRoleProtected     = &Error{Kind: KindForbidden, Message: "Role sistem tidak dapat dihapus atau diganti nama"}
+	ErrEmailTaken              = &Error{Kind: KindConflict, Message: "Email sudah terdaftar"}
+	ErrPasswordPolicy          = &Error{Kind: KindValidation, Message: "Password tidak memenuhi kebijakan"}
+	ErrDefaultRoleMissing      = &Error{Kind: KindInternal, Message: "Role default tidak ditemukan"}
+	ErrRegistrationClosed      = &Error{Kind: KindForbidden, Message: "Registrasi publik sedang ditutup"}
+	ErrRegistrationInviteOnly  = &Error{Kind: KindForbidden, Message: "Registrasi hanya dapat dilakukan melalui undangan"}
+	ErrEmailDomainNotAllowed   = &Error{Kind: KindValidation, Message: "Domain email tidak diizinkan untuk registrasi"}
+	ErrInvitationNotFound      = &Error{Kind: KindNotFound, Message: "Undangan tidak ditemukan"}
+	ErrInvitationPending       = &Error{Kind: KindConflict, Message: "Undangan untuk email ini masih aktif"}
+	ErrInvitationClosed        = &Error{Kind: KindConflict, Message: "Undangan sudah diterima atau dicabut"}
+	ErrInvitationInvalid       = &Error{Kind: KindValidation, Message: "Link undangan tidak valid"}
+	ErrInvitationExpired       = &Error{Kind: KindValidation, Message: "Link undangan sudah kadaluwarsa"}
+	ErrInvalidInvitationStatus = &Error{Kind: KindValidation, Message: "Status undangan tidak valid"}
+	ErrSendInvitation          = &Error{Kind: KindInternal, Message: "Gagal mengirim email undangan"}
+	ErrJobNotFound             = &Error{Kind: KindNotFound, Message: "Job tidak ditemukan"}
+	ErrInvalidJobStatus        = &Error{Kind: KindValidation, Message: "Status job tidak valid"}
+	ErrJobNotRetryable         = &Error{Kind: KindConflict, Message: "Hanya job yang gagal (dead) atau menunggu retry yang dapat diulang"}
+	ErrJobPayloadCleared       = &Error{Kind: KindConflict, Message: "Isi job rahasia sudah dihapus sehingga tidak dapat diulang"}
+	ErrInvalidAuditFilter      = &Error{Kind: KindValidation, Message: "Filter audit tidak valid"}
+	ErrAuditSigningDisabled    = &Error{Kind: KindInternal, Message: "Signing key audit belum dikonfigurasi"}
+	ErrSessionNotFound         = &Error{Kind: KindNotFound, Message: "Session tidak ditemukan"}
+	ErrInvalidLoginFilter      = &Error{Kind: KindValidation, Message: "Filter riwayat login tidak valid"}
+	ErrCurrentPasswordWrong    = &Error{Kind: KindValidation, Message: "Password saat ini salah"}
+	ErrEmailUnchanged          = &Error{Kind: KindValidation, Message: "Email baru sama dengan email saat ini"}
+	ErrEmailChangeInvalid      = &Error{Kind: KindValidation, Message: "Link konfirmasi email tidak valid"}
+	ErrEmailChangeExpired      = &Error{Kind: KindValidation, Message: "Link konfirmasi email sudah kadaluwarsa"}
+	ErrSendAccountEmail        = &Error{Kind: KindInternal, Message: "Gagal mengirim email"}
+	ErrInvalidCredentials      = &Error{Kind: KindUnauthorized, Message: "Email atau password salah"}
+	ErrRegistrationInvalid     = &Error{Kind: KindValidation, Message: "Link konfirmasi registrasi tidak valid"}
+	ErrRegistrationExpired     = &Error{Kind: KindValidation, Message: "Link konfirmasi registrasi sudah kadaluwarsa"}
+	ErrSendRegistration        = &Error{Kind: KindInternal, Message: "Gagal mengirim email registrasi"}
+	ErrAPITokenNotFound        = &Error{Kind: KindNotFound, Message: "Token tidak ditemukan"}
+	ErrInvalidTokenScope       = &Error{Kind: KindValidation, Message: "Scope token tidak valid"}
+	ErrInvalidTokenExpiry      = &Error{Kind: KindValidation, Message: "Masa berlaku token tidak valid"}
+	ErrOAuthClientNotFound     = &Error{Kind: KindNotFound, Message: "Client OAuth tidak ditemukan"}
+	ErrInvalidOAuthClient      = &Error{Kind: KindValidation, Message: "Grant type client OAuth tidak valid"}
+	ErrInvalidRedirectURI      = &Error{Kind: KindValidation, Message: "Redirect URI tidak valid atau tidak terdaftar untuk client"}
+	ErrSSOProviderNotFound     = &Error{Kind: KindNotFound, Message: "Provider SSO tidak ditemukan"}
+	ErrSSOProviderUnavailable  = &Error{Kind: KindInternal, Message: "Provider SSO tidak dapat dihubungi"}
+	ErrSSOStateInvalid         = &Error{Kind: KindValidation, Message: "Sesi login SSO tidak valid atau sudah kadaluwarsa"}
+	ErrSSOLoginFailed          = &Error{Kind: KindUnauthorized, Message: "Login SSO gagal"}
+	ErrSSOAccountUnavailable   = &Error{Kind: KindUnauthorized, Message: "Akun untuk identitas ini tidak aktif"}
+	ErrSSONotProvisioned       = &Error{Kind: KindForbidden, Message: "Identitas ini belum terhubung ke akun mana pun"}
+	ErrSSOEmailMissing         = &Error{Kind: KindValidation, Message: "Provider SSO tidak mengirim email"}
+	ErrSSOEmailTaken           = &Error{Kind: KindConflict, Message: "Email sudah terdaftar, login dengan password lalu tautkan identitas dari pengaturan akun"}
+	ErrSSOIdentityLinked       = &Error{Kind: KindConflict, Message: "Identitas sudah ditautkan ke akun lain"}
+	ErrSSOLastIdentity         = &Error{Kind: KindConflict, Message: "Identitas terakhir tidak dapat dilepas karena akun tidak memiliki password"}
+	ErrSSORoleMissing          = &Error{Kind: KindInternal, Message: "Role hasil mapping SSO tidak ditemukan"}
+	ErrUserIdentityNotFound    = &Error{Kind: KindNotFound, Message: "Identitas tidak ditemukan"}
+	ErrAccountDeactivated      = &Error{Kind: KindForbidden, Message: "Akun sudah dinonaktifkan"}
+	ErrLDAPUnavailable         = &Error{Kind: KindInternal, Message: "Server LDAP tidak dapat dihubungi"}
+	ErrLDAPDisabled            = &Error{Kind: KindNotFound, Message: "LDAP tidak dikonfigurasi"}
+	ErrLDAPSyncEmpty           = &Error{Kind: KindInternal, Message: "Sinkronisasi LDAP tidak menemukan user, tidak ada user yang dinonaktifkan"}
+	ErrLDAPEmailMissing        = &Error{Kind: KindValidation, Message: "Entry LDAP tidak memiliki email"}
+	ErrLDAPRoleMissing         = &Error{Kind: KindInternal, Message: "Role hasil mapping LDAP tidak ditemukan"}
+	ErrLDAPEmailTaken          = &Error{Kind: KindConflict, Message: "Email sudah dipakai akun lokal yang tidak dapat ditautkan ke LDAP"}
+	ErrLDAPIdentityManaged     = &Error{Kind: KindConflict, Message: "Identitas LDAP dikelola oleh directory dan tidak dapat dilepas"}
+	ErrSCIMUserNameInvalid     = &Error{Kind: KindValidation, Message: "userName harus berupa alamat email"}
+	ErrSCIMExternalIDTaken     = &Error{Kind: KindConflict, Message: "externalId sudah dipakai user lain"}
+	ErrSCIMGroupNameRequired   = &Error{Kind: KindValidation, Message: "displayName grup wajib diisi"}
+	ErrSCIMGroupNameTaken      = &Error{Kind: KindConflict, Message: "Nama grup sudah dipakai"}
+	ErrSCIMMemberNotFound      = &Error{Kind: KindValidation, Message: "Member grup tidak ditemukan"}
+	ErrSCIMResourceNotFound    = &Error{Kind: KindNotFound, Message: "Resource SCIM tidak ditemukan"}
+	ErrSCIMIdentityManaged     = &Error{Kind: KindConflict, Message: "Identitas SCIM dikelola oleh identity provider dan tidak dapat dilepas"}
+	ErrPasswordlessDisabled    = &Error{Kind: KindForbidden, Message: "Login tanpa password tidak diaktifkan"}
+	ErrInvalidLoginMethod      = &Error{Kind: KindValidation, Message: "Metode login tanpa password harus link atau code"}
+	ErrPasswordlessInvalid     = &Error{Kind: KindUnauthorized, Message: "Link atau kode login tidak valid atau sudah kadaluwarsa"}
+	ErrSendLoginCode           = &Error{Kind: KindInternal, Message: "Gagal mengirim email login"}
+	ErrInvalidToken            = &Error{Kind: KindUnauthorized, Message: "Token tidak valid"}
+	ErrHashPassword            = &Error{Kind: KindInternal, Message: "Gagal mengenkripsi password"}
+	ErrGenerateToken           = &Error{Kind: KindInternal, Message: "Gagal membuat token"}
+)
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/mailer"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// invitationTokenPurpose membedakan token undangan dari token bertanda tangan lain
+const invitationTokenPurpose = "invitation"
+
+// InvitationService berisi aturan bisnis untuk onboarding lewat undangan
+type InvitationService struct {
+	invitations repositories.InvitationRepository
+	users       repositories.UserRepository
+	roles       repositories.RoleRepository
+	userService *UserService
+	mailer      mailer.Mailer
+	config      config.InvitationConfig
+	audit       *AuditService
+}
+
+// NewInvitationService membuat InvitationService baru
+func NewInvitationService(
+	invitations repositories.InvitationRepository,
+	users repositories.UserRepository,
+	roles repositories.RoleRepository,
+	userService *UserService,
+	mail mailer.Mailer,
+	config config.InvitationConfig,
+	audit *AuditService,
+) *InvitationService {
+	return &InvitationService{
+		invitations: invitations,
+		users:       users,
+		roles:       roles,
+		userService: userService,
+		mailer:      mail,
+		config:      config,
+		audit:       audit,
+	}
+}
+
+// List mengambil undangan, status kosong berarti semua status
+func (s *InvitationService) List(ctx context.Context, status string) ([]models.Invitation, error) {
+	switch status {
+	case "", models.InvitationPending, models.InvitationAccepted, models.InvitationRevoked, models.InvitationExpired:
+	default:
+		return nil, ErrInvalidInvitationStatus
+	}
+
+	now := utils.Now()
+	invitations, err := s.invitations.FindAll(ctx, status, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range invitations {
+		invitations[i].Status = invitations[i].StatusAt(now)
+	}
+	return invitations, nil
+}
+
+// InviteParams adalah data untuk membuat undangan
+type InviteParams struct {
+	Email     string
+	IDRole    uint
+	Locale    string // Bahasa email undangan, kosong berarti locale default mailer
+	InvitedBy uint
+}
+
+// Invite membuat undangan untuk email dengan role tertentu lalu mengirim link undangan
+func (s *InvitationService) Invite(ctx context.Context, params InviteParams) (*models.Invitation, error) {
+	email := strings.TrimSpace(params.Email)
+
+	if err := s.userService.ensureEmailAvailable(ctx, email, 0); err != nil {
+		return nil, err
+	}
+	if err := s.userService.ensureRoleExists(ctx, params.IDRole); err != nil {
+		return nil, err
+	}
+
+	now := utils.Now()
+	_, err := s.invitations.FindPendingByEmail(ctx, email, now)
+	if err == nil {
+		return nil, ErrInvitationPending
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+
+	invitation := &models.Invitation{
+		Email:     email,
+		IDRole:    params.IDRole,
+		InvitedBy: params.InvitedBy,
+		Locale:    params.Locale,
+	}
+	token, err := s.renewToken(invitation, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.invitations.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	// Ambil ulang agar relasi role tersedia untuk isi email
+	invitation, err = s.get(ctx, invitation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.send(ctx, invitation, token); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "invitation.create", TargetType: "invitation", TargetID: invitation.ID, After: invitation})
+	return invitation, nil
+}
+
+// Resend membuat link baru (link lama tidak berlaku lagi), memperpanjang masa berlaku dan mengirim ulang
+func (s *InvitationService) Resend(ctx context.Context, id uint) (*models.Invitation, error) {
+	invitation, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, ErrInvitationClosed
+	}
+	before := *invitation
+
+	token, err := s.renewToken(invitation, utils.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.invitations.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+	if err := s.send(ctx, invitation, token); err != nil {
+		return nil, err
+	}
+
+	resent, err := s.get(ctx, invitation.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "invitation.resend", TargetType: "invitation", TargetID: resent.ID, Before: &before, After: resent})
+	return resent, nil
+}
+
+// Revoke mencabut undangan sehingga link-nya tidak bisa dipakai
+func (s *InvitationService) Revoke(ctx context.Context, id uint) (*models.Invitation, error) {
+	invitation, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, ErrInvitationClosed
+	}
+
+	before := *invitation
+	now := utils.Now()
+	invitation.RevokedAt = &now
+	if err := s.invitations.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+	invitation.Status = invitation.StatusAt(now)
+	s.audit.Record(ctx, AuditEntry{Action: "invitation.revoke", TargetType: "invitation", TargetID: invitation.ID, Before: &before, After: invitation})
+	return invitation, nil
+}
+
+// AcceptInvitationParams adalah data yang diisi oleh user yang diundang
+type AcceptInvitationParams struct {
+	Token    string
+	Name     string
+	Password string
+}
+
+// Accept memverifikasi token undangan lalu membuat user dengan email dan role dari undangan
+func (s *InvitationService) Accept(ctx context.Context, params AcceptInvitationParams) (*models.User, error) {
+	invitation, err := s.verifyToken(ctx, params.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.Create(ctx, CreateUserParams{
+		Name:     params.Name,
+		Email:    invitation.Email,
+		Password: params.Password,
+		IDRole:   invitation.IDRole,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	before := *invitation
+	now := utils.Now()
+	invitation.AcceptedAt = &now
+	if err := s.invitations.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+	invitation.Status = invitation.StatusAt(now)
+	s.audit.Record(ctx, AuditEntry{Action: "invitation.accept", TargetType: "invitation", TargetID: invitation.ID, Before: &before, After: invitation})
+	return user, nil
+}
+
+// verifyToken memeriksa tanda tangan token lalu mencocokkan nonce dengan undangan yang masih aktif
+func (s *InvitationService) verifyToken(ctx context.Context, token string) (*models.Invitation, error) {
+	payload, err := utils.VerifySignedToken(invitationTokenPurpose, token)
+	if errors.Is(err, utils.ErrSignedTokenExpired) {
+		return nil, ErrInvitationExpired
+	}
+	if err != nil {
+		return nil, ErrInvitationInvalid
+	}
+
+	idPart, nonce, ok := strings.Cut(payload, ":")
+	id, parseErr := strconv.ParseUint(idPart, 10, 64)
+	if !ok || parseErr != nil {
+		return nil, ErrInvitationInvalid
+	}
+
+	invitation, err := s.invitations.FindByID(ctx, uint(id))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Link lama (sebelum resend) punya nonce berbeda
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(nonce)), []byte(invitation.TokenHash)) != 1 {
+		return nil, ErrInvitationInvalid
+	}
+
+	switch invitation.StatusAt(utils.Now()) {
+	case models.InvitationPending:
+		return invitation, nil
+	case models.InvitationExpired:
+		return nil, ErrInvitationExpired
+	default:
+		return nil, ErrInvitationClosed
+	}
+}
+
+// renewToken membuat nonce baru untuk undangan dan memperpanjang masa berlakunya.
+// Token bertanda tangan baru dibuat di send karena membutuhkan ID undangan.
+func (s *InvitationService) renewToken(invitation *models.Invitation, now time.Time) (string, error) {
+	nonce, err := utils.NewToken(24)
+	if err != nil {
+		return "", err
+	}
+	invitation.TokenHash = utils.HashToken(nonce)
+	invitation.ExpiresAt = now.Add(s.config.TTL)
+	invitation.SentAt = now
+	return nonce, nil
+}
+
+// send mengirim email undangan berisi link bertanda tangan
+func (s *InvitationService) send(ctx context.Context, invitation *models.Invitation, nonce string) error {
+	payload := fmt.Sprintf("%d:%s", invitation.ID, nonce)
+	token := utils.SignToken(invitationTokenPurpose, payload, invitation.ExpiresAt)
+
+	msg, err := mailer.Compose(invitation.Locale, "invitation", invitation.Email, map[string]interface{}{
+		"Link":      s.config.AcceptURL + "?token=" + url.QueryEscape(token),
+		"ExpiresAt": invitation.ExpiresAt,
+		"RoleName":  invitation.Role.Name,
+	})
+	if err != nil {
+		return wrap(ErrSendInvitation, err)
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return wrap(ErrSendInvitation, err)
+	}
+	return nil
+}
+
+// get mengambil undangan berdasarkan ID dan mengisi statusnya
+func (s *InvitationService) get(ctx context.Context, id uint) (*models.Invitation, error) {
+	invitation, err := s.invitations.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	invitation.Status = invitation.StatusAt(utils.Now())
+	return invitation, nil
+}
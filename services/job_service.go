@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// maxJobListLimit membatasi jumlah job yang dikembalikan sekali request
+const maxJobListLimit = 500
+
+// JobService berisi aturan untuk inspeksi dan retry job oleh admin
+type JobService struct {
+	jobs  repositories.JobRepository
+	audit *AuditService
+}
+
+// NewJobService membuat JobService baru
+func NewJobService(jobs repositories.JobRepository, audit *AuditService) *JobService {
+	return &JobService{jobs: jobs, audit: audit}
+}
+
+// List mengambil job sesuai filter, limit 0 atau terlalu besar diganti batas maksimal
+func (s *JobService) List(ctx context.Context, filter repositories.JobFilter) ([]models.Job, error) {
+	switch filter.Status {
+	case "", models.JobPending, models.JobRunning, models.JobSucceeded, models.JobDead:
+	default:
+		return nil, ErrInvalidJobStatus
+	}
+	if filter.Limit <= 0 || filter.Limit > maxJobListLimit {
+		filter.Limit = maxJobListLimit
+	}
+	return s.jobs.FindAll(ctx, filter)
+}
+
+// Get mengambil job berdasarkan ID
+func (s *JobService) Get(ctx context.Context, id uint) (*models.Job, error) {
+	job, err := s.jobs.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Retry menjadwalkan ulang job yang sudah dead atau masih menunggu retry agar segera dijalankan.
+// Jumlah percobaan di-reset sehingga job mendapat jatah retry penuh lagi. Job rahasia yang
+// payload-nya sudah dihapus tidak bisa diulang.
+func (s *JobService) Retry(ctx context.Context, id uint) (*models.Job, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobDead && job.Status != models.JobPending {
+		return nil, ErrJobNotRetryable
+	}
+	if job.Sensitive && job.Payload == "" {
+		return nil, ErrJobPayloadCleared
+	}
+	before := *job
+
+	job.Status = models.JobPending
+	job.RunAt = utils.Now()
+	job.Attempts = 0
+	if err := s.jobs.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "job.retry", TargetType: "job", TargetID: job.ID, Before: &before, After: job})
+	return job, nil
+}
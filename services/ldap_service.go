@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+)
+
+// ldapPageSize adalah jumlah entry per halaman saat sinkronisasi (paged results control)
+const ldapPageSize = 500
+
+// LDAPService berisi aturan bisnis untuk login lewat directory LDAP (bind sebagai user) dan
+// sinkronisasi user dari directory. User dari LDAP ditandai dengan UserIdentity provider "ldap"
+// dan tidak punya password lokal, password-nya selalu diverifikasi ke directory.
+type LDAPService struct {
+	identities  repositories.UserIdentityRepository
+	users       repositories.UserRepository
+	roles       repositories.RoleRepository
+	userService *UserService
+	sessions    *SessionService
+	config      config.LDAPConfig
+	audit       *AuditService
+}
+
+// NewLDAPService membuat LDAPService baru
+func NewLDAPService(
+	identities repositories.UserIdentityRepository,
+	users repositories.UserRepository,
+	roles repositories.RoleRepository,
+	userService *UserService,
+	sessions *SessionService,
+	config config.LDAPConfig,
+	audit *AuditService,
+) *LDAPService {
+	return &LDAPService{
+		identities:  identities,
+		users:       users,
+		roles:       roles,
+		userService: userService,
+		sessions:    sessions,
+		config:      config,
+		audit:       audit,
+	}
+}
+
+// LDAPSyncResult adalah jumlah user yang berubah dalam satu sinkronisasi
+type LDAPSyncResult struct {
+	Created     int `json:"created"`
+	Updated     int `json:"updated"`
+	Deactivated int `json:"deactivated"`
+	Failed      int `json:"failed"` // Entry yang dilewati, detailnya di log
+}
+
+// ldapEntry adalah data user yang dibaca dari satu entry directory
+type ldapEntry struct {
+	DN     string
+	ID     string
+	Email  string
+	Name   string
+	Groups []string
+}
+
+// Hasil apply untuk satu entry
+const (
+	ldapUnchanged = iota
+	ldapCreated
+	ldapUpdated
+)
+
+// Enabled menandakan LDAP dikonfigurasi. Aman dipanggil pada service nil.
+func (s *LDAPService) Enabled() bool {
+	return s != nil && s.config.Enabled()
+}
+
+// Manages menandakan user berasal dari directory LDAP sehingga login-nya diverifikasi ke LDAP
+func (s *LDAPService) Manages(ctx context.Context, userID uint) (bool, error) {
+	identities, err := s.identities.FindByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, identity := range identities {
+		if identity.Provider == models.IdentityProviderLDAP {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Authenticate mencari entry user dengan UserFilter lalu bind sebagai user tersebut dengan
+// password yang diinput. Jika berhasil, user lokal dibuat atau diperbarui dari entry-nya.
+// Email yang tidak ditemukan dan password yang salah sama-sama menghasilkan ErrInvalidCredentials.
+func (s *LDAPService) Authenticate(ctx context.Context, login, password string) (*models.User, error) {
+	// Bind dengan password kosong adalah unauthenticated bind yang diterima banyak server LDAP
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := strings.ReplaceAll(s.config.UserFilter, "{login}", ldap.EscapeFilter(strings.TrimSpace(login)))
+	entries, err := s.search(conn, filter)
+	if err != nil {
+		return nil, err
+	}
+	// Lebih dari satu entry berarti filter tidak unik, lebih aman ditolak daripada memilih salah satu
+	if len(entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := conn.Bind(entries[0].DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, wrap(ErrLDAPUnavailable, err)
+	}
+
+	user, _, err := s.apply(ctx, entries[0])
+	return user, err
+}
+
+// Sync menyamakan user lokal dengan directory: entry yang cocok dengan SyncFilter dibuat atau
+// diperbarui (termasuk role dan status aktif), user LDAP yang tidak lagi ditemukan dinonaktifkan
+// dan session-nya dicabut. Entry yang gagal diproses dilewati tanpa menonaktifkan user-nya.
+func (s *LDAPService) Sync(ctx context.Context) (*LDAPSyncResult, error) {
+	if !s.Enabled() {
+		return nil, ErrLDAPDisabled
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.search(conn, s.config.SyncFilter)
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	managed, err := s.identities.FindByProvider(ctx, models.IdentityProviderLDAP)
+	if err != nil {
+		return nil, err
+	}
+	// Hasil kosong hampir selalu berarti filter atau base DN salah, jangan nonaktifkan semua user
+	if len(entries) == 0 && len(managed) > 0 {
+		return nil, ErrLDAPSyncEmpty
+	}
+
+	result := &LDAPSyncResult{}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		seen[entry.ID] = true
+		user, change, err := s.apply(ctx, entry)
+		if err == nil && user.DeactivatedAt != nil {
+			err = s.userService.setDeactivated(ctx, s.sessions, user, false)
+			if change == ldapUnchanged {
+				change = ldapUpdated
+			}
+		}
+		if err != nil {
+			log.Printf("[ldap] gagal sinkronisasi %s: %v", entry.DN, err)
+			result.Failed++
+			continue
+		}
+
+		switch change {
+		case ldapCreated:
+			result.Created++
+		case ldapUpdated:
+			result.Updated++
+		}
+	}
+
+	for _, identity := range managed {
+		if seen[identity.Subject] {
+			continue
+		}
+		user, err := s.users.FindByID(ctx, identity.UserID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err == nil && user.DeactivatedAt == nil {
+			if err = s.userService.setDeactivated(ctx, s.sessions, user, true); err == nil {
+				result.Deactivated++
+			}
+		}
+		if err != nil {
+			log.Printf("[ldap] gagal menonaktifkan user %d: %v", identity.UserID, err)
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// apply membuat atau memperbarui user lokal dari entry directory. Entry yang belum dikenal
+// ditautkan ke user lokal dengan email yang sama (lihat provision), atau dibuatkan user baru.
+// Status aktif tidak diubah di sini.
+func (s *LDAPService) apply(ctx context.Context, entry *ldapEntry) (*models.User, int, error) {
+	if entry.Email == "" {
+		return nil, ldapUnchanged, ErrLDAPEmailMissing
+	}
+	role, err := s.mappedRole(ctx, entry)
+	if err != nil {
+		return nil, ldapUnchanged, err
+	}
+
+	var user *models.User
+	linked, err := s.identities.FindBySubject(ctx, models.IdentityProviderLDAP, entry.ID)
+	switch {
+	case err == nil:
+		if user, err = s.users.FindByID(ctx, linked.UserID); errors.Is(err, repositories.ErrNotFound) {
+			// User sudah dihapus di aplikasi, tidak dibuat ulang dari directory
+			return nil, ldapUnchanged, ErrAccountDeactivated
+		}
+	case errors.Is(err, repositories.ErrNotFound):
+		var created bool
+		if user, created, err = s.provision(ctx, entry, role); err == nil && created {
+			return user, ldapCreated, nil
+		}
+	}
+	if err != nil {
+		return nil, ldapUnchanged, err
+	}
+
+	before := *user
+	changed := false
+	if entry.Name != user.Name {
+		user.Name = entry.Name
+		changed = true
+	}
+	if !strings.EqualFold(entry.Email, user.Email) {
+		if err := s.userService.ensureEmailAvailable(ctx, entry.Email, user.ID); err != nil {
+			return nil, ldapUnchanged, err
+		}
+		user.Email = entry.Email
+		changed = true
+	}
+	if role != nil && role.ID != user.IDRole {
+		user.IDRole = role.ID
+		user.Role = *role
+		changed = true
+	}
+	if !changed {
+		return user, ldapUnchanged, nil
+	}
+
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, ldapUnchanged, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.update", TargetType: "user", TargetID: user.ID, Before: before, After: user})
+	return user, ldapUpdated, nil
+}
+
+// provision menautkan entry ke user lokal dengan email yang sama atau membuat user baru tanpa
+// password lokal. created bernilai true jika user baru dibuat. User lokal hanya ditautkan jika
+// LDAP_LINK_BY_EMAIL aktif dan user tersebut tidak punya password lokal maupun role admin,
+// selain itu entry ditolak dengan ErrLDAPEmailTaken.
+func (s *LDAPService) provision(ctx context.Context, entry *ldapEntry, role *models.Role) (*models.User, bool, error) {
+	user, err := s.users.FindByEmail(ctx, entry.Email)
+	created := false
+	switch {
+	case err == nil:
+		if !s.config.LinkByEmail || user.Password != "" || user.Role.Name == models.AdminRoleName {
+			return nil, false, ErrLDAPEmailTaken
+		}
+	case errors.Is(err, repositories.ErrNotFound):
+		var roleID uint
+		if role != nil {
+			roleID = role.ID
+		} else if s.config.DefaultRole != "" {
+			defaultRole, err := s.findRole(ctx, s.config.DefaultRole)
+			if err != nil {
+				return nil, false, err
+			}
+			roleID = defaultRole.ID
+		}
+		user, err = s.userService.createWithHash(ctx, CreateUserParams{Name: entry.Name, Email: entry.Email, IDRole: roleID}, "")
+		created = true
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	linked := &models.UserIdentity{
+		UserID:   user.ID,
+		Provider: models.IdentityProviderLDAP,
+		Subject:  entry.ID,
+		Email:    entry.Email,
+	}
+	if err := s.identities.Create(ctx, linked); err != nil {
+		return nil, false, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user_identity.link", TargetType: "user", TargetID: user.ID, After: linked})
+	return user, created, nil
+}
+
+// mappedRole mengembalikan role dari mapping pertama yang grupnya dimiliki user, nil jika tidak ada
+func (s *LDAPService) mappedRole(ctx context.Context, entry *ldapEntry) (*models.Role, error) {
+	for _, mapping := range s.config.GroupRoles {
+		for _, group := range entry.Groups {
+			if sameDN(mapping.Group, group) {
+				return s.findRole(ctx, mapping.Role)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findRole mengambil role hasil mapping, role yang tidak ada berarti konfigurasi LDAP salah
+func (s *LDAPService) findRole(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.roles.FindByName(ctx, name)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, wrap(ErrLDAPRoleMissing, fmt.Errorf("role %q", name))
+	}
+	return role, err
+}
+
+// connect membuka koneksi ke server LDAP lalu bind sebagai akun service (jika diisi)
+func (s *LDAPService) connect() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: s.config.InsecureSkipVerify}
+	if u, err := url.Parse(s.config.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(s.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: s.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, wrap(ErrLDAPUnavailable, err)
+	}
+	conn.SetTimeout(s.config.Timeout)
+
+	if s.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, wrap(ErrLDAPUnavailable, err)
+		}
+	}
+	if s.config.BindDN != "" {
+		if err := conn.Bind(s.config.BindDN, s.config.BindPassword); err != nil {
+			conn.Close()
+			return nil, wrap(ErrLDAPUnavailable, err)
+		}
+	}
+	return conn, nil
+}
+
+// search mencari entry user di bawah BaseDN, hasil dibaca per halaman
+func (s *LDAPService) search(conn *ldap.Conn, filter string) ([]*ldapEntry, error) {
+	request := ldap.NewSearchRequest(
+		s.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(s.config.Timeout.Seconds()), false,
+		filter,
+		[]string{s.config.IDAttribute, s.config.EmailAttribute, s.config.NameAttribute, s.config.GroupAttribute},
+		nil,
+	)
+	result, err := conn.SearchWithPaging(request, ldapPageSize)
+	if err != nil {
+		return nil, wrap(ErrLDAPUnavailable, err)
+	}
+
+	entries := make([]*ldapEntry, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		parsed := &ldapEntry{
+			DN:     entry.DN,
+			ID:     ldapID(entry.GetRawAttributeValue(s.config.IDAttribute)),
+			Email:  strings.TrimSpace(entry.GetAttributeValue(s.config.EmailAttribute)),
+			Name:   strings.TrimSpace(entry.GetAttributeValue(s.config.NameAttribute)),
+			Groups: entry.GetAttributeValues(s.config.GroupAttribute),
+		}
+		// Tanpa atribut ID, DN dipakai sebagai ID (user dianggap baru jika DN-nya berubah)
+		if parsed.ID == "" {
+			parsed.ID = strings.ToLower(entry.DN)
+		}
+		if parsed.Name == "" {
+			parsed.Name = parsed.Email
+		}
+		entries = append(entries, parsed)
+	}
+	return entries, nil
+}
+
+// ldapID mengubah nilai atribut ID menjadi string. ID biner (objectGUID di Active Directory)
+// ditulis sebagai hex.
+func ldapID(raw []byte) string {
+	for _, b := range raw {
+		if b < 0x20 || b > 0x7e {
+			return hex.EncodeToString(raw)
+		}
+	}
+	return string(raw)
+}
+
+// sameDN membandingkan dua DN tanpa membedakan huruf besar kecil dan spasi di antara komponen
+func sameDN(a, b string) bool {
+	dnA, errA := ldap.ParseDN(a)
+	dnB, errB := ldap.ParseDN(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return dnA.EqualFold(dnB)
+}
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// oauthSecretBytes adalah jumlah byte acak pada client secret, authorization code dan refresh token
+const oauthSecretBytes = 32
+
+// Kode error OAuth 2.0 (RFC 6749 bagian 4.1.2.1 dan 5.2)
+const (
+	OAuthInvalidRequest       = "invalid_request"
+	OAuthInvalidClient        = "invalid_client"
+	OAuthInvalidGrant         = "invalid_grant"
+	OAuthInvalidScope         = "invalid_scope"
+	OAuthUnauthorizedClient   = "unauthorized_client"
+	OAuthUnsupportedGrantType = "unsupported_grant_type"
+	OAuthUnsupportedResponse  = "unsupported_response_type"
+	OAuthAccessDenied         = "access_denied"
+)
+
+// OAuthError adalah error protokol OAuth 2.0. Jika RedirectURI diisi, error dikirim ke client
+// lewat redirect (endpoint authorize), selain itu sebagai JSON dari endpoint token.
+type OAuthError struct {
+	Code        string
+	Description string
+	RedirectURI string
+	State       string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// Status adalah HTTP status response error dari endpoint token
+func (e *OAuthError) Status() int {
+	if e.Code == OAuthInvalidClient {
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
+
+// RedirectTo adalah URL redirect client yang membawa error dan state, kosong jika error tidak di-redirect
+func (e *OAuthError) RedirectTo() string {
+	if e.RedirectURI == "" {
+		return ""
+	}
+	params := url.Values{"error": {e.Code}, "error_description": {e.Description}}
+	if e.State != "" {
+		params.Set("state", e.State)
+	}
+	return appendQuery(e.RedirectURI, params)
+}
+
+// oauthError membuat OAuthError yang dikirim sebagai JSON
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthService adalah authorization server OAuth 2.0: pendaftaran client, authorization code
+// dengan PKCE, client credentials, refresh token, pencabutan dan introspeksi token
+type OAuthService struct {
+	clients  repositories.OAuthClientRepository
+	grants   repositories.OAuthGrantRepository
+	codes    repositories.OAuthCodeRepository
+	consents repositories.OAuthConsentRepository
+	users    repositories.UserRepository
+	config   config.OAuthConfig
+	audit    *AuditService
+
+	// Key RS256 untuk ID token, lihat signingKey
+	keyOnce sync.Once
+	key     *rsa.PrivateKey
+	keyID   string
+	keyErr  error
+}
+
+// NewOAuthService membuat OAuthService baru
+func NewOAuthService(
+	clients repositories.OAuthClientRepository,
+	grants repositories.OAuthGrantRepository,
+	codes repositories.OAuthCodeRepository,
+	consents repositories.OAuthConsentRepository,
+	users repositories.UserRepository,
+	config config.OAuthConfig,
+	audit *AuditService,
+) *OAuthService {
+	s := &OAuthService{
+		clients:  clients,
+		grants:   grants,
+		codes:    codes,
+		consents: consents,
+		users:    users,
+		config:   config,
+		audit:    audit,
+	}
+	if config.SigningKey != nil {
+		s.useSigningKey(config.SigningKey)
+	}
+	return s
+}
+
+// ConsentURL adalah URL halaman persetujuan di frontend
+func (s *OAuthService) ConsentURL() string {
+	return s.config.ConsentURL
+}
+
+// CreateOAuthClientParams adalah data untuk mendaftarkan client baru
+type CreateOAuthClientParams struct {
+	Name         string
+	RedirectURIs []string
+	GrantTypes   []string // Kosong = authorization_code dan refresh_token
+	Scopes       []string
+	Confidential bool
+	FirstParty   bool
+}
+
+// NewOAuthClient adalah client yang baru didaftarkan. Secret hanya dikembalikan sekali ini.
+type NewOAuthClient struct {
+	ClientSecret string              `json:"client_secret,omitempty"`
+	Client       *models.OAuthClient `json:"client"`
+}
+
+// ListClients mengambil semua client yang terdaftar
+func (s *OAuthService) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	return s.clients.FindAll(ctx)
+}
+
+// CreateClient mendaftarkan client baru. Client confidential mendapat secret.
+func (s *OAuthService) CreateClient(ctx context.Context, params CreateOAuthClientParams) (*NewOAuthClient, error) {
+	grantTypes := params.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{models.GrantAuthorizationCode, models.GrantRefreshToken}
+	}
+	client := &models.OAuthClient{
+		ClientID:     utils.NewID(),
+		Name:         strings.TrimSpace(params.Name),
+		RedirectURIs: params.RedirectURIs,
+		GrantTypes:   uniqueStrings(grantTypes),
+		Confidential: params.Confidential,
+		FirstParty:   params.FirstParty,
+	}
+	if err := validateClient(client); err != nil {
+		return nil, err
+	}
+	scopes, err := normalizeScopes(params.Scopes, models.OAuthScopes)
+	if err != nil {
+		return nil, err
+	}
+	client.Scopes = scopes
+
+	result := &NewOAuthClient{Client: client}
+	if client.Confidential {
+		secret, err := utils.NewToken(oauthSecretBytes)
+		if err != nil {
+			return nil, wrap(ErrGenerateToken, err)
+		}
+		client.SecretHash = utils.HashToken(secret)
+		result.ClientSecret = secret
+	}
+	if err := s.clients.Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEntry{Action: "oauth_client.create", TargetType: "oauth_client", TargetID: client.ID, After: client})
+	return result, nil
+}
+
+// DeleteClient menghapus client beserta semua grant-nya. Access token yang masih berlaku ikut dicabut.
+func (s *OAuthService) DeleteClient(ctx context.Context, id uint) error {
+	client, err := s.clients.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrOAuthClientNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	grants, err := s.grants.FindActiveByClient(ctx, client.ID, utils.Now())
+	if err != nil {
+		return err
+	}
+	for i := range grants {
+		utils.RevokeGrant(grants[i].ID, s.revokedUntil(&grants[i]))
+	}
+	if err := s.clients.Delete(ctx, client); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditEntry{Action: "oauth_client.delete", TargetType: "oauth_client", TargetID: client.ID, Before: client})
+	return nil
+}
+
+// validateClient memeriksa grant type dan redirect URI client baru
+func validateClient(client *models.OAuthClient) error {
+	for _, grantType := range client.GrantTypes {
+		switch grantType {
+		case models.GrantAuthorizationCode, models.GrantRefreshToken:
+		case models.GrantClientCredentials:
+			// Client public tidak bisa menjaga secret, sehingga tidak boleh memakai client credentials
+			if !client.Confidential {
+				return ErrInvalidOAuthClient
+			}
+		default:
+			return ErrInvalidOAuthClient
+		}
+	}
+	if client.AllowsGrant(models.GrantAuthorizationCode) && len(client.RedirectURIs) == 0 {
+		return ErrInvalidRedirectURI
+	}
+	for _, uri := range client.RedirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" {
+			return ErrInvalidRedirectURI
+		}
+	}
+	return nil
+}
+
+// AuthorizeRequest adalah parameter endpoint authorize (RFC 6749 bagian 4.1.1 dan RFC 7636)
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string // OpenID Connect, dikembalikan apa adanya di ID token
+}
+
+// OAuthClientInfo adalah data client yang ditampilkan di layar persetujuan
+type OAuthClientInfo struct {
+	ClientID   string `json:"client_id"`
+	Name       string `json:"name"`
+	FirstParty bool   `json:"first_party"`
+}
+
+// AuthorizationPrompt adalah data untuk layar persetujuan
+type AuthorizationPrompt struct {
+	Client          OAuthClientInfo `json:"client"`
+	Scopes          []string        `json:"scopes"`
+	RedirectURI     string          `json:"redirect_uri"`
+	State           string          `json:"state"`
+	ConsentRequired bool            `json:"consent_required"` // false jika client first party atau scope sudah pernah disetujui
+}
+
+// Prepare memeriksa request authorize untuk user yang sedang login dan mengembalikan data layar persetujuan
+func (s *OAuthService) Prepare(ctx context.Context, userID uint, req AuthorizeRequest) (*AuthorizationPrompt, error) {
+	client, redirectURI, err := s.authorizeClient(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := s.authorizeScopes(client, req, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	consentRequired := !client.FirstParty
+	if consentRequired {
+		consent, err := s.consents.Find(ctx, userID, client.ID)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return nil, err
+		}
+		consentRequired = err != nil || !consent.Covers(scopes)
+	}
+
+	return &AuthorizationPrompt{
+		Client:          OAuthClientInfo{ClientID: client.ClientID, Name: client.Name, FirstParty: client.FirstParty},
+		Scopes:          scopes,
+		RedirectURI:     redirectURI,
+		State:           req.State,
+		ConsentRequired: consentRequired,
+	}, nil
+}
+
+// Approve menyelesaikan request authorize setelah user memilih di layar persetujuan dan
+// mengembalikan URL redirect client yang membawa authorization code, atau error access_denied
+// jika user menolak
+func (s *OAuthService) Approve(ctx context.Context, userID uint, req AuthorizeRequest, approved bool) (string, error) {
+	client, redirectURI, err := s.authorizeClient(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	scopes, err := s.authorizeScopes(client, req, redirectURI)
+	if err != nil {
+		return "", err
+	}
+	if !approved {
+		denied := &OAuthError{Code: OAuthAccessDenied, Description: "User menolak permintaan akses", RedirectURI: redirectURI, State: req.State}
+		return denied.RedirectTo(), nil
+	}
+
+	// Persetujuan disimpan agar layar persetujuan tidak muncul lagi untuk scope yang sama
+	if !client.FirstParty {
+		if err := s.rememberConsent(ctx, userID, client, scopes); err != nil {
+			return "", err
+		}
+	}
+
+	code, err := utils.NewToken(oauthSecretBytes)
+	if err != nil {
+		return "", wrap(ErrGenerateToken, err)
+	}
+	err = s.codes.Create(ctx, &models.OAuthAuthorizationCode{
+		CodeHash:      utils.HashToken(code),
+		OAuthClientID: client.ID,
+		UserID:        userID,
+		RedirectURI:   redirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+		ExpiresAt:     utils.Now().Add(s.config.CodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(redirectURI, params), nil
+}
+
+// authorizeClient mencari client dan memilih redirect URI. Error di sini tidak boleh di-redirect
+// karena redirect URI belum terbukti milik client (RFC 6749 bagian 4.1.2.1).
+func (s *OAuthService) authorizeClient(ctx context.Context, req AuthorizeRequest) (*models.OAuthClient, string, error) {
+	client, err := s.clients.FindByClientID(ctx, req.ClientID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, "", ErrOAuthClientNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		return nil, "", ErrInvalidRedirectURI
+	}
+	return client, redirectURI, nil
+}
+
+// authorizeScopes memeriksa sisa parameter authorize. Error di sini dikirim ke redirect URI client.
+func (s *OAuthService) authorizeScopes(client *models.OAuthClient, req AuthorizeRequest, redirectURI string) ([]string, error) {
+	fail := func(code, description string) error {
+		return &OAuthError{Code: code, Description: description, RedirectURI: redirectURI, State: req.State}
+	}
+
+	if req.ResponseType != "code" {
+		return nil, fail(OAuthUnsupportedResponse, "Hanya response_type=code yang didukung")
+	}
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return nil, fail(OAuthUnauthorizedClient, "Client tidak boleh memakai authorization code")
+	}
+	// PKCE wajib untuk semua client, hanya metode S256 yang diterima
+	if req.CodeChallengeMethod != "S256" || len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128 {
+		return nil, fail(OAuthInvalidRequest, "code_challenge dengan code_challenge_method=S256 wajib diisi")
+	}
+
+	scopes, ok := clientScopes(client, req.Scope)
+	if !ok {
+		return nil, fail(OAuthInvalidScope, "Scope tidak diizinkan untuk client")
+	}
+	return scopes, nil
+}
+
+// rememberConsent menambahkan scope ke persetujuan user untuk client
+func (s *OAuthService) rememberConsent(ctx context.Context, userID uint, client *models.OAuthClient, scopes []string) error {
+	consent, err := s.consents.Find(ctx, userID, client.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		consent = &models.OAuthConsent{UserID: userID, OAuthClientID: client.ID}
+	} else if err != nil {
+		return err
+	}
+	if consent.ID != 0 && consent.Covers(scopes) {
+		return nil
+	}
+
+	before := *consent
+	consent.Scopes = uniqueStrings(append(consent.Scopes, scopes...))
+	if err := s.consents.Save(ctx, consent); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "oauth.consent", TargetType: "oauth_client", TargetID: client.ID, Before: &before, After: consent})
+	return nil
+}
+
+// clientScopes mengubah parameter scope (dipisah spasi) menjadi daftar scope. Scope kosong berarti
+// semua scope client. false jika ada scope yang tidak diizinkan untuk client.
+func clientScopes(client *models.OAuthClient, scope string) ([]string, bool) {
+	requested := uniqueStrings(strings.Fields(scope))
+	if len(requested) == 0 {
+		return client.Scopes, len(client.Scopes) > 0
+	}
+	for _, s := range requested {
+		if !containsScope(client.Scopes, s) {
+			return nil, false
+		}
+	}
+	return requested, true
+}
+
+// verifyPKCE mencocokkan code_verifier dengan code_challenge S256 (RFC 7636 bagian 4.6)
+func verifyPKCE(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// appendQuery menambahkan parameter ke URL yang mungkin sudah memiliki query
+func appendQuery(rawURL string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + params.Encode()
+}
+
+// containsScope mengecek apakah daftar scope berisi scope tertentu
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueStrings membuang item duplikat, urutan dipertahankan
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
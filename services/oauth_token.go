@@ -0,0 +1,475 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// ClientCredentials adalah identitas client dari header Basic atau body request
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// TokenRequest adalah parameter endpoint token (RFC 6749 bagian 4.1.3, 4.4.2 dan 6)
+type TokenRequest struct {
+	Client       ClientCredentials
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// TokenResponse adalah response sukses endpoint token (RFC 6749 bagian 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+	IDToken      string `json:"id_token,omitempty"` // Hanya jika scope berisi openid
+}
+
+// Introspection adalah response endpoint introspeksi (RFC 7662 bagian 2.2)
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// Token menerbitkan token untuk grant authorization_code, refresh_token atau client_credentials
+func (s *OAuthService) Token(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case models.GrantAuthorizationCode:
+		return s.exchangeCode(ctx, client, req)
+	case models.GrantRefreshToken:
+		return s.refresh(ctx, client, req)
+	case models.GrantClientCredentials:
+		return s.clientCredentials(ctx, client, req)
+	case "":
+		return nil, oauthError(OAuthInvalidRequest, "grant_type wajib diisi")
+	default:
+		return nil, oauthError(OAuthUnsupportedGrantType, "grant_type tidak didukung")
+	}
+}
+
+// exchangeCode menukar authorization code dengan token setelah PKCE dicocokkan
+func (s *OAuthService) exchangeCode(ctx context.Context, client *models.OAuthClient, req TokenRequest) (*TokenResponse, error) {
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return nil, oauthError(OAuthUnauthorizedClient, "Client tidak boleh memakai authorization code")
+	}
+	invalid := oauthError(OAuthInvalidGrant, "Authorization code tidak valid atau sudah kadaluwarsa")
+
+	code, err := s.codes.FindByHash(ctx, utils.HashToken(req.Code))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if code.OAuthClientID != client.ID {
+		return nil, invalid
+	}
+	// Kode yang dipakai ulang menandakan kode bocor, semua token dari kode tersebut dicabut (RFC 6749 bagian 4.1.2)
+	if code.UsedAt != nil {
+		if code.GrantID != "" {
+			if err := s.revokeGrantByID(ctx, code.GrantID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, invalid
+	}
+	if !utils.Now().Before(code.ExpiresAt) || req.RedirectURI != code.RedirectURI || !verifyPKCE(code.CodeChallenge, req.CodeVerifier) {
+		return nil, invalid
+	}
+	if ok, err := s.codes.MarkUsed(ctx, code, utils.Now()); err != nil || !ok {
+		if err != nil {
+			return nil, err
+		}
+		return nil, invalid
+	}
+
+	user, err := s.users.FindByID(ctx, code.UserID)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && user.DeactivatedAt != nil) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	grant, response, err := s.issue(ctx, client, user, code.Scopes, nil, code.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	code.GrantID = grant.ID
+	if err := s.codes.Update(ctx, code); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// refresh menerbitkan access token baru dari refresh token. Refresh token selalu diganti (rotasi).
+func (s *OAuthService) refresh(ctx context.Context, client *models.OAuthClient, req TokenRequest) (*TokenResponse, error) {
+	if !client.AllowsGrant(models.GrantRefreshToken) {
+		return nil, oauthError(OAuthUnauthorizedClient, "Client tidak boleh memakai refresh token")
+	}
+	invalid := oauthError(OAuthInvalidGrant, "Refresh token tidak valid atau sudah kadaluwarsa")
+
+	hash := utils.HashToken(req.RefreshToken)
+	grant, err := s.grants.FindByRefreshHash(ctx, hash)
+	if errors.Is(err, repositories.ErrNotFound) {
+		if err := s.revokeReusedRefresh(ctx, client, hash); err != nil {
+			return nil, err
+		}
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if grant.OAuthClientID != client.ID || !grant.ActiveAt(utils.Now()) || grant.UserID == nil {
+		return nil, invalid
+	}
+	user, err := s.users.FindByID(ctx, *grant.UserID)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && user.DeactivatedAt != nil) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Scope boleh dipersempit, tidak boleh melebihi scope grant
+	scopes := grant.Scopes
+	if requested := uniqueStrings(strings.Fields(req.Scope)); len(requested) > 0 {
+		for _, scope := range requested {
+			if !containsScope(grant.Scopes, scope) {
+				return nil, oauthError(OAuthInvalidScope, "Scope melebihi scope yang disetujui")
+			}
+		}
+		scopes = requested
+	}
+
+	_, response, err := s.issue(ctx, client, user, scopes, grant, "")
+	return response, err
+}
+
+// revokeReusedRefresh mencabut grant jika refresh token yang dikirim adalah refresh token lama yang
+// sudah dirotasi. Pemakaian ulang menandakan refresh token bocor, sehingga pemilik sah maupun
+// penyerang harus login ulang (RFC 9700 bagian 4.14.2).
+func (s *OAuthService) revokeReusedRefresh(ctx context.Context, client *models.OAuthClient, hash string) error {
+	grant, err := s.grants.FindByPreviousRefreshHash(ctx, hash)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if grant.OAuthClientID != client.ID || grant.RevokedAt != nil {
+		return nil
+	}
+	return s.revokeGrant(ctx, grant)
+}
+
+// clientCredentials menerbitkan access token atas nama client sendiri, tanpa user dan refresh token
+func (s *OAuthService) clientCredentials(ctx context.Context, client *models.OAuthClient, req TokenRequest) (*TokenResponse, error) {
+	if !client.Confidential || !client.AllowsGrant(models.GrantClientCredentials) {
+		return nil, oauthError(OAuthUnauthorizedClient, "Client tidak boleh memakai client credentials")
+	}
+	scopes, ok := clientScopes(client, req.Scope)
+	if !ok {
+		return nil, oauthError(OAuthInvalidScope, "Scope tidak diizinkan untuk client")
+	}
+	_, response, err := s.issue(ctx, client, nil, scopes, nil, "")
+	return response, err
+}
+
+// issue menerbitkan access token (JWT), refresh token dan ID token jika scope berisi openid.
+// grant nil berarti grant baru dibuat, selain itu refresh token pada grant tersebut diganti.
+func (s *OAuthService) issue(ctx context.Context, client *models.OAuthClient, user *models.User, scopes []string, grant *models.OAuthGrant, nonce string) (*models.OAuthGrant, *TokenResponse, error) {
+	now := utils.Now()
+	withRefresh := user != nil && client.AllowsGrant(models.GrantRefreshToken)
+
+	isNew := grant == nil
+	if isNew {
+		grant = &models.OAuthGrant{
+			ID:            utils.NewID(),
+			OAuthClientID: client.ID,
+			Scopes:        scopes,
+			ExpiresAt:     now.Add(s.config.AccessTokenTTL),
+		}
+		if user != nil {
+			grant.UserID = &user.ID
+		}
+		if withRefresh {
+			grant.ExpiresAt = now.Add(s.config.RefreshTokenTTL)
+		}
+	}
+
+	response := &TokenResponse{
+		TokenType: "Bearer",
+		ExpiresIn: int(s.config.AccessTokenTTL / time.Second),
+		Scope:     strings.Join(scopes, " "),
+	}
+	if withRefresh {
+		refreshToken, err := utils.NewToken(oauthSecretBytes)
+		if err != nil {
+			return nil, nil, wrap(ErrGenerateToken, err)
+		}
+		if !isNew {
+			grant.PreviousRefreshHash = grant.RefreshTokenHash
+		}
+		grant.RefreshTokenHash = utils.HashToken(refreshToken)
+		response.RefreshToken = refreshToken
+	}
+
+	var err error
+	if response.AccessToken, err = s.accessToken(client, user, grant.ID, scopes, now); err != nil {
+		return nil, nil, wrap(ErrGenerateToken, err)
+	}
+	if user != nil && containsScope(scopes, models.ScopeOpenID) {
+		if response.IDToken, err = s.idToken(client, user, scopes, nonce, response.AccessToken, now); err != nil {
+			return nil, nil, wrap(ErrGenerateToken, err)
+		}
+	}
+
+	if isNew {
+		err = s.grants.Create(ctx, grant)
+	} else {
+		err = s.grants.Update(ctx, grant)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return grant, response, nil
+}
+
+// accessToken menandatangani access token OAuth. Claim "id" dan "email" sama dengan JWT login
+// sehingga JWTAuth mengenali user, "scope" membatasi endpoint yang boleh dipakai.
+func (s *OAuthService) accessToken(client *models.OAuthClient, user *models.User, grantID string, scopes []string, now time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"iss":       s.config.Issuer,
+		"sub":       client.ClientID,
+		"client_id": client.ClientID,
+		"scope":     strings.Join(scopes, " "),
+		"gid":       grantID,
+		"jti":       utils.NewID(),
+		"iat":       now.Unix(),
+		"exp":       now.Add(s.config.AccessTokenTTL).Unix(),
+	}
+	if user != nil {
+		claims["sub"] = strconv.FormatUint(uint64(user.ID), 10)
+		claims["id"] = user.ID
+		claims["email"] = user.Email
+	}
+	return utils.SignJWT(claims)
+}
+
+// Revoke mencabut refresh token atau access token milik client (RFC 7009). Token yang tidak
+// dikenal atau milik client lain diabaikan tanpa error.
+func (s *OAuthService) Revoke(ctx context.Context, credentials ClientCredentials, token string) error {
+	client, err := s.authenticateClient(ctx, credentials)
+	if err != nil {
+		return err
+	}
+
+	// Refresh token: seluruh grant dicabut, termasuk access token yang diterbitkan darinya
+	grant, err := s.grants.FindByRefreshHash(ctx, utils.HashToken(token))
+	if err == nil {
+		if grant.OAuthClientID == client.ID && grant.RevokedAt == nil {
+			return s.revokeGrant(ctx, grant)
+		}
+		return nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+
+	// Access token: hanya token itu yang masuk blacklist sampai kadaluwarsa
+	claims, ok := s.parseAccessToken(token)
+	if !ok || claims["client_id"] != client.ClientID {
+		return nil
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		utils.AddToBlacklist(token, exp.Time)
+	}
+	return nil
+}
+
+// Introspect mengembalikan status token untuk resource server (RFC 7662). Hanya client
+// confidential yang boleh melakukan introspeksi.
+func (s *OAuthService) Introspect(ctx context.Context, credentials ClientCredentials, token string) (*Introspection, error) {
+	client, err := s.authenticateClient(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+	if !client.Confidential {
+		return nil, oauthError(OAuthInvalidClient, "Hanya client confidential yang boleh melakukan introspeksi")
+	}
+	inactive := &Introspection{Active: false}
+
+	// Access token
+	if claims, ok := s.parseAccessToken(token); ok {
+		grantID, _ := claims["gid"].(string)
+		if utils.IsBlacklisted(token) || !s.grantActive(ctx, grantID) {
+			return inactive, nil
+		}
+		result := &Introspection{Active: true, TokenType: "Bearer"}
+		result.Scope, _ = claims["scope"].(string)
+		result.ClientID, _ = claims["client_id"].(string)
+		result.Username, _ = claims["email"].(string)
+		result.Sub, _ = claims["sub"].(string)
+		result.Iss, _ = claims["iss"].(string)
+		result.Jti, _ = claims["jti"].(string)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			result.Exp = exp.Unix()
+		}
+		if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+			result.Iat = iat.Unix()
+		}
+		return result, nil
+	}
+
+	// Refresh token
+	grant, err := s.grants.FindByRefreshHash(ctx, utils.HashToken(token))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !grant.ActiveAt(utils.Now()) {
+		return inactive, nil
+	}
+	owner, err := s.clients.FindByID(ctx, grant.OAuthClientID)
+	if err != nil {
+		return inactive, nil
+	}
+	result := &Introspection{
+		Active:    true,
+		Scope:     strings.Join(grant.Scopes, " "),
+		ClientID:  owner.ClientID,
+		TokenType: "refresh_token",
+		Exp:       grant.ExpiresAt.Unix(),
+		Iat:       grant.UpdatedAt.Unix(),
+		Iss:       s.config.Issuer,
+	}
+	if grant.UserID != nil {
+		result.Sub = strconv.FormatUint(uint64(*grant.UserID), 10)
+		if user, err := s.users.FindByID(ctx, *grant.UserID); err == nil {
+			result.Username = user.Email
+		}
+	}
+	return result, nil
+}
+
+// authenticateClient memeriksa client_id dan client_secret. Client public cukup mengirim client_id.
+func (s *OAuthService) authenticateClient(ctx context.Context, credentials ClientCredentials) (*models.OAuthClient, error) {
+	invalid := oauthError(OAuthInvalidClient, "Autentikasi client gagal")
+	if credentials.ClientID == "" {
+		return nil, invalid
+	}
+
+	client, err := s.clients.FindByClientID(ctx, credentials.ClientID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Confidential {
+		hash := utils.HashToken(credentials.ClientSecret)
+		if credentials.ClientSecret == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+			return nil, invalid
+		}
+	} else if credentials.ClientSecret != "" {
+		return nil, invalid
+	}
+	return client, nil
+}
+
+// parseAccessToken mem-parse access token OAuth yang masih berlaku, false jika bukan access token OAuth
+func (s *OAuthService) parseAccessToken(token string) (jwt.MapClaims, bool) {
+	parsed, err := utils.ParseJWT(token)
+	if err != nil || !parsed.Valid {
+		return nil, false
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := claims["client_id"].(string); !ok {
+		return nil, false
+	}
+	return claims, true
+}
+
+// grantActive mengecek apakah grant masih ada, belum dicabut dan belum kadaluwarsa
+func (s *OAuthService) grantActive(ctx context.Context, grantID string) bool {
+	if grantID == "" || utils.IsGrantRevoked(grantID) {
+		return false
+	}
+	grant, err := s.grants.FindByID(ctx, grantID)
+	return err == nil && grant.RevokedAt == nil
+}
+
+// revokeGrantByID mencabut grant berdasarkan ID, grant yang tidak ada atau sudah dicabut diabaikan
+func (s *OAuthService) revokeGrantByID(ctx context.Context, grantID string) error {
+	grant, err := s.grants.FindByID(ctx, grantID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if grant.RevokedAt != nil {
+		return nil
+	}
+	return s.revokeGrant(ctx, grant)
+}
+
+// revokeGrant menandai grant dicabut dan memasukkannya ke blacklist agar access token-nya ikut ditolak
+func (s *OAuthService) revokeGrant(ctx context.Context, grant *models.OAuthGrant) error {
+	before := *grant
+	now := utils.Now()
+	grant.RevokedAt = &now
+	grant.RefreshTokenHash = ""
+	if err := s.grants.Update(ctx, grant); err != nil {
+		return err
+	}
+	utils.RevokeGrant(grant.ID, s.revokedUntil(grant))
+	s.audit.Record(ctx, AuditEntry{Action: "oauth_grant.revoke", TargetType: "oauth_grant", TargetID: grant.ID, Before: &before, After: grant})
+	return nil
+}
+
+// revokedUntil adalah batas waktu entry blacklist grant: access token terakhir bisa berlaku
+// sampai satu masa access token setelah sekarang, meskipun grant sudah kadaluwarsa lebih dulu
+func (s *OAuthService) revokedUntil(grant *models.OAuthGrant) time.Time {
+	until := utils.Now().Add(s.config.AccessTokenTTL)
+	if grant.ExpiresAt.After(until) {
+		return grant.ExpiresAt
+	}
+	return until
+}
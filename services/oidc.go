@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+)
+
+// OIDCDiscovery adalah dokumen /.well-known/openid-configuration (OpenID Connect Discovery 1.0)
+type OIDCDiscovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// JSONWebKey adalah public key RSA dalam format JWK (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JSONWebKeySet adalah response /oauth/jwks
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Discovery mengembalikan metadata provider OpenID Connect
+func (s *OAuthService) Discovery() OIDCDiscovery {
+	issuer := s.config.Issuer
+	return OIDCDiscovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserinfoEndpoint:                  issuer + "/oauth/userinfo",
+		JWKSURI:                           issuer + "/oauth/jwks",
+		RevocationEndpoint:                issuer + "/oauth/revoke",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		ScopesSupported:                   models.OAuthScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{models.GrantAuthorizationCode, models.GrantRefreshToken, models.GrantClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "nonce", "at_hash", "name", "updated_at", "email", "roles"},
+	}
+}
+
+// JWKS mengembalikan public key untuk memverifikasi ID token
+func (s *OAuthService) JWKS() (*JSONWebKeySet, error) {
+	key, kid, err := s.signingKey()
+	if err != nil {
+		return nil, wrap(ErrGenerateToken, err)
+	}
+	jwk := publicJWK(&key.PublicKey)
+	jwk.Kid = kid
+	return &JSONWebKeySet{Keys: []JSONWebKey{jwk}}, nil
+}
+
+// UserInfo mengembalikan claim user sesuai scope access token (OpenID Connect Core bagian 5.3)
+func (s *OAuthService) UserInfo(ctx context.Context, userID uint, scopes []string) (map[string]interface{}, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return userClaims(user, scopes), nil
+}
+
+// idToken menandatangani ID token (RS256) untuk user. at_hash mengikat ID token ke access token
+// yang diterbitkan bersamanya (OpenID Connect Core bagian 3.1.3.6).
+func (s *OAuthService) idToken(client *models.OAuthClient, user *models.User, scopes []string, nonce, accessToken string, now time.Time) (string, error) {
+	key, kid, err := s.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"iss":     s.config.Issuer,
+		"aud":     client.ClientID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(s.config.IDTokenTTL).Unix(),
+		"at_hash": halfHash(accessToken),
+	}
+	for name, value := range userClaims(user, scopes) {
+		claims[name] = value
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
+
+// userClaims mengubah data user menjadi claim standar sesuai scope
+func userClaims(user *models.User, scopes []string) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub": strconv.FormatUint(uint64(user.ID), 10),
+	}
+	if containsScope(scopes, models.ScopeProfile) {
+		claims["name"] = user.Name
+		claims["updated_at"] = user.UpdatedAt.Unix()
+	}
+	if containsScope(scopes, models.ScopeEmail) {
+		claims["email"] = user.Email
+	}
+	if containsScope(scopes, models.ScopeRoles) {
+		roles := []string{}
+		if user.Role.Name != "" {
+			roles = append(roles, user.Role.Name)
+		}
+		claims["roles"] = roles
+	}
+	return claims
+}
+
+// signingKey mengembalikan key ID token beserta kid-nya. Jika OIDC_SIGNING_KEY tidak diisi,
+// key sementara dibuat sekali dan hanya berlaku sampai aplikasi restart.
+func (s *OAuthService) signingKey() (*rsa.PrivateKey, string, error) {
+	s.keyOnce.Do(func() {
+		log.Println("[oidc] OIDC_SIGNING_KEY kosong, memakai key sementara (ID token tidak berlaku setelah restart)")
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			s.keyErr = err
+			return
+		}
+		s.setSigningKey(key)
+	})
+	return s.key, s.keyID, s.keyErr
+}
+
+// useSigningKey memasang key dari konfigurasi sehingga signingKey tidak membuat key sementara
+func (s *OAuthService) useSigningKey(key *rsa.PrivateKey) {
+	s.keyOnce.Do(func() { s.setSigningKey(key) })
+}
+
+// setSigningKey menyimpan key beserta kid berupa JWK thumbprint (RFC 7638)
+func (s *OAuthService) setSigningKey(key *rsa.PrivateKey) {
+	jwk := publicJWK(&key.PublicKey)
+	sum := sha256.Sum256([]byte(`{"e":"` + jwk.E + `","kty":"RSA","n":"` + jwk.N + `"}`))
+	s.key = key
+	s.keyID = base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// publicJWK mengubah public key RSA menjadi JWK tanpa kid
+func publicJWK(key *rsa.PublicKey) JSONWebKey {
+	return JSONWebKey{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// halfHash adalah base64url dari separuh kiri SHA-256, format at_hash untuk RS256
+func halfHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
@@ -0,0 +1,21 @@
+package services
+
+// Batas pagination untuk endpoint yang memakai ?page= dan ?per_page=
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// paginate menormalkan page (dimulai dari 1) dan perPage lalu menghitung offset query
+func paginate(page, perPage int) (int, int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	return page, perPage, (page - 1) * perPage
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ccojocar/zxcvbn-go"
+	"golang-starter-kit/breach"
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// Nama aturan pada PasswordViolation, bisa dipakai frontend untuk menandai aturan yang gagal
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleMaxLength = "max_length"
+	PasswordRuleClass     = "class"
+	PasswordRuleStrength  = "strength"
+	PasswordRulePersonal  = "personal"
+	PasswordRuleHistory   = "history"
+	PasswordRuleBreached  = "breached"
+)
+
+// personalTokenMinLength adalah panjang minimal bagian nama/email yang dicek, agar
+// potongan pendek seperti "a" atau "id" tidak membuat hampir semua password ditolak
+const personalTokenMinLength = 3
+
+// passwordClassNames adalah nama jenis karakter untuk pesan pelanggaran
+var passwordClassNames = map[string]string{
+	config.PasswordClassLower:  "huruf kecil",
+	config.PasswordClassUpper:  "huruf besar",
+	config.PasswordClassDigit:  "angka",
+	config.PasswordClassSymbol: "simbol",
+}
+
+// PasswordViolation adalah satu aturan kebijakan password yang tidak terpenuhi
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordSubject adalah data user pemilik password, dipakai untuk aturan personal dan riwayat.
+// UserID = 0 berarti user baru (belum punya riwayat).
+type PasswordSubject struct {
+	UserID      uint
+	Name        string
+	Email       string
+	CurrentHash string // Hash password saat ini, ikut dicek sebagai riwayat
+}
+
+// subjectOf membuat PasswordSubject dari user
+func subjectOf(user *models.User) PasswordSubject {
+	return PasswordSubject{UserID: user.ID, Name: user.Name, Email: user.Email, CurrentHash: user.Password}
+}
+
+// PasswordPolicy memeriksa password terhadap kebijakan dan menyimpan riwayat password
+type PasswordPolicy struct {
+	config   config.PasswordPolicyConfig
+	history  repositories.PasswordHistoryRepository
+	breached breach.Checker
+}
+
+// NewPasswordPolicy membuat PasswordPolicy baru. breached boleh nil jika dataset password bocor tidak dipakai.
+func NewPasswordPolicy(
+	config config.PasswordPolicyConfig,
+	history repositories.PasswordHistoryRepository,
+	breached breach.Checker,
+) *PasswordPolicy {
+	return &PasswordPolicy{config: config, history: history, breached: breached}
+}
+
+// Validate mengembalikan ErrPasswordPolicy berisi semua aturan yang dilanggar, nil jika password memenuhi kebijakan
+func (p *PasswordPolicy) Validate(ctx context.Context, password string, subject PasswordSubject) error {
+	violations, err := p.Check(ctx, password, subject)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return withDetails(ErrPasswordPolicy, violations)
+	}
+	return nil
+}
+
+// Check memeriksa semua aturan dan mengembalikan daftar aturan yang dilanggar (bukan hanya yang pertama)
+func (p *PasswordPolicy) Check(ctx context.Context, password string, subject PasswordSubject) ([]PasswordViolation, error) {
+	var violations []PasswordViolation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		add(PasswordRuleMinLength, "Password minimal %d karakter", p.config.MinLength)
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		add(PasswordRuleMaxLength, "Password maksimal %d karakter", p.config.MaxLength)
+	}
+
+	present := passwordClasses(password)
+	for _, class := range p.config.RequiredClasses {
+		if !present[class] {
+			add(PasswordRuleClass, "Password harus mengandung %s", passwordClassNames[class])
+		}
+	}
+
+	personal := personalTokens(subject)
+	if p.config.DisallowPersonal && containsAny(strings.ToLower(password), personal) {
+		add(PasswordRulePersonal, "Password tidak boleh mengandung nama atau email")
+	}
+
+	if p.config.MinScore > 0 {
+		// Bagian nama dan email ikut dianggap kata yang mudah ditebak
+		if zxcvbn.PasswordStrength(password, personal).Score < p.config.MinScore {
+			add(PasswordRuleStrength, "Password terlalu mudah ditebak, gunakan kombinasi kata atau karakter yang lebih panjang")
+		}
+	}
+
+	if p.breached != nil {
+		found, err := p.breached.Breached(password)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			add(PasswordRuleBreached, "Password ini pernah muncul dalam kebocoran data, gunakan password lain")
+		}
+	}
+
+	reused, err := p.reused(ctx, password, subject)
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		add(PasswordRuleHistory, "Password sudah dipakai dalam %d password terakhir", p.config.History)
+	}
+	return violations, nil
+}
+
+// Remember menyimpan hash password baru ke riwayat user dan menghapus riwayat yang lebih lama dari batas
+func (p *PasswordPolicy) Remember(ctx context.Context, userID uint, hash string) error {
+	if p.config.History == 0 {
+		return nil
+	}
+	if err := p.history.Create(ctx, &models.PasswordHistory{UserID: userID, Hash: hash}); err != nil {
+		return err
+	}
+	return p.history.Prune(ctx, userID, p.config.History)
+}
+
+// reused mengecek apakah password sama dengan password saat ini atau salah satu password terakhir user
+func (p *PasswordPolicy) reused(ctx context.Context, password string, subject PasswordSubject) (bool, error) {
+	if p.config.History == 0 || subject.UserID == 0 {
+		return false, nil
+	}
+	hashes, err := p.history.RecentHashes(ctx, subject.UserID, p.config.History)
+	if err != nil {
+		return false, err
+	}
+	if subject.CurrentHash != "" {
+		hashes = append(hashes, subject.CurrentHash)
+	}
+	for _, hash := range hashes {
+		if ok, _ := utils.VerifyPassword(hash, password); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// passwordClasses mengembalikan jenis karakter yang ada di password
+func passwordClasses(password string) map[string]bool {
+	present := map[string]bool{}
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			present[config.PasswordClassLower] = true
+		case unicode.IsUpper(r):
+			present[config.PasswordClassUpper] = true
+		case unicode.IsDigit(r):
+			present[config.PasswordClassDigit] = true
+		case !unicode.IsSpace(r) && !unicode.IsLetter(r):
+			present[config.PasswordClassSymbol] = true
+		}
+	}
+	return present
+}
+
+// personalTokens memecah nama dan email user menjadi potongan kata (huruf kecil)
+func personalTokens(subject PasswordSubject) []string {
+	local, _, _ := strings.Cut(subject.Email, "@")
+	split := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+
+	var tokens []string
+	for _, field := range append(strings.FieldsFunc(subject.Name, split), strings.FieldsFunc(local, split)...) {
+		if utf8.RuneCountInString(field) >= personalTokenMinLength {
+			tokens = append(tokens, strings.ToLower(field))
+		}
+	}
+	return tokens
+}
+
+// containsAny mengecek apakah s mengandung salah satu token
+func containsAny(s string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.Contains(s, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// violatedRules mengembalikan nama aturan yang dilanggar secara berurutan
+func violatedRules(t *testing.T, policy *services.PasswordPolicy, password string, subject services.PasswordSubject) []string {
+	t.Helper()
+	violations, err := policy.Check(context.Background(), password, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := make([]string, len(violations))
+	for i, violation := range violations {
+		rules[i] = violation.Rule
+	}
+	return rules
+}
+
+func TestPasswordPolicyReportsAllViolations(t *testing.T) {
+	h := testutil.New(t)
+	policy := services.NewPasswordPolicy(config.PasswordPolicyConfig{
+		MinLength:        10,
+		MaxLength:        20,
+		RequiredClasses:  []string{config.PasswordClassUpper, config.PasswordClassDigit, config.PasswordClassSymbol},
+		MinScore:         3,
+		DisallowPersonal: true,
+	}, repositories.NewPasswordHistoryRepository(h.DB), nil)
+	subject := services.PasswordSubject{Name: "Budi Santoso", Email: "budi.s@example.com"}
+
+	cases := []struct {
+		password string
+		want     []string
+	}{
+		{"budi", []string{"min_length", "class", "class", "class", "personal", "strength"}},
+		{"Santoso#2024", []string{"personal", "strength"}},
+		{"Kuda lari di pantai 7 senja!", []string{"max_length"}},
+		{"Kuda-lari-7-senja!", nil},
+	}
+	for _, tc := range cases {
+		got := violatedRules(t, policy, tc.password, subject)
+		if len(got) != len(tc.want) {
+			t.Fatalf("%q: rules = %v, want %v", tc.password, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%q: rules = %v, want %v", tc.password, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestPasswordPolicyHistory(t *testing.T) {
+	h := testutil.New(t)
+	user := h.CreateUser(t, testutil.UserAttrs{})
+	policy := services.NewPasswordPolicy(config.PasswordPolicyConfig{MinLength: 1, History: 2},
+		repositories.NewPasswordHistoryRepository(h.DB), nil)
+	ctx := context.Background()
+
+	for _, password := range []string{"pertama1", "kedua2", "ketiga3"} {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := policy.Remember(ctx, user.ID, string(hash)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Hanya 2 password terakhir yang disimpan
+	var count int64
+	h.DB.Model(&models.PasswordHistory{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 2 {
+		t.Fatalf("history rows = %d, want 2", count)
+	}
+
+	subject := services.PasswordSubject{UserID: user.ID, CurrentHash: user.Password}
+	for password, reused := range map[string]bool{
+		"ketiga3":                true,
+		"kedua2":                 true,
+		"pertama1":               false,
+		testutil.DefaultPassword: true, // Password saat ini selalu dicek
+	} {
+		rules := violatedRules(t, policy, password, subject)
+		if (len(rules) == 1 && rules[0] == services.PasswordRuleHistory) != reused {
+			t.Fatalf("%q: rules = %v, want reused = %v", password, rules, reused)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/mailer"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// passwordlessTokenPurpose membedakan token magic link dari token bertanda tangan lain
+const passwordlessTokenPurpose = "passwordless_login"
+
+// PasswordlessService berisi aturan bisnis login tanpa password: user meminta magic link atau
+// kode 6 digit ke email, lalu menukarnya dengan JWT yang sama dengan hasil Login
+type PasswordlessService struct {
+	users    repositories.UserRepository
+	codes    repositories.LoginCodeRepository
+	auth     *AuthService
+	sessions *SessionService
+	mailer   mailer.Mailer
+	config   config.PasswordlessConfig
+}
+
+// NewPasswordlessService membuat PasswordlessService baru
+func NewPasswordlessService(
+	users repositories.UserRepository,
+	codes repositories.LoginCodeRepository,
+	auth *AuthService,
+	sessions *SessionService,
+	mail mailer.Mailer,
+	cfg config.PasswordlessConfig,
+) *PasswordlessService {
+	return &PasswordlessService{
+		users:    users,
+		codes:    codes,
+		auth:     auth,
+		sessions: sessions,
+		mailer:   mail,
+		config:   cfg,
+	}
+}
+
+// PasswordlessRequestParams adalah data permintaan magic link atau kode login
+type PasswordlessRequestParams struct {
+	Email  string
+	Method string // models.LoginCodeLink atau models.LoginCodeOTP
+	Locale string
+}
+
+// Request mengirim magic link atau kode login ke email user. Link dan kode lama yang belum dipakai
+// tidak berlaku lagi. Email yang tidak terdaftar, akun nonaktif, role yang tidak diizinkan dan
+// permintaan ulang sebelum jeda PASSWORDLESS_RESEND_SECONDS tidak menghasilkan error agar response
+// tidak membocorkan email mana yang terdaftar. User yang sedang terkunci karena terlalu banyak kode salah
+// juga tidak dikirimi link atau kode baru.
+func (s *PasswordlessService) Request(ctx context.Context, params PasswordlessRequestParams) error {
+	if !s.config.Enabled {
+		return ErrPasswordlessDisabled
+	}
+	if params.Method != models.LoginCodeLink && params.Method != models.LoginCodeOTP {
+		return ErrInvalidLoginMethod
+	}
+
+	user, err := s.users.FindByEmail(ctx, strings.TrimSpace(params.Email))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.DeactivatedAt != nil || !s.config.AllowsRole(user.Role.Name) {
+		return nil
+	}
+	locked, err := s.lockedOut(ctx, user.ID)
+	if err != nil || locked {
+		return err
+	}
+
+	latest, err := s.codes.FindLatestByUser(ctx, user.ID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+	if err == nil && utils.Now().Before(latest.CreatedAt.Add(s.config.ResendInterval)) {
+		return nil
+	}
+
+	if err := s.codes.DeletePendingByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	secret, err := newLoginSecret(params.Method)
+	if err != nil {
+		return err
+	}
+	code := &models.LoginCode{
+		UserID:    user.ID,
+		Method:    params.Method,
+		CodeHash:  loginCodeHash(user.ID, secret),
+		ExpiresAt: utils.Now().Add(s.config.TTL),
+	}
+	if err := s.codes.Create(ctx, code); err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{"Name": user.Name, "ExpiresAt": code.ExpiresAt}
+	name := "login_code"
+	if params.Method == models.LoginCodeLink {
+		token := utils.SignToken(passwordlessTokenPurpose, fmt.Sprintf("%d:%s", code.ID, secret), code.ExpiresAt)
+		data["Link"] = s.config.LinkURL + "?token=" + url.QueryEscape(token)
+		name = "login_link"
+	} else {
+		data["Code"] = secret
+	}
+	return s.send(ctx, params.Locale, name, user.Email, data)
+}
+
+// LoginWithLink menukar token magic link dengan JWT
+func (s *PasswordlessService) LoginWithLink(ctx context.Context, token string) (*LoginResult, error) {
+	if !s.config.Enabled {
+		return nil, ErrPasswordlessDisabled
+	}
+
+	payload, err := utils.VerifySignedToken(passwordlessTokenPurpose, token)
+	if err != nil {
+		return nil, ErrPasswordlessInvalid
+	}
+	idPart, nonce, ok := strings.Cut(payload, ":")
+	id, parseErr := strconv.ParseUint(idPart, 10, 64)
+	if !ok || parseErr != nil {
+		return nil, ErrPasswordlessInvalid
+	}
+
+	code, err := s.codes.FindByID(ctx, uint(id))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrPasswordlessInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if code.Method != models.LoginCodeLink || !matchesLoginCode(code, nonce) {
+		return nil, ErrPasswordlessInvalid
+	}
+	user, err := s.users.FindByID(ctx, code.UserID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrPasswordlessInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.redeem(ctx, user, code)
+}
+
+// LoginWithCode menukar kode 6 digit dengan JWT. Setiap percobaan mengurangi sisa percobaan kode,
+// setelah PASSWORDLESS_MAX_ATTEMPTS kali salah kode tidak bisa dipakai dan user harus meminta kode baru.
+// Karena kode baru bisa diminta setiap PASSWORDLESS_RESEND_SECONDS, kode salah juga dihitung per user:
+// setelah PASSWORDLESS_LOCKOUT_ATTEMPTS kali salah dalam PASSWORDLESS_LOCKOUT_MINUTES terakhir semua
+// kode ditolak sampai percobaan lama keluar dari rentang waktu tersebut.
+func (s *PasswordlessService) LoginWithCode(ctx context.Context, email, value string) (*LoginResult, error) {
+	if !s.config.Enabled {
+		return nil, ErrPasswordlessDisabled
+	}
+
+	email = strings.TrimSpace(email)
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		s.sessions.RecordAttempt(ctx, email, nil, models.LoginFailedUnknownEmail, "")
+		return nil, ErrPasswordlessInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := s.lockedOut(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if locked {
+		s.sessions.RecordAttempt(ctx, email, &user.ID, models.LoginFailedInvalidCode, "")
+		return nil, ErrPasswordlessInvalid
+	}
+
+	code, err := s.codes.FindLatestByUser(ctx, user.ID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil || code.Method != models.LoginCodeOTP || code.UsedAt != nil || !utils.Now().Before(code.ExpiresAt) {
+		s.sessions.RecordAttempt(ctx, email, &user.ID, models.LoginFailedInvalidCode, "")
+		return nil, ErrPasswordlessInvalid
+	}
+
+	// Percobaan dihitung sebelum kode dicocokkan agar request paralel tidak bisa melewati batas
+	counted, err := s.codes.AddAttempt(ctx, code, s.config.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !counted || !matchesLoginCode(code, strings.TrimSpace(value)) {
+		s.sessions.RecordAttempt(ctx, email, &user.ID, models.LoginFailedInvalidCode, "")
+		return nil, ErrPasswordlessInvalid
+	}
+	return s.redeem(ctx, user, code)
+}
+
+// redeem menandai link atau kode sudah dipakai lalu menerbitkan JWT lewat alur login biasa.
+// Role diperiksa lagi karena bisa saja berubah setelah link atau kode dikirim.
+func (s *PasswordlessService) redeem(ctx context.Context, user *models.User, code *models.LoginCode) (*LoginResult, error) {
+	if code.UsedAt != nil || !utils.Now().Before(code.ExpiresAt) || !s.config.AllowsRole(user.Role.Name) {
+		s.sessions.RecordAttempt(ctx, user.Email, &user.ID, models.LoginFailedInvalidCode, "")
+		return nil, ErrPasswordlessInvalid
+	}
+	used, err := s.codes.MarkUsed(ctx, code, utils.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		s.sessions.RecordAttempt(ctx, user.Email, &user.ID, models.LoginFailedInvalidCode, "")
+		return nil, ErrPasswordlessInvalid
+	}
+	return s.auth.completeLogin(ctx, user.Email, user)
+}
+
+// lockedOut menandakan user sudah mencapai batas kode salah dalam rentang waktu PASSWORDLESS_LOCKOUT_MINUTES
+func (s *PasswordlessService) lockedOut(ctx context.Context, userID uint) (bool, error) {
+	failed, err := s.sessions.FailedAttemptsSince(ctx, userID, models.LoginFailedInvalidCode, utils.Now().Add(-s.config.LockoutWindow))
+	if err != nil {
+		return false, err
+	}
+	return failed >= int64(s.config.LockoutLimit), nil
+}
+
+// send merender template email lalu mengirimnya
+func (s *PasswordlessService) send(ctx context.Context, locale, name, to string, data map[string]interface{}) error {
+	msg, err := mailer.Compose(locale, name, to, data)
+	if err != nil {
+		return wrap(ErrSendLoginCode, err)
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return wrap(ErrSendLoginCode, err)
+	}
+	return nil
+}
+
+// newLoginSecret membuat nonce untuk magic link atau kode 6 digit acak untuk login dengan kode
+func newLoginSecret(method string) (string, error) {
+	if method == models.LoginCodeLink {
+		return utils.NewToken(24)
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", wrap(ErrGenerateToken, err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// loginCodeHash menghasilkan hash yang disimpan di database. ID user ikut di-hash agar kode 6 digit
+// yang sama milik user berbeda tidak menghasilkan hash yang sama.
+func loginCodeHash(userID uint, secret string) string {
+	return utils.HashToken(fmt.Sprintf("%d:%s", userID, secret))
+}
+
+// matchesLoginCode mencocokkan nonce atau kode dengan hash yang tersimpan
+func matchesLoginCode(code *models.LoginCode, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(loginCodeHash(code.UserID, secret)), []byte(code.CodeHash)) == 1
+}
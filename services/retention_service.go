@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// RetentionService menghapus permanen data yang sudah di-soft delete lebih lama dari masa retensi
+type RetentionService struct {
+	users     repositories.UserRepository
+	roles     repositories.RoleRepository
+	retention time.Duration
+}
+
+// NewRetentionService membuat RetentionService dengan masa retensi tertentu
+func NewRetentionService(users repositories.UserRepository, roles repositories.RoleRepository, retention time.Duration) *RetentionService {
+	return &RetentionService{users: users, roles: roles, retention: retention}
+}
+
+// RetentionResult adalah jumlah baris yang dihapus permanen oleh Run
+type RetentionResult struct {
+	Users int64 `json:"users"`
+	Roles int64 `json:"roles"`
+}
+
+// Run menghapus permanen user dan role yang di-soft delete sebelum (sekarang - masa retensi).
+// User dihapus lebih dulu agar role yang hanya dipakai user tersebut ikut bisa dihapus.
+func (s *RetentionService) Run(ctx context.Context) (*RetentionResult, error) {
+	cutoff := utils.Now().Add(-s.retention)
+	result := &RetentionResult{}
+
+	var err error
+	if result.Users, err = s.users.PurgeDeletedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	if result.Roles, err = s.roles.PurgeDeletedBefore(ctx, cutoff); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/services"
+	"golang-starter-kit/testutil"
+)
+
+func TestRetentionPurgesExpiredRows(t *testing.T) {
+	h := testutil.New(t)
+	old := h.CreateUser(t, testutil.UserAttrs{})
+	recent := h.CreateUser(t, testutil.UserAttrs{})
+	unusedRole := h.CreateRole(t, testutil.RoleAttrs{})
+
+	h.DB.Delete(&old)
+	h.DB.Delete(&models.Role{}, old.IDRole)
+	h.DB.Delete(&unusedRole)
+	h.Clock.Advance(20 * 24 * time.Hour)
+	h.DB.Delete(&recent)
+	h.Clock.Advance(11 * 24 * time.Hour)
+
+	retention := services.NewRetentionService(
+		repositories.NewUserRepository(h.DB),
+		repositories.NewRoleRepository(h.DB),
+		30*24*time.Hour,
+	)
+	result, err := retention.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hanya user lama yang lewat masa retensi, role-nya ikut terhapus karena sudah tidak dipakai
+	if result.Users != 1 || result.Roles != 2 {
+		t.Fatalf("result = %+v, want 1 user and 2 roles", result)
+	}
+	var count int64
+	h.DB.Unscoped().Model(&models.User{}).Where("id = ?", recent.ID).Count(&count)
+	if count != 1 {
+		t.Fatal("recently deleted user should be kept")
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+)
+
+// RoleService berisi aturan bisnis untuk pengelolaan role
+type RoleService struct {
+	roles repositories.RoleRepository
+	audit *AuditService
+}
+
+// NewRoleService membuat RoleService baru
+func NewRoleService(roles repositories.RoleRepository, audit *AuditService) *RoleService {
+	return &RoleService{roles: roles, audit: audit}
+}
+
+// List mengambil semua role sesuai DeletedScope
+func (s *RoleService) List(ctx context.Context, scope repositories.DeletedScope) ([]models.Role, error) {
+	return s.roles.FindAll(ctx, scope)
+}
+
+// Get mengambil role berdasarkan ID
+func (s *RoleService) Get(ctx context.Context, id uint) (*models.Role, error) {
+	role, err := s.roles.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Create menyimpan role baru
+func (s *RoleService) Create(ctx context.Context, name string) (*models.Role, error) {
+	role := &models.Role{Name: name}
+	if err := s.roles.Create(ctx, role); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "role.create", TargetType: "role", TargetID: role.ID, After: role})
+	return role, nil
+}
+
+// Update mengubah nama role, nil atau string kosong berarti tidak diubah
+func (s *RoleService) Update(ctx context.Context, id uint, name *string) (*models.Role, error) {
+	role, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *role
+	if name != nil && *name != "" && *name != role.Name {
+		// Nama role sistem dipakai sebagai acuan hak akses, jadi tidak boleh diganti
+		if role.IsSystem {
+			return nil, ErrSystemRoleProtected
+		}
+		role.Name = *name
+	}
+	if err := s.roles.Update(ctx, role); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "role.update", TargetType: "role", TargetID: role.ID, Before: &before, After: role})
+	return role, nil
+}
+
+// Delete menghapus role (soft delete). Role yang masih dipakai user ditolak,
+// kecuali reassignTo diisi: semua user dipindahkan ke role tersebut lebih dulu.
+func (s *RoleService) Delete(ctx context.Context, id uint, reassignTo *uint) error {
+	role, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return ErrSystemRoleProtected
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == role.ID {
+			return ErrInvalidReassignRole
+		}
+		if _, err := s.Get(ctx, *reassignTo); err != nil {
+			if errors.Is(err, ErrRoleNotFound) {
+				return ErrInvalidReassignRole
+			}
+			return err
+		}
+		if _, err := s.roles.ReassignAndDelete(ctx, role, *reassignTo); err != nil {
+			return err
+		}
+		s.audit.Record(ctx, AuditEntry{Action: "role.delete", TargetType: "role", TargetID: role.ID, Before: role})
+		return nil
+	}
+
+	count, err := s.roles.CountUsers(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+	if err := s.roles.Delete(ctx, role); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "role.delete", TargetType: "role", TargetID: role.ID, Before: role})
+	return nil
+}
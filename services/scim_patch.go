@@ -0,0 +1,238 @@
+package services
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SCIMPatchRequest adalah body PATCH (RFC 7644 bagian 3.5.2)
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation adalah satu operasi add, replace atau remove. Path kosong berarti value
+// berisi objek atribut yang diubah.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// scimFilter adalah satu-satunya bentuk filter yang didukung: atribut eq "nilai"
+var scimFilter = regexp.MustCompile(`^\s*(\S+)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// scimMemberPath adalah path anggota tertentu, contoh members[value eq "12"]
+var scimMemberPath = regexp.MustCompile(`^members\[\s*value\s+eq\s+("(?:[^"\\]|\\.)*")\s*\]$`)
+
+// parseSCIMFilter mengembalikan nama atribut (huruf kecil, tanpa URN schema) dan nilai filter.
+// Filter kosong menghasilkan atribut kosong.
+func parseSCIMFilter(filter, schema string) (string, string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+	match := scimFilter.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", scimError(SCIMInvalidFilter, `Filter hanya mendukung bentuk atribut eq "nilai"`)
+	}
+	value, err := strconv.Unquote(match[2])
+	if err != nil {
+		return "", "", scimError(SCIMInvalidFilter, "Nilai filter tidak valid")
+	}
+	return scimAttributePath(match[1], schema), value, nil
+}
+
+// scimAttributePath menormalkan path atribut: huruf kecil dan tanpa prefix URN schema resource
+func scimAttributePath(path, schema string) string {
+	path = strings.ToLower(strings.TrimSpace(path))
+	return strings.TrimPrefix(path, strings.ToLower(schema)+":")
+}
+
+// scimOperation mengembalikan jenis operasi PATCH dalam huruf kecil (Azure AD mengirim "Replace")
+func scimOperation(operation SCIMPatchOperation) (string, error) {
+	op := strings.ToLower(operation.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return "", scimError(SCIMInvalidSyntax, "Operasi PATCH harus add, replace atau remove")
+	}
+	if op != "remove" && len(operation.Value) == 0 {
+		return "", scimError(SCIMInvalidValue, "Operasi "+op+" membutuhkan value")
+	}
+	return op, nil
+}
+
+// scimAttributes membaca value operasi tanpa path sebagai objek atribut
+func scimAttributes(value json.RawMessage) (map[string]json.RawMessage, error) {
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(value, &attributes); err != nil {
+		return nil, scimError(SCIMInvalidValue, "Value operasi tanpa path harus berupa objek")
+	}
+	return attributes, nil
+}
+
+// decodeSCIMValue membaca value atribut, error menjadi invalidValue
+func decodeSCIMValue(value json.RawMessage, target interface{}) error {
+	if err := json.Unmarshal(value, target); err != nil {
+		return scimError(SCIMInvalidValue, "Value tidak valid")
+	}
+	return nil
+}
+
+// apply menerapkan satu operasi PATCH ke perubahan user
+func (c *scimUserChanges) apply(operation SCIMPatchOperation) error {
+	op, err := scimOperation(operation)
+	if err != nil {
+		return err
+	}
+	path := scimAttributePath(operation.Path, SCIMSchemaUser)
+
+	if op == "remove" {
+		switch path {
+		case "":
+			return scimError(SCIMNoTarget, "Operasi remove membutuhkan path")
+		case "externalid":
+			c.ExternalID = ""
+			c.RemoveExternalID = true
+		case "username", "active":
+			return scimError(SCIMMutability, "Atribut "+operation.Path+" wajib ada dan tidak dapat dihapus")
+		}
+		return nil
+	}
+
+	if path != "" {
+		return c.set(path, operation.Value)
+	}
+	attributes, err := scimAttributes(operation.Value)
+	if err != nil {
+		return err
+	}
+	for name, value := range attributes {
+		if err := c.set(scimAttributePath(name, SCIMSchemaUser), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set mengisi satu atribut user. Atribut yang tidak disimpan aplikasi (misalnya title atau
+// extension enterprise) diabaikan agar provisioning dari IdP tidak gagal.
+func (c *scimUserChanges) set(path string, value json.RawMessage) error {
+	switch path {
+	case "username":
+		return decodeSCIMValue(value, &c.UserName)
+	case "displayname":
+		return decodeSCIMValue(value, &c.DisplayName)
+	case "name":
+		var name SCIMName
+		if err := decodeSCIMValue(value, &name); err != nil {
+			return err
+		}
+		c.Name = name
+	case "name.formatted":
+		return decodeSCIMValue(value, &c.Name.Formatted)
+	case "name.givenname":
+		return decodeSCIMValue(value, &c.Name.GivenName)
+	case "name.familyname":
+		return decodeSCIMValue(value, &c.Name.FamilyName)
+	case "externalid":
+		c.RemoveExternalID = false
+		return decodeSCIMValue(value, &c.ExternalID)
+	case "active":
+		var active SCIMBool
+		if err := decodeSCIMValue(value, &active); err != nil {
+			return err
+		}
+		c.Active = &active
+	case "password":
+		return decodeSCIMValue(value, &c.Password)
+	}
+	return nil
+}
+
+// apply menerapkan satu operasi PATCH ke perubahan grup. Members sudah berisi anggota saat ini.
+func (c *scimGroupChanges) apply(operation SCIMPatchOperation) error {
+	op, err := scimOperation(operation)
+	if err != nil {
+		return err
+	}
+	path := scimAttributePath(operation.Path, SCIMSchemaGroup)
+
+	if match := scimMemberPath.FindStringSubmatch(path); match != nil {
+		if op != "remove" {
+			return scimError(SCIMInvalidPath, "Filter members hanya didukung untuk operasi remove")
+		}
+		value, err := strconv.Unquote(match[1])
+		if err != nil {
+			return scimError(SCIMInvalidPath, "Path members tidak valid")
+		}
+		c.removeMembers([]uint{scimID(value)})
+		return nil
+	}
+
+	switch {
+	case path == "" && op == "remove":
+		return scimError(SCIMNoTarget, "Operasi remove membutuhkan path")
+	case path == "":
+		attributes, err := scimAttributes(operation.Value)
+		if err != nil {
+			return err
+		}
+		for name, value := range attributes {
+			if err := c.set(op, scimAttributePath(name, SCIMSchemaGroup), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case path == "members" && op == "remove" && len(operation.Value) == 0:
+		*c.Members = nil
+		return nil
+	case path == "displayname" && op == "remove":
+		return scimError(SCIMMutability, "displayName wajib ada dan tidak dapat dihapus")
+	}
+	return c.set(op, path, operation.Value)
+}
+
+// set menerapkan operasi ke satu atribut grup, atribut lain diabaikan
+func (c *scimGroupChanges) set(op, path string, value json.RawMessage) error {
+	switch path {
+	case "displayname":
+		return decodeSCIMValue(value, &c.DisplayName)
+	case "members":
+		var members []SCIMReference
+		if err := decodeSCIMValue(value, &members); err != nil {
+			return err
+		}
+		ids, err := memberIDs(members)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case "add":
+			*c.Members = append(*c.Members, ids...)
+		case "replace":
+			*c.Members = ids
+		case "remove":
+			c.removeMembers(ids)
+		}
+	}
+	return nil
+}
+
+// removeMembers mengeluarkan user dari daftar anggota
+func (c *scimGroupChanges) removeMembers(ids []uint) {
+	remaining := (*c.Members)[:0]
+	for _, member := range *c.Members {
+		removed := false
+		for _, id := range ids {
+			if member == id {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			remaining = append(remaining, member)
+		}
+	}
+	*c.Members = remaining
+}
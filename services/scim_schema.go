@@ -0,0 +1,222 @@
+package services
+
+// SCIMSupported adalah fitur opsional di ServiceProviderConfig
+type SCIMSupported struct {
+	Supported bool `json:"supported"`
+}
+
+// SCIMBulkSupport adalah dukungan operasi bulk
+type SCIMBulkSupport struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+// SCIMFilterSupport adalah dukungan filter dan jumlah resource terbanyak per halaman
+type SCIMFilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+// SCIMAuthenticationScheme adalah cara client SCIM melakukan autentikasi
+type SCIMAuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+// SCIMServiceProviderConfig adalah response /ServiceProviderConfig (RFC 7643 bagian 5)
+type SCIMServiceProviderConfig struct {
+	Schemas               []string                   `json:"schemas"`
+	Patch                 SCIMSupported              `json:"patch"`
+	Bulk                  SCIMBulkSupport            `json:"bulk"`
+	Filter                SCIMFilterSupport          `json:"filter"`
+	ChangePassword        SCIMSupported              `json:"changePassword"`
+	Sort                  SCIMSupported              `json:"sort"`
+	ETag                  SCIMSupported              `json:"etag"`
+	AuthenticationSchemes []SCIMAuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  SCIMMeta                   `json:"meta"`
+}
+
+// SCIMResourceType adalah satu resource type yang didukung (RFC 7643 bagian 6)
+type SCIMResourceType struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Endpoint    string   `json:"endpoint"`
+	Description string   `json:"description"`
+	Schema      string   `json:"schema"`
+	Meta        SCIMMeta `json:"meta"`
+}
+
+// SCIMAttribute adalah definisi atribut dalam schema (RFC 7643 bagian 7)
+type SCIMAttribute struct {
+	Name           string          `json:"name"`
+	Type           string          `json:"type"`
+	MultiValued    bool            `json:"multiValued"`
+	Required       bool            `json:"required"`
+	CaseExact      bool            `json:"caseExact"`
+	Mutability     string          `json:"mutability"`
+	Returned       string          `json:"returned"`
+	Uniqueness     string          `json:"uniqueness"`
+	ReferenceTypes []string        `json:"referenceTypes,omitempty"`
+	SubAttributes  []SCIMAttribute `json:"subAttributes,omitempty"`
+}
+
+// SCIMSchemaDefinition adalah response /Schemas/:id
+type SCIMSchemaDefinition struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Attributes  []SCIMAttribute `json:"attributes"`
+	Meta        SCIMMeta        `json:"meta"`
+}
+
+// scimAttribute membuat definisi atribut yang bisa diubah, tidak wajib dan tidak unik.
+// Field lain diubah oleh pemanggil bila perlu.
+func scimAttribute(name, attributeType string) SCIMAttribute {
+	return SCIMAttribute{Name: name, Type: attributeType, Mutability: "readWrite", Returned: "default", Uniqueness: "none"}
+}
+
+// ServiceProviderConfig mengembalikan fitur SCIM yang didukung
+func (s *SCIMService) ServiceProviderConfig() SCIMServiceProviderConfig {
+	return SCIMServiceProviderConfig{
+		Schemas:        []string{SCIMSchemaServiceProviderConfig},
+		Patch:          SCIMSupported{Supported: true},
+		Bulk:           SCIMBulkSupport{},
+		Filter:         SCIMFilterSupport{Supported: true, MaxResults: s.config.MaxResults},
+		ChangePassword: SCIMSupported{Supported: true},
+		AuthenticationSchemes: []SCIMAuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer Token",
+			Description: "Personal access token dengan scope scim milik admin di header Authorization: Bearer",
+			Primary:     true,
+		}},
+		Meta: SCIMMeta{ResourceType: "ServiceProviderConfig", Location: s.config.BaseURL + "/ServiceProviderConfig"},
+	}
+}
+
+// ResourceTypes mengembalikan resource type User dan Group
+func (s *SCIMService) ResourceTypes() *SCIMListResponse {
+	types := s.resourceTypes()
+	return listResponse(int64(len(types)), 1, len(types), types)
+}
+
+// ResourceType mengambil satu resource type berdasarkan ID (User atau Group)
+func (s *SCIMService) ResourceType(id string) (*SCIMResourceType, error) {
+	for _, resourceType := range s.resourceTypes() {
+		if resourceType.ID == id {
+			return &resourceType, nil
+		}
+	}
+	return nil, ErrSCIMResourceNotFound
+}
+
+// Schemas mengembalikan definisi schema User dan Group
+func (s *SCIMService) Schemas() *SCIMListResponse {
+	schemas := s.schemas()
+	return listResponse(int64(len(schemas)), 1, len(schemas), schemas)
+}
+
+// Schema mengambil satu definisi schema berdasarkan URN
+func (s *SCIMService) Schema(id string) (*SCIMSchemaDefinition, error) {
+	for _, schema := range s.schemas() {
+		if schema.ID == id {
+			return &schema, nil
+		}
+	}
+	return nil, ErrSCIMResourceNotFound
+}
+
+// resourceTypes adalah daftar resource type dengan location sesuai SCIM_BASE_URL
+func (s *SCIMService) resourceTypes() []SCIMResourceType {
+	return []SCIMResourceType{
+		{
+			Schemas:     []string{SCIMSchemaResourceType},
+			ID:          "User",
+			Name:        "User",
+			Endpoint:    "/Users",
+			Description: "User aplikasi",
+			Schema:      SCIMSchemaUser,
+			Meta:        SCIMMeta{ResourceType: "ResourceType", Location: s.config.BaseURL + "/ResourceTypes/User"},
+		},
+		{
+			Schemas:     []string{SCIMSchemaResourceType},
+			ID:          "Group",
+			Name:        "Group",
+			Endpoint:    "/Groups",
+			Description: "Role aplikasi, setiap user hanya anggota satu grup",
+			Schema:      SCIMSchemaGroup,
+			Meta:        SCIMMeta{ResourceType: "ResourceType", Location: s.config.BaseURL + "/ResourceTypes/Group"},
+		},
+	}
+}
+
+// schemas adalah definisi atribut User dan Group yang disimpan aplikasi
+func (s *SCIMService) schemas() []SCIMSchemaDefinition {
+	id := scimAttribute("id", "string")
+	id.CaseExact = true
+	id.Mutability = "readOnly"
+	id.Returned = "always"
+	id.Uniqueness = "server"
+
+	externalID := scimAttribute("externalId", "string")
+	externalID.CaseExact = true
+
+	userName := scimAttribute("userName", "string")
+	userName.Required = true
+	userName.Uniqueness = "server"
+
+	password := scimAttribute("password", "string")
+	password.Mutability = "writeOnly"
+	password.Returned = "never"
+
+	emails := scimAttribute("emails", "complex")
+	emails.MultiValued = true
+	emails.Mutability = "readOnly"
+	emails.SubAttributes = []SCIMAttribute{scimAttribute("value", "string"), scimAttribute("type", "string"), scimAttribute("primary", "boolean")}
+
+	groups := scimAttribute("groups", "complex")
+	groups.MultiValued = true
+	groups.Mutability = "readOnly"
+	groups.SubAttributes = []SCIMAttribute{scimAttribute("value", "string"), scimAttribute("display", "string"), scimReference("Group")}
+
+	name := scimAttribute("name", "complex")
+	name.SubAttributes = []SCIMAttribute{scimAttribute("formatted", "string"), scimAttribute("givenName", "string"), scimAttribute("familyName", "string")}
+
+	displayName := scimAttribute("displayName", "string")
+	displayName.Required = true
+	displayName.Uniqueness = "server"
+
+	members := scimAttribute("members", "complex")
+	members.MultiValued = true
+	members.SubAttributes = []SCIMAttribute{scimAttribute("value", "string"), scimAttribute("display", "string"), scimReference("User")}
+
+	return []SCIMSchemaDefinition{
+		{
+			Schemas:     []string{SCIMSchemaSchema},
+			ID:          SCIMSchemaUser,
+			Name:        "User",
+			Description: "User aplikasi, userName adalah email login",
+			Attributes:  []SCIMAttribute{id, externalID, userName, name, scimAttribute("displayName", "string"), emails, scimAttribute("active", "boolean"), password, groups},
+			Meta:        SCIMMeta{ResourceType: "Schema", Location: s.config.BaseURL + "/Schemas/" + SCIMSchemaUser},
+		},
+		{
+			Schemas:     []string{SCIMSchemaSchema},
+			ID:          SCIMSchemaGroup,
+			Name:        "Group",
+			Description: "Role aplikasi",
+			Attributes:  []SCIMAttribute{id, displayName, members},
+			Meta:        SCIMMeta{ResourceType: "Schema", Location: s.config.BaseURL + "/Schemas/" + SCIMSchemaGroup},
+		},
+	}
+}
+
+// scimReference membuat sub-atribut $ref ke resource type tertentu
+func scimReference(resourceType string) SCIMAttribute {
+	ref := scimAttribute("$ref", "reference")
+	ref.ReferenceTypes = []string{resourceType}
+	return ref
+}
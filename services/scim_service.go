@@ -0,0 +1,896 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+)
+
+// URN schema dan message SCIM 2.0 (RFC 7643 dan RFC 7644)
+const (
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMSchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SCIMSchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+	SCIMSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Nilai scimType pada response error SCIM (RFC 7644 bagian 3.12)
+const (
+	SCIMInvalidFilter = "invalidFilter"
+	SCIMInvalidPath   = "invalidPath"
+	SCIMInvalidSyntax = "invalidSyntax"
+	SCIMInvalidValue  = "invalidValue"
+	SCIMNoTarget      = "noTarget"
+	SCIMUniqueness    = "uniqueness"
+	SCIMMutability    = "mutability"
+)
+
+// SCIMError adalah error protokol SCIM (filter, path atau body yang tidak valid). Error aturan
+// bisnis tetap memakai Error dan diubah ke format SCIM oleh controller.
+type SCIMError struct {
+	Status int
+	Type   string // scimType, kosong untuk error tanpa tipe
+	Detail string
+}
+
+func (e *SCIMError) Error() string {
+	return e.Type + ": " + e.Detail
+}
+
+// scimError membuat SCIMError dengan status 400
+func scimError(scimType, detail string) *SCIMError {
+	return &SCIMError{Status: http.StatusBadRequest, Type: scimType, Detail: detail}
+}
+
+// SCIMMeta adalah atribut meta setiap resource
+type SCIMMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location"`
+}
+
+// SCIMName adalah komponen nama user
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMEmail adalah satu email user. Aplikasi hanya menyimpan satu email, yaitu userName.
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary"`
+}
+
+// SCIMReference adalah referensi ke resource lain, dipakai untuk members grup dan groups user
+type SCIMReference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMUser adalah representasi models.User dalam schema User SCIM
+type SCIMUser struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        SCIMName        `json:"name"`
+	DisplayName string          `json:"displayName"`
+	Emails      []SCIMEmail     `json:"emails"`
+	Active      bool            `json:"active"`
+	Groups      []SCIMReference `json:"groups"`
+	Meta        SCIMMeta        `json:"meta"`
+}
+
+// SCIMGroup adalah representasi models.Role dalam schema Group SCIM
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id"`
+	DisplayName string          `json:"displayName"`
+	Members     []SCIMReference `json:"members,omitempty"`
+	Meta        SCIMMeta        `json:"meta"`
+}
+
+// SCIMBool adalah boolean yang juga menerima string "True"/"False" seperti yang dikirim Azure AD
+type SCIMBool bool
+
+// UnmarshalJSON menerima true/false atau string boolean
+func (b *SCIMBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = SCIMBool(v)
+		return nil
+	case string:
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*b = SCIMBool(parsed)
+		return nil
+	}
+	return errors.New("expected boolean")
+}
+
+// SCIMUserInput adalah body POST dan PUT /Users. Atribut yang tidak disimpan aplikasi
+// (misalnya addresses) diabaikan.
+type SCIMUserInput struct {
+	ExternalID  string    `json:"externalId"`
+	UserName    string    `json:"userName"`
+	Name        SCIMName  `json:"name"`
+	DisplayName string    `json:"displayName"`
+	Active      *SCIMBool `json:"active"`
+	Password    string    `json:"password"`
+}
+
+// SCIMGroupInput adalah body POST dan PUT /Groups. Members nil berarti anggota tidak diubah.
+type SCIMGroupInput struct {
+	DisplayName string          `json:"displayName"`
+	Members     []SCIMReference `json:"members"`
+}
+
+// SCIMListParams adalah parameter query list resource
+type SCIMListParams struct {
+	Filter             string
+	StartIndex         int  // Dimulai dari 1
+	Count              *int // nil berarti SCIM_MAX_RESULTS
+	ExcludedAttributes string
+}
+
+// SCIMListResponse adalah response list resource (RFC 7644 bagian 3.4.2)
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMService berisi aturan bisnis provisioning SCIM 2.0. User dipetakan ke models.User dengan
+// userName = email, Group dipetakan ke models.Role. Karena user hanya punya satu role, menambah
+// user ke sebuah grup memindahkannya dari grup lama, dan user yang dikeluarkan dari grup
+// dipindahkan ke role default. externalId disimpan sebagai UserIdentity provider "scim".
+type SCIMService struct {
+	users       repositories.UserRepository
+	roles       repositories.RoleRepository
+	identities  repositories.UserIdentityRepository
+	userService *UserService
+	roleService *RoleService
+	sessions    *SessionService
+	config      config.SCIMConfig
+	audit       *AuditService
+}
+
+// NewSCIMService membuat SCIMService baru
+func NewSCIMService(
+	users repositories.UserRepository,
+	roles repositories.RoleRepository,
+	identities repositories.UserIdentityRepository,
+	userService *UserService,
+	roleService *RoleService,
+	sessions *SessionService,
+	config config.SCIMConfig,
+	audit *AuditService,
+) *SCIMService {
+	return &SCIMService{
+		users:       users,
+		roles:       roles,
+		identities:  identities,
+		userService: userService,
+		roleService: roleService,
+		sessions:    sessions,
+		config:      config,
+		audit:       audit,
+	}
+}
+
+// ListUsers mengambil user yang cocok dengan filter. Filter yang didukung: userName, emails,
+// emails.value, externalId dan id dengan operator eq.
+func (s *SCIMService) ListUsers(ctx context.Context, params SCIMListParams) (*SCIMListResponse, error) {
+	attribute, value, err := parseSCIMFilter(params.Filter, SCIMSchemaUser)
+	if err != nil {
+		return nil, err
+	}
+	var filter repositories.UserFilter
+	switch attribute {
+	case "":
+	case "username", "emails", "emails.value":
+		filter.Email = value
+	case "externalid":
+		filter.IdentityProvider = models.IdentityProviderSCIM
+		filter.IdentitySubject = value
+	case "id":
+		id := scimID(value)
+		filter.ID = &id
+	default:
+		return nil, scimError(SCIMInvalidFilter, "Filter hanya mendukung userName, emails, externalId dan id")
+	}
+
+	startIndex, offset, limit := s.page(params)
+	users, total, err := s.users.FindPage(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]SCIMUser, 0, len(users))
+	for i := range users {
+		resource, err := s.userResource(ctx, &users[i])
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *resource)
+	}
+	return listResponse(total, startIndex, len(resources), resources), nil
+}
+
+// GetUser mengambil satu user
+func (s *SCIMService) GetUser(ctx context.Context, id uint) (*SCIMUser, error) {
+	user, err := s.userService.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.userResource(ctx, user)
+}
+
+// CreateUser membuat user baru dari client SCIM. User tanpa password hanya bisa login lewat
+// SSO, LDAP atau reset password.
+func (s *SCIMService) CreateUser(ctx context.Context, input SCIMUserInput) (*SCIMUser, error) {
+	if !validUserName(input.UserName) {
+		return nil, ErrSCIMUserNameInvalid
+	}
+	if input.ExternalID != "" {
+		if err := s.ensureExternalIDAvailable(ctx, input.ExternalID, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	name := scimDisplayName(input)
+	if name == "" {
+		name = input.UserName
+	}
+	params := CreateUserParams{Name: name, Email: input.UserName, Password: input.Password}
+	var user *models.User
+	var err error
+	if input.Password != "" {
+		user, err = s.userService.Create(ctx, params)
+	} else {
+		user, err = s.userService.createWithHash(ctx, params, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.completeUser(ctx, user, input); err != nil {
+		s.rollbackUser(ctx, user)
+		return nil, err
+	}
+	return s.GetUser(ctx, user.ID)
+}
+
+// completeUser menyimpan externalId dan status aktif user yang baru dibuat
+func (s *SCIMService) completeUser(ctx context.Context, user *models.User, input SCIMUserInput) error {
+	if input.ExternalID != "" {
+		if err := s.setExternalID(ctx, user.ID, input.ExternalID); err != nil {
+			return err
+		}
+	}
+	if input.Active != nil && !bool(*input.Active) {
+		return s.userService.setDeactivated(ctx, s.sessions, user, true)
+	}
+	return nil
+}
+
+// rollbackUser menghapus permanen user yang gagal dibuat lengkap agar tidak tertinggal user setengah jadi
+// dan client bisa mengulang POST dengan userName yang sama
+func (s *SCIMService) rollbackUser(ctx context.Context, user *models.User) {
+	if err := s.removeExternalID(ctx, user.ID); err != nil {
+		log.Printf("[scim] gagal menghapus externalId user #%d yang gagal dibuat: %v", user.ID, err)
+	}
+	if err := s.users.Purge(ctx, user); err != nil {
+		log.Printf("[scim] gagal menghapus user #%d yang gagal dibuat: %v", user.ID, err)
+		return
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.purge", TargetType: "user", TargetID: user.ID, Before: user})
+}
+
+// ReplaceUser mengganti atribut user (PUT). Sesuai RFC 7644 bagian 3.5.1 atribut yang tidak dikirim
+// dikosongkan: externalId dihapus dan nama kembali ke userName seperti saat user dibuat. Password dan
+// active yang tidak dikirim tidak diubah, karena password tidak pernah dikembalikan ke client dan user
+// yang dinonaktifkan tidak boleh aktif kembali hanya karena client tidak mengirim active.
+func (s *SCIMService) ReplaceUser(ctx context.Context, id uint, input SCIMUserInput) (*SCIMUser, error) {
+	if !validUserName(input.UserName) {
+		return nil, ErrSCIMUserNameInvalid
+	}
+	changes := scimUserChanges{SCIMUserInput: input, RemoveExternalID: input.ExternalID == ""}
+	if scimDisplayName(input) == "" {
+		changes.DisplayName = input.UserName
+	}
+	return s.saveUser(ctx, id, changes)
+}
+
+// PatchUser menerapkan operasi PATCH ke user
+func (s *SCIMService) PatchUser(ctx context.Context, id uint, request SCIMPatchRequest) (*SCIMUser, error) {
+	if _, err := s.userService.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	var changes scimUserChanges
+	for _, operation := range request.Operations {
+		if err := changes.apply(operation); err != nil {
+			return nil, err
+		}
+	}
+	return s.saveUser(ctx, id, changes)
+}
+
+// DeleteUser menghapus user (soft delete) beserta externalId-nya
+func (s *SCIMService) DeleteUser(ctx context.Context, id uint) error {
+	if err := s.userService.Delete(ctx, id); err != nil {
+		return err
+	}
+	// externalId dilepas agar client bisa membuat ulang user dengan externalId yang sama
+	return s.removeExternalID(ctx, id)
+}
+
+// ListGroups mengambil role yang cocok dengan filter. Filter yang didukung: displayName dan id
+// dengan operator eq. excludedAttributes=members melewati daftar anggota.
+func (s *SCIMService) ListGroups(ctx context.Context, params SCIMListParams) (*SCIMListResponse, error) {
+	attribute, value, err := parseSCIMFilter(params.Filter, SCIMSchemaGroup)
+	if err != nil {
+		return nil, err
+	}
+	var filter repositories.RoleFilter
+	switch attribute {
+	case "":
+	case "displayname":
+		filter.Name = value
+	case "id":
+		id := scimID(value)
+		filter.ID = &id
+	default:
+		return nil, scimError(SCIMInvalidFilter, "Filter hanya mendukung displayName dan id")
+	}
+
+	startIndex, offset, limit := s.page(params)
+	roles, total, err := s.roles.FindPage(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	withMembers := !excludesAttribute(params.ExcludedAttributes, "members")
+	resources := make([]SCIMGroup, 0, len(roles))
+	for i := range roles {
+		resource, err := s.groupResource(ctx, &roles[i], withMembers)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *resource)
+	}
+	return listResponse(total, startIndex, len(resources), resources), nil
+}
+
+// GetGroup mengambil satu role beserta anggotanya
+func (s *SCIMService) GetGroup(ctx context.Context, id uint, excludedAttributes string) (*SCIMGroup, error) {
+	role, err := s.roleService.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupResource(ctx, role, !excludesAttribute(excludedAttributes, "members"))
+}
+
+// CreateGroup membuat role baru dengan anggota dari input
+func (s *SCIMService) CreateGroup(ctx context.Context, input SCIMGroupInput) (*SCIMGroup, error) {
+	name := strings.TrimSpace(input.DisplayName)
+	if name == "" {
+		return nil, ErrSCIMGroupNameRequired
+	}
+	if err := s.ensureGroupNameAvailable(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	members, err := memberIDs(input.Members)
+	if err != nil {
+		return nil, err
+	}
+	// Role anggota sebelumnya disimpan untuk rollback jika memindahkan anggota gagal
+	previous := make(map[uint]uint, len(members))
+	for _, id := range members {
+		user, err := s.userService.Get(ctx, id)
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrSCIMMemberNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		previous[id] = user.IDRole
+	}
+
+	role, err := s.roleService.Create(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setMembers(ctx, role, members); err != nil {
+		s.rollbackGroup(ctx, role, previous)
+		return nil, err
+	}
+	return s.GetGroup(ctx, role.ID, "")
+}
+
+// rollbackGroup mengembalikan anggota ke role sebelumnya lalu menghapus permanen role yang gagal
+// dibuat lengkap, agar client bisa mengulang POST dengan displayName yang sama
+func (s *SCIMService) rollbackGroup(ctx context.Context, role *models.Role, previous map[uint]uint) {
+	moved, err := s.memberIDsOf(ctx, role.ID)
+	if err != nil {
+		log.Printf("[scim] gagal membaca anggota role #%d yang gagal dibuat: %v", role.ID, err)
+		return
+	}
+	for _, id := range moved {
+		idRole, ok := previous[id]
+		if !ok {
+			continue
+		}
+		if _, err := s.userService.Update(ctx, id, UpdateUserParams{IDRole: &idRole}); err != nil {
+			log.Printf("[scim] gagal mengembalikan role user #%d: %v", id, err)
+			return
+		}
+	}
+	if err := s.roles.Purge(ctx, role); err != nil {
+		log.Printf("[scim] gagal menghapus role #%d yang gagal dibuat: %v", role.ID, err)
+		return
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "role.purge", TargetType: "role", TargetID: role.ID, Before: role})
+}
+
+// ReplaceGroup mengganti nama dan (jika dikirim) seluruh anggota role (PUT)
+func (s *SCIMService) ReplaceGroup(ctx context.Context, id uint, input SCIMGroupInput) (*SCIMGroup, error) {
+	changes := scimGroupChanges{DisplayName: input.DisplayName}
+	if input.Members != nil {
+		members, err := memberIDs(input.Members)
+		if err != nil {
+			return nil, err
+		}
+		changes.Members = &members
+	}
+	return s.saveGroup(ctx, id, changes)
+}
+
+// PatchGroup menerapkan operasi PATCH ke role. Operasi members dijalankan berurutan terhadap
+// daftar anggota saat ini, hasil akhirnya disimpan sekaligus.
+func (s *SCIMService) PatchGroup(ctx context.Context, id uint, request SCIMPatchRequest) (*SCIMGroup, error) {
+	role, err := s.roleService.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.memberIDsOf(ctx, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	changes := scimGroupChanges{Members: &current}
+	for _, operation := range request.Operations {
+		if err := changes.apply(operation); err != nil {
+			return nil, err
+		}
+	}
+	return s.saveGroup(ctx, id, changes)
+}
+
+// DeleteGroup menghapus role, anggotanya dipindahkan ke role default
+func (s *SCIMService) DeleteGroup(ctx context.Context, id uint) error {
+	fallback, err := s.defaultRole(ctx)
+	if err != nil {
+		return err
+	}
+	return s.roleService.Delete(ctx, id, &fallback.ID)
+}
+
+// scimUserChanges adalah perubahan user dari PUT atau PATCH, field kosong berarti tidak diubah
+type scimUserChanges struct {
+	SCIMUserInput
+	RemoveExternalID bool
+}
+
+// saveUser menyimpan perubahan atribut user lalu mengembalikan resource terbaru
+func (s *SCIMService) saveUser(ctx context.Context, id uint, changes scimUserChanges) (*SCIMUser, error) {
+	var params UpdateUserParams
+	if changes.UserName != "" {
+		if !validUserName(changes.UserName) {
+			return nil, ErrSCIMUserNameInvalid
+		}
+		params.Email = &changes.UserName
+	}
+	if name := scimDisplayName(changes.SCIMUserInput); name != "" {
+		params.Name = &name
+	}
+	if changes.Password != "" {
+		params.Password = &changes.Password
+	}
+
+	user, err := s.userService.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if changes.ExternalID != "" {
+		if err := s.ensureExternalIDAvailable(ctx, changes.ExternalID, id); err != nil {
+			return nil, err
+		}
+	}
+	if params.Email != nil || params.Name != nil || params.Password != nil {
+		if user, err = s.userService.Update(ctx, id, params); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case changes.ExternalID != "":
+		err = s.setExternalID(ctx, id, changes.ExternalID)
+	case changes.RemoveExternalID:
+		err = s.removeExternalID(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Active != nil && bool(*changes.Active) == (user.DeactivatedAt != nil) {
+		if err := s.userService.setDeactivated(ctx, s.sessions, user, !bool(*changes.Active)); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetUser(ctx, id)
+}
+
+// scimGroupChanges adalah perubahan role dari PUT atau PATCH. Members nil berarti anggota tidak
+// diubah, selain itu berisi daftar lengkap ID user anggota yang baru.
+type scimGroupChanges struct {
+	DisplayName string
+	Members     *[]uint
+}
+
+// saveGroup menyimpan nama dan anggota role lalu mengembalikan resource terbaru
+func (s *SCIMService) saveGroup(ctx context.Context, id uint, changes scimGroupChanges) (*SCIMGroup, error) {
+	role, err := s.roleService.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(changes.DisplayName); name != "" && name != role.Name {
+		if err := s.ensureGroupNameAvailable(ctx, name, role.ID); err != nil {
+			return nil, err
+		}
+		if role, err = s.roleService.Update(ctx, role.ID, &name); err != nil {
+			return nil, err
+		}
+	}
+	if changes.Members != nil {
+		if err := s.setMembers(ctx, role, *changes.Members); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetGroup(ctx, role.ID, "")
+}
+
+// setMembers menjadikan members satu-satunya anggota role. User baru dipindahkan ke role ini,
+// anggota lama yang tidak ada di members dipindahkan ke role default.
+func (s *SCIMService) setMembers(ctx context.Context, role *models.Role, members []uint) error {
+	current, err := s.memberIDsOf(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+	isCurrent := make(map[uint]bool, len(current))
+	for _, id := range current {
+		isCurrent[id] = true
+	}
+	wanted := make(map[uint]bool, len(members))
+	var added []uint
+	for _, id := range members {
+		if wanted[id] {
+			continue
+		}
+		wanted[id] = true
+		if !isCurrent[id] {
+			added = append(added, id)
+		}
+	}
+	if err := s.ensureMembersExist(ctx, added); err != nil {
+		return err
+	}
+
+	var removed []uint
+	for _, id := range current {
+		if !wanted[id] {
+			removed = append(removed, id)
+		}
+	}
+	if len(removed) > 0 {
+		fallback, err := s.defaultRole(ctx)
+		if err != nil {
+			return err
+		}
+		// Anggota role default tidak bisa dikeluarkan, mereka tidak punya role lain
+		if fallback.ID != role.ID {
+			for _, id := range removed {
+				if _, err := s.userService.Update(ctx, id, UpdateUserParams{IDRole: &fallback.ID}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, id := range added {
+		if _, err := s.userService.Update(ctx, id, UpdateUserParams{IDRole: &role.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureMembersExist mengembalikan ErrSCIMMemberNotFound jika salah satu user tidak ada. Dicek
+// sebelum anggota diubah agar tidak ada perubahan setengah jalan.
+func (s *SCIMService) ensureMembersExist(ctx context.Context, ids []uint) error {
+	for _, id := range ids {
+		if _, err := s.userService.Get(ctx, id); err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return ErrSCIMMemberNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// memberIDsOf mengambil ID semua user dengan role tertentu
+func (s *SCIMService) memberIDsOf(ctx context.Context, roleID uint) ([]uint, error) {
+	users, _, err := s.users.FindPage(ctx, repositories.UserFilter{RoleID: &roleID}, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+// defaultRole mengambil role untuk user yang dikeluarkan dari grup (REGISTRATION_DEFAULT_ROLE)
+func (s *SCIMService) defaultRole(ctx context.Context) (*models.Role, error) {
+	role, err := s.roles.FindByName(ctx, s.userService.defaultRole)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrDefaultRoleMissing
+	}
+	return role, err
+}
+
+// ensureGroupNameAvailable mengembalikan ErrSCIMGroupNameTaken jika nama sudah dipakai role lain
+func (s *SCIMService) ensureGroupNameAvailable(ctx context.Context, name string, excludeID uint) error {
+	existing, err := s.roles.FindByName(ctx, name)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != excludeID {
+		return ErrSCIMGroupNameTaken
+	}
+	return nil
+}
+
+// ensureExternalIDAvailable mengembalikan ErrSCIMExternalIDTaken jika externalId milik user lain
+func (s *SCIMService) ensureExternalIDAvailable(ctx context.Context, externalID string, userID uint) error {
+	existing, err := s.identities.FindBySubject(ctx, models.IdentityProviderSCIM, externalID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.UserID != userID {
+		return ErrSCIMExternalIDTaken
+	}
+	return nil
+}
+
+// setExternalID menyimpan externalId user, menggantikan externalId sebelumnya
+func (s *SCIMService) setExternalID(ctx context.Context, userID uint, externalID string) error {
+	identity, err := s.externalIdentity(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		identity = &models.UserIdentity{UserID: userID, Provider: models.IdentityProviderSCIM, Subject: externalID}
+		if err := s.identities.Create(ctx, identity); err != nil {
+			return err
+		}
+		s.audit.Record(ctx, AuditEntry{Action: "user_identity.link", TargetType: "user", TargetID: userID, After: identity})
+		return nil
+	}
+	if identity.Subject == externalID {
+		return nil
+	}
+	before := *identity
+	identity.Subject = externalID
+	if err := s.identities.Update(ctx, identity); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user_identity.update", TargetType: "user", TargetID: userID, Before: &before, After: identity})
+	return nil
+}
+
+// removeExternalID menghapus externalId user jika ada
+func (s *SCIMService) removeExternalID(ctx context.Context, userID uint) error {
+	identity, err := s.externalIdentity(ctx, userID)
+	if err != nil || identity == nil {
+		return err
+	}
+	if err := s.identities.Delete(ctx, identity); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user_identity.unlink", TargetType: "user", TargetID: userID, Before: identity})
+	return nil
+}
+
+// externalIdentity mengambil identitas SCIM user, nil jika user belum punya externalId
+func (s *SCIMService) externalIdentity(ctx context.Context, userID uint) (*models.UserIdentity, error) {
+	identities, err := s.identities.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range identities {
+		if identities[i].Provider == models.IdentityProviderSCIM {
+			return &identities[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// userResource mengubah user (beserta role-nya) menjadi resource SCIM
+func (s *SCIMService) userResource(ctx context.Context, user *models.User) (*SCIMUser, error) {
+	identity, err := s.externalIdentity(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	id := strconv.Itoa(int(user.ID))
+	resource := &SCIMUser{
+		Schemas:     []string{SCIMSchemaUser},
+		ID:          id,
+		UserName:    user.Email,
+		Name:        SCIMName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      user.DeactivatedAt == nil,
+		Groups:      []SCIMReference{s.groupReference(&user.Role)},
+		Meta:        s.meta("User", "/Users/"+id, user.CreatedAt, user.UpdatedAt),
+	}
+	if identity != nil {
+		resource.ExternalID = identity.Subject
+	}
+	return resource, nil
+}
+
+// groupResource mengubah role menjadi resource SCIM, anggota hanya diambil jika withMembers
+func (s *SCIMService) groupResource(ctx context.Context, role *models.Role, withMembers bool) (*SCIMGroup, error) {
+	id := strconv.Itoa(int(role.ID))
+	resource := &SCIMGroup{
+		Schemas:     []string{SCIMSchemaGroup},
+		ID:          id,
+		DisplayName: role.Name,
+		Meta:        s.meta("Group", "/Groups/"+id, role.CreatedAt, role.UpdatedAt),
+	}
+	if !withMembers {
+		return resource, nil
+	}
+	users, _, err := s.users.FindPage(ctx, repositories.UserFilter{RoleID: &role.ID}, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		userID := strconv.Itoa(int(user.ID))
+		resource.Members = append(resource.Members, SCIMReference{
+			Value:   userID,
+			Display: user.Name,
+			Ref:     s.config.BaseURL + "/Users/" + userID,
+		})
+	}
+	return resource, nil
+}
+
+// groupReference adalah referensi ke grup (role) user
+func (s *SCIMService) groupReference(role *models.Role) SCIMReference {
+	id := strconv.Itoa(int(role.ID))
+	return SCIMReference{Value: id, Display: role.Name, Ref: s.config.BaseURL + "/Groups/" + id}
+}
+
+// meta membuat atribut meta resource dengan location absolut
+func (s *SCIMService) meta(resourceType, path string, created, modified time.Time) SCIMMeta {
+	return SCIMMeta{ResourceType: resourceType, Created: &created, LastModified: &modified, Location: s.config.BaseURL + path}
+}
+
+// page menormalkan startIndex dan count lalu menghitung offset dan limit query
+func (s *SCIMService) page(params SCIMListParams) (int, int, int) {
+	startIndex := params.StartIndex
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := s.config.MaxResults
+	if params.Count != nil && *params.Count < count {
+		count = *params.Count
+	}
+	// count negatif dianggap 0 (RFC 7644 bagian 3.4.2.4), response hanya berisi totalResults
+	if count < 0 {
+		count = 0
+	}
+	return startIndex, startIndex - 1, count
+}
+
+// listResponse membungkus resource dalam ListResponse
+func listResponse(total int64, startIndex, count int, resources interface{}) *SCIMListResponse {
+	return &SCIMListResponse{
+		Schemas:      []string{SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: count,
+		Resources:    resources,
+	}
+}
+
+// scimDisplayName memilih nama user dari displayName, name.formatted, lalu givenName + familyName
+func scimDisplayName(input SCIMUserInput) string {
+	if name := strings.TrimSpace(input.DisplayName); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(input.Name.Formatted); name != "" {
+		return name
+	}
+	return strings.TrimSpace(input.Name.GivenName + " " + input.Name.FamilyName)
+}
+
+// validUserName menandakan userName berupa alamat email, karena email adalah identitas login user
+func validUserName(userName string) bool {
+	address, err := mail.ParseAddress(userName)
+	return err == nil && address.Address == userName
+}
+
+// scimID mengubah id resource menjadi ID database, id yang bukan angka menjadi 0 (tidak ada resource)
+func scimID(value string) uint {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// memberIDs mengambil ID user dari daftar members
+func memberIDs(members []SCIMReference) ([]uint, error) {
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		id := scimID(member.Value)
+		if id == 0 {
+			return nil, ErrSCIMMemberNotFound
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// excludesAttribute menandakan atribut ada di excludedAttributes (dipisah koma)
+func excludesAttribute(excluded, attribute string) bool {
+	for _, name := range strings.Split(excluded, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), attribute) {
+			return true
+		}
+	}
+	return false
+}
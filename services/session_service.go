@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// SessionService mengelola session login dan riwayat percobaan login
+type SessionService struct {
+	sessions repositories.SessionRepository
+	attempts repositories.LoginAttemptRepository
+	audit    *AuditService
+}
+
+// NewSessionService membuat SessionService baru
+func NewSessionService(sessions repositories.SessionRepository, attempts repositories.LoginAttemptRepository, audit *AuditService) *SessionService {
+	return &SessionService{sessions: sessions, attempts: attempts, audit: audit}
+}
+
+// Start membuat session baru untuk user dengan IP dan user agent dari context
+func (s *SessionService) Start(ctx context.Context, user *models.User, expiresAt time.Time) (*models.Session, error) {
+	info := utils.RequestInfoFrom(ctx)
+	session := &models.Session{
+		ID:        utils.NewID(),
+		UserID:    user.ID,
+		IP:        info.IP,
+		UserAgent: truncate(info.UserAgent, 255),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// RecordAttempt mencatat percobaan login. reason kosong berarti login berhasil.
+// Kegagalan mencatat hanya di-log agar tidak mengubah hasil login.
+func (s *SessionService) RecordAttempt(ctx context.Context, email string, userID *uint, reason, sessionID string) {
+	info := utils.RequestInfoFrom(ctx)
+	attempt := &models.LoginAttempt{
+		UserID:    userID,
+		Email:     truncate(email, 255),
+		Success:   reason == "",
+		Reason:    reason,
+		SessionID: sessionID,
+		IP:        info.IP,
+		UserAgent: truncate(info.UserAgent, 255),
+	}
+	if err := s.attempts.Create(ctx, attempt); err != nil {
+		log.Printf("[session] gagal mencatat percobaan login %s: %v", email, err)
+	}
+}
+
+// FailedAttemptsSince menghitung percobaan login gagal milik user dengan alasan reason sejak waktu since
+func (s *SessionService) FailedAttemptsSince(ctx context.Context, userID uint, reason string, since time.Time) (int64, error) {
+	return s.attempts.CountFailedSince(ctx, userID, reason, since)
+}
+
+// List mengambil session aktif milik user. currentID menandai session yang sedang dipakai.
+func (s *SessionService) List(ctx context.Context, userID uint, currentID string) ([]models.Session, error) {
+	sessions, err := s.sessions.FindActiveByUser(ctx, userID, utils.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke mencabut satu session aktif milik user
+func (s *SessionService) Revoke(ctx context.Context, userID uint, sessionID string) error {
+	session, err := s.sessions.FindByID(ctx, sessionID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || !session.ActiveAt(utils.Now()) {
+		return ErrSessionNotFound
+	}
+	return s.revoke(ctx, session)
+}
+
+// RevokeOthers mencabut semua session aktif user kecuali currentID dan mengembalikan jumlahnya.
+// currentID kosong (token tanpa session) berarti semua session dicabut.
+func (s *SessionService) RevokeOthers(ctx context.Context, userID uint, currentID string) (int, error) {
+	sessions, err := s.sessions.FindActiveByUser(ctx, userID, utils.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for i := range sessions {
+		if sessions[i].ID == currentID {
+			continue
+		}
+		if err := s.revoke(ctx, &sessions[i]); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// End mencabut session saat logout. Session yang tidak ada atau sudah berakhir diabaikan.
+func (s *SessionService) End(ctx context.Context, sessionID string) error {
+	session, err := s.sessions.FindByID(ctx, sessionID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !session.ActiveAt(utils.Now()) {
+		return nil
+	}
+	return s.revoke(ctx, session)
+}
+
+// revoke menandai session dicabut dan memasukkannya ke blacklist sampai waktu kadaluwarsanya
+func (s *SessionService) revoke(ctx context.Context, session *models.Session) error {
+	before := *session
+	now := utils.Now()
+	session.RevokedAt = &now
+	if err := s.sessions.Update(ctx, session); err != nil {
+		return err
+	}
+	utils.RevokeSession(session.ID, session.ExpiresAt)
+	s.audit.Record(ctx, AuditEntry{Action: "session.revoke", TargetType: "session", TargetID: session.ID, Before: &before, After: session})
+	return nil
+}
+
+// LoginAttemptPage adalah satu halaman riwayat login
+type LoginAttemptPage struct {
+	Items   []models.LoginAttempt `json:"items"`
+	Page    int                   `json:"page"`
+	PerPage int                   `json:"per_page"`
+	Total   int64                 `json:"total"`
+}
+
+// ListAttempts mengambil riwayat login per halaman, page dimulai dari 1
+func (s *SessionService) ListAttempts(ctx context.Context, filter repositories.LoginAttemptFilter, page, perPage int) (*LoginAttemptPage, error) {
+	page, perPage, offset := paginate(page, perPage)
+	attempts, total, err := s.attempts.FindPage(ctx, filter, offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginAttemptPage{Items: attempts, Page: page, PerPage: perPage, Total: total}, nil
+}
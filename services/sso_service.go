@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang-starter-kit/config"
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+	"golang.org/x/oauth2"
+)
+
+// SSOService berisi aturan bisnis untuk login lewat identity provider OpenID Connect eksternal:
+// authorization code + PKCE, verifikasi ID token terhadap JWKS provider, identitas yang
+// ditautkan ke user dan pembuatan user otomatis (just-in-time provisioning).
+type SSOService struct {
+	identities  repositories.UserIdentityRepository
+	states      repositories.SSOStateRepository
+	users       repositories.UserRepository
+	roles       repositories.RoleRepository
+	userService *UserService
+	sessions    *SessionService
+	config      config.SSOConfig
+	audit       *AuditService
+	httpClient  *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider // Hasil discovery, dibaca sekali per provider
+}
+
+// NewSSOService membuat SSOService baru
+func NewSSOService(
+	identities repositories.UserIdentityRepository,
+	states repositories.SSOStateRepository,
+	users repositories.UserRepository,
+	roles repositories.RoleRepository,
+	userService *UserService,
+	sessions *SessionService,
+	config config.SSOConfig,
+	audit *AuditService,
+) *SSOService {
+	return &SSOService{
+		identities:  identities,
+		states:      states,
+		users:       users,
+		roles:       roles,
+		userService: userService,
+		sessions:    sessions,
+		config:      config,
+		audit:       audit,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		providers:   map[string]*oidc.Provider{},
+	}
+}
+
+// SSOProviderInfo adalah provider yang ditampilkan di halaman login
+type SSOProviderInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// SSOStart adalah URL identity provider yang harus dibuka browser. Frontend menyimpan state
+// (misalnya di sessionStorage) dan mencocokkannya dengan state yang kembali ke halaman callback.
+type SSOStart struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// ssoIdentity adalah claim dari ID token yang sudah diverifikasi
+type ssoIdentity struct {
+	Provider      config.SSOProvider
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Claims        map[string]interface{}
+}
+
+// Providers menampilkan provider yang dikonfigurasi
+func (s *SSOService) Providers() []SSOProviderInfo {
+	providers := []SSOProviderInfo{}
+	for _, provider := range s.config.Providers {
+		providers = append(providers, SSOProviderInfo{ID: provider.ID, Name: provider.Name})
+	}
+	return providers
+}
+
+// Start memulai login SSO. userID diisi jika identitas akan ditautkan ke user yang sedang login.
+func (s *SSOService) Start(ctx context.Context, providerID string, userID *uint) (*SSOStart, error) {
+	provider, ok := s.config.Provider(providerID)
+	if !ok {
+		return nil, ErrSSOProviderNotFound
+	}
+	oauthConfig, _, err := s.client(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.NewToken(24)
+	if err != nil {
+		return nil, wrap(ErrGenerateToken, err)
+	}
+	nonce, err := utils.NewToken(16)
+	if err != nil {
+		return nil, wrap(ErrGenerateToken, err)
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	login := &models.SSOLoginState{
+		StateHash:    utils.HashToken(state),
+		Provider:     provider.ID,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserID:       userID,
+		ExpiresAt:    utils.Now().Add(s.config.StateTTL),
+	}
+	if err := s.states.Create(ctx, login); err != nil {
+		return nil, err
+	}
+
+	return &SSOStart{
+		AuthorizationURL: oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)),
+		State:            state,
+		ExpiresAt:        login.ExpiresAt,
+	}, nil
+}
+
+// Login menyelesaikan login SSO dari halaman callback lalu menerbitkan JWT yang sama dengan
+// login password. Identitas yang belum dikenal ditautkan lewat email (jika LinkByEmail) atau
+// dibuatkan user baru (jika Provision).
+func (s *SSOService) Login(ctx context.Context, providerID, state, code string) (*LoginResult, error) {
+	identity, err := s.redeem(ctx, providerID, state, code, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := s.identities.FindBySubject(ctx, identity.Provider.ID, identity.Subject)
+	var user *models.User
+	switch {
+	case err == nil:
+		if user, err = s.users.FindByID(ctx, linked.UserID); errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrSSOAccountUnavailable
+		}
+	case errors.Is(err, repositories.ErrNotFound):
+		linked, user, err = s.provision(ctx, identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.DeactivatedAt != nil {
+		return nil, ErrSSOAccountUnavailable
+	}
+
+	if user, err = s.syncRole(ctx, user, identity); err != nil {
+		return nil, err
+	}
+	s.touch(ctx, linked, identity)
+
+	result, err := startLogin(ctx, s.sessions, user)
+	if err != nil {
+		return nil, err
+	}
+	s.sessions.RecordAttempt(ctx, user.Email, &user.ID, "", result.SessionID)
+	return result, nil
+}
+
+// Link menyelesaikan alur penautan identitas baru ke user yang sedang login
+func (s *SSOService) Link(ctx context.Context, userID uint, providerID, state, code string) (*models.UserIdentity, error) {
+	identity, err := s.redeem(ctx, providerID, state, code, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := s.identities.FindBySubject(ctx, identity.Provider.ID, identity.Subject)
+	if err == nil {
+		if linked.UserID != userID {
+			return nil, ErrSSOIdentityLinked
+		}
+		s.touch(ctx, linked, identity)
+		return linked, nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	return s.link(ctx, userID, identity)
+}
+
+// Identities menampilkan identitas yang ditautkan ke user
+func (s *SSOService) Identities(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	return s.identities.FindByUser(ctx, userID)
+}
+
+// Unlink melepas identitas dari user. Identitas terakhir milik user tanpa password lokal
+// tidak bisa dilepas karena user tidak akan bisa login lagi.
+func (s *SSOService) Unlink(ctx context.Context, userID, id uint) error {
+	identity, err := s.identities.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && identity.UserID != userID) {
+		return ErrUserIdentityNotFound
+	}
+	if err != nil {
+		return err
+	}
+	switch identity.Provider {
+	case models.IdentityProviderLDAP:
+		return ErrLDAPIdentityManaged
+	case models.IdentityProviderSCIM:
+		return ErrSCIMIdentityManaged
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Password == "" {
+		identities, err := s.identities.FindByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) <= 1 {
+			return ErrSSOLastIdentity
+		}
+	}
+
+	if err := s.identities.Delete(ctx, identity); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user_identity.unlink", TargetType: "user", TargetID: userID, Before: identity})
+	return nil
+}
+
+// redeem memeriksa state, menukar code di identity provider lalu memverifikasi ID token
+func (s *SSOService) redeem(ctx context.Context, providerID, state, code string, userID *uint) (*ssoIdentity, error) {
+	provider, ok := s.config.Provider(providerID)
+	if !ok {
+		return nil, ErrSSOProviderNotFound
+	}
+
+	login, err := s.states.FindByHash(ctx, utils.HashToken(state))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrSSOStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := utils.Now()
+	sameUser := (login.UserID == nil) == (userID == nil) && (userID == nil || *login.UserID == *userID)
+	if login.Provider != provider.ID || !sameUser || login.UsedAt != nil || !now.Before(login.ExpiresAt) {
+		return nil, ErrSSOStateInvalid
+	}
+	if used, err := s.states.MarkUsed(ctx, login, now); err != nil {
+		return nil, err
+	} else if !used {
+		return nil, ErrSSOStateInvalid
+	}
+
+	oauthConfig, oidcProvider, err := s.client(provider)
+	if err != nil {
+		return nil, err
+	}
+	ctx = oidc.ClientContext(ctx, s.httpClient)
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(login.CodeVerifier))
+	if err != nil {
+		return nil, wrap(ErrSSOLoginFailed, err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, wrap(ErrSSOLoginFailed, errors.New("token response has no id_token"))
+	}
+	idToken, err := oidcProvider.Verifier(&oidc.Config{ClientID: provider.ClientID, Now: utils.Now}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, wrap(ErrSSOLoginFailed, err)
+	}
+	if idToken.Nonce != login.Nonce {
+		return nil, wrap(ErrSSOLoginFailed, errors.New("id_token nonce mismatch"))
+	}
+
+	identity := &ssoIdentity{Provider: provider, Subject: idToken.Subject}
+	if err := idToken.Claims(&identity.Claims); err != nil {
+		return nil, wrap(ErrSSOLoginFailed, err)
+	}
+	identity.Email, _ = identity.Claims["email"].(string)
+	identity.EmailVerified, _ = identity.Claims["email_verified"].(bool)
+	identity.Name, _ = identity.Claims["name"].(string)
+	identity.Email = strings.TrimSpace(identity.Email)
+	return identity, nil
+}
+
+// provision menautkan identitas yang belum dikenal ke user dengan email yang sama, atau membuat user baru
+func (s *SSOService) provision(ctx context.Context, identity *ssoIdentity) (*models.UserIdentity, *models.User, error) {
+	provider := identity.Provider
+	if identity.Email != "" && provider.LinkByEmail && identity.EmailVerified {
+		user, err := s.users.FindByEmail(ctx, identity.Email)
+		if err == nil {
+			linked, err := s.link(ctx, user.ID, identity)
+			return linked, user, err
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	if !provider.Provision {
+		return nil, nil, ErrSSONotProvisioned
+	}
+	if identity.Email == "" {
+		return nil, nil, ErrSSOEmailMissing
+	}
+
+	roleName := s.mappedRole(identity)
+	if roleName == "" {
+		roleName = provider.DefaultRole
+	}
+	var roleID uint
+	if roleName != "" {
+		role, err := s.findRole(ctx, roleName)
+		if err != nil {
+			return nil, nil, err
+		}
+		roleID = role.ID
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+	user, err := s.userService.createWithHash(ctx, CreateUserParams{Name: name, Email: identity.Email, IDRole: roleID}, "")
+	if errors.Is(err, ErrEmailTaken) {
+		return nil, nil, ErrSSOEmailTaken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	linked, err := s.link(ctx, user.ID, identity)
+	return linked, user, err
+}
+
+// link menyimpan identitas baru untuk user
+func (s *SSOService) link(ctx context.Context, userID uint, identity *ssoIdentity) (*models.UserIdentity, error) {
+	linked := &models.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider.ID,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := s.identities.Create(ctx, linked); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user_identity.link", TargetType: "user", TargetID: userID, After: linked})
+	return linked, nil
+}
+
+// syncRole memperbarui role user jika claim role dari identity provider cocok dengan salah satu
+// mapping. Jika tidak ada yang cocok, role user tidak diubah.
+func (s *SSOService) syncRole(ctx context.Context, user *models.User, identity *ssoIdentity) (*models.User, error) {
+	roleName := s.mappedRole(identity)
+	if roleName == "" || roleName == user.Role.Name {
+		return user, nil
+	}
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *user
+	user.IDRole = role.ID
+	user.Role = *role
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.update", TargetType: "user", TargetID: user.ID, Before: before, After: user})
+	return user, nil
+}
+
+// mappedRole mengembalikan nama role dari mapping pertama yang cocok dengan claim role
+func (s *SSOService) mappedRole(identity *ssoIdentity) string {
+	provider := identity.Provider
+	if provider.RoleClaim == "" {
+		return ""
+	}
+
+	values := map[string]bool{}
+	switch claim := identity.Claims[provider.RoleClaim].(type) {
+	case string:
+		values[claim] = true
+	case []interface{}:
+		for _, item := range claim {
+			if value, ok := item.(string); ok {
+				values[value] = true
+			}
+		}
+	}
+	for _, mapping := range provider.RoleMappings {
+		if values[mapping.Value] {
+			return mapping.Role
+		}
+	}
+	return ""
+}
+
+// findRole mengambil role hasil mapping, role yang tidak ada berarti konfigurasi SSO salah
+func (s *SSOService) findRole(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.roles.FindByName(ctx, name)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, wrap(ErrSSORoleMissing, fmt.Errorf("role %q", name))
+	}
+	return role, err
+}
+
+// touch mencatat waktu login dan email terbaru dari identity provider. Kegagalan tidak
+// menggagalkan login.
+func (s *SSOService) touch(ctx context.Context, linked *models.UserIdentity, identity *ssoIdentity) {
+	now := utils.Now()
+	linked.LastLoginAt = &now
+	if identity.Email != "" {
+		linked.Email = identity.Email
+	}
+	_ = s.identities.Update(ctx, linked)
+}
+
+// client membaca metadata provider (discovery) lalu menyiapkan konfigurasi OAuth2-nya.
+// Discovery yang gagal tidak disimpan agar dicoba lagi di request berikutnya.
+func (s *SSOService) client(provider config.SSOProvider) (*oauth2.Config, *oidc.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oidcProvider, ok := s.providers[provider.ID]
+	if !ok {
+		// Context request tidak dipakai karena provider (dan JWKS-nya) dipakai ulang setelah request selesai
+		discovered, err := oidc.NewProvider(oidc.ClientContext(context.Background(), s.httpClient), provider.Issuer)
+		if err != nil {
+			return nil, nil, wrap(ErrSSOProviderUnavailable, err)
+		}
+		s.providers[provider.ID] = discovered
+		oidcProvider = discovered
+	}
+
+	scopes := provider.Scopes
+	if !containsScope(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+	return &oauth2.Config{
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		Endpoint:     oidcProvider.Endpoint(),
+		RedirectURL:  provider.RedirectURL,
+		Scopes:       scopes,
+	}, oidcProvider, nil
+}
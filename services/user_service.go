@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/repositories"
+	"golang-starter-kit/utils"
+)
+
+// UserService berisi aturan bisnis untuk pengelolaan user
+type UserService struct {
+	users       repositories.UserRepository
+	roles       repositories.RoleRepository
+	defaultRole string
+	passwords   *PasswordPolicy
+	sessions    *SessionService
+	audit       *AuditService
+}
+
+// NewUserService membuat UserService baru. defaultRole adalah nama role
+// untuk user baru yang dibuat tanpa IDRole.
+func NewUserService(
+	users repositories.UserRepository,
+	roles repositories.RoleRepository,
+	defaultRole string,
+	passwords *PasswordPolicy,
+	sessions *SessionService,
+	audit *AuditService,
+) *UserService {
+	return &UserService{users: users, roles: roles, defaultRole: defaultRole, passwords: passwords, sessions: sessions, audit: audit}
+}
+
+// CreateUserParams adalah data yang dibutuhkan untuk membuat user baru.
+// IDRole = 0 berarti user mendapat role default.
+type CreateUserParams struct {
+	Name     string
+	Email    string
+	Password string
+	IDRole   uint
+}
+
+// UpdateUserParams adalah data yang boleh diubah pada user, nil berarti tidak diubah
+type UpdateUserParams struct {
+	Name     *string
+	Email    *string
+	Password *string
+	IDRole   *uint
+}
+
+// List mengambil semua user sesuai DeletedScope
+func (s *UserService) List(ctx context.Context, scope repositories.DeletedScope) ([]models.User, error) {
+	return s.users.FindAll(ctx, scope)
+}
+
+// Get mengambil user berdasarkan ID
+func (s *UserService) Get(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.users.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Create memvalidasi dan menyimpan user baru
+func (s *UserService) Create(ctx context.Context, params CreateUserParams) (*models.User, error) {
+	// Validasi password sesuai kebijakan
+	err := s.passwords.Validate(ctx, params.Password, PasswordSubject{Name: params.Name, Email: params.Email})
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash password
+	hashedPassword, err := hashPassword(params.Password)
+	if err != nil {
+		return nil, err
+	}
+	return s.createWithHash(ctx, params, hashedPassword)
+}
+
+// createWithHash menyimpan user baru dengan password yang sudah divalidasi dan di-hash sebelumnya
+// (misalnya registrasi yang menunggu konfirmasi email). params.Password diabaikan. hashedPassword
+// kosong berarti user tidak bisa login dengan password (misalnya user dari SSO).
+func (s *UserService) createWithHash(ctx context.Context, params CreateUserParams, hashedPassword string) (*models.User, error) {
+	// Cek apakah email sudah terdaftar
+	if err := s.ensureEmailAvailable(ctx, params.Email, 0); err != nil {
+		return nil, err
+	}
+
+	// Cek apakah role ada, atau pakai role default
+	if params.IDRole == 0 {
+		role, err := s.roles.FindByName(ctx, s.defaultRole)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrDefaultRoleMissing
+		}
+		if err != nil {
+			return nil, err
+		}
+		params.IDRole = role.ID
+	} else if err := s.ensureRoleExists(ctx, params.IDRole); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Name:     params.Name,
+		Email:    params.Email,
+		Password: hashedPassword,
+		IDRole:   params.IDRole,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	// User dari SSO tidak punya password lokal, tidak ada yang perlu diingat
+	if user.Password != "" {
+		if err := s.passwords.Remember(ctx, user.ID, user.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ambil user beserta role-nya
+	created, err := s.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.create", TargetType: "user", TargetID: created.ID, After: created})
+	return created, nil
+}
+
+// Update mengubah data user yang sudah ada. Jika password diubah, semua session user dicabut.
+func (s *UserService) Update(ctx context.Context, id uint, params UpdateUserParams) (*models.User, error) {
+	// Cek apakah email sudah terdaftar
+	if params.Email != nil && *params.Email != "" {
+		if err := s.ensureEmailAvailable(ctx, *params.Email, id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Cek apakah role ada
+	if params.IDRole != nil {
+		if err := s.ensureRoleExists(ctx, *params.IDRole); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+
+	if params.Name != nil && *params.Name != "" {
+		user.Name = *params.Name
+	}
+	if params.Email != nil && *params.Email != "" {
+		user.Email = *params.Email
+	}
+	passwordChanged := params.Password != nil && *params.Password != ""
+	if passwordChanged {
+		// Divalidasi setelah nama dan email diisi agar aturan personal memakai data terbaru
+		if err := s.setPassword(ctx, user, *params.Password); err != nil {
+			return nil, err
+		}
+	}
+	if params.IDRole != nil {
+		user.IDRole = *params.IDRole
+	}
+
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if passwordChanged {
+		if err := s.passwords.Remember(ctx, user.ID, user.Password); err != nil {
+			return nil, err
+		}
+		// Password direset admin, semua session lama user harus login ulang
+		if _, err := s.sessions.RevokeOthers(ctx, user.ID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ambil ulang agar relasi role sesuai dengan IDRole terbaru
+	updated, err := s.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.update", TargetType: "user", TargetID: updated.ID, Before: &before, After: updated})
+	return updated, nil
+}
+
+// Delete menghapus user (soft delete)
+func (s *UserService) Delete(ctx context.Context, id uint) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.users.Delete(ctx, user); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.delete", TargetType: "user", TargetID: user.ID, Before: user})
+	return nil
+}
+
+// Restore mengembalikan user yang sudah di-soft delete
+func (s *UserService) Restore(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.getDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Email user yang dihapus bisa saja sudah dipakai user baru
+	if err := s.ensureEmailAvailable(ctx, user.Email, user.ID); err != nil {
+		return nil, err
+	}
+
+	// Role user bisa saja sudah dihapus selama user berada di tempat sampah
+	if err := s.ensureRoleExists(ctx, user.IDRole); err != nil {
+		return nil, err
+	}
+
+	before := *user
+	if err := s.users.Restore(ctx, user); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.restore", TargetType: "user", TargetID: restored.ID, Before: &before, After: restored})
+	return restored, nil
+}
+
+// Purge menghapus permanen user yang sudah di-soft delete
+func (s *UserService) Purge(ctx context.Context, id uint) error {
+	user, err := s.getDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.users.Purge(ctx, user); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{Action: "user.purge", TargetType: "user", TargetID: user.ID, Before: user})
+	return nil
+}
+
+// setDeactivated menonaktifkan atau mengaktifkan kembali user. User yang dinonaktifkan
+// langsung kehilangan semua session-nya.
+func (s *UserService) setDeactivated(ctx context.Context, sessions *SessionService, user *models.User, deactivated bool) error {
+	before := *user
+	action := "user.activate"
+	user.DeactivatedAt = nil
+	if deactivated {
+		now := utils.Now()
+		user.DeactivatedAt = &now
+		action = "user.deactivate"
+	}
+	if err := s.users.Update(ctx, user); err != nil {
+		return err
+	}
+	if deactivated {
+		if _, err := sessions.RevokeOthers(ctx, user.ID, ""); err != nil {
+			return err
+		}
+	}
+	s.audit.Record(ctx, AuditEntry{Action: action, TargetType: "user", TargetID: user.ID, Before: &before, After: user})
+	return nil
+}
+
+// getDeleted mengambil user yang sudah di-soft delete berdasarkan ID
+func (s *UserService) getDeleted(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.users.FindDeletedByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ensureEmailAvailable mengembalikan ErrEmailTaken jika email sudah dipakai user lain
+func (s *UserService) ensureEmailAvailable(ctx context.Context, email string, excludeID uint) error {
+	exists, err := s.users.EmailExists(ctx, email, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// ensureRoleExists mengembalikan ErrInvalidRole jika role tidak ada atau sudah dihapus
+func (s *UserService) ensureRoleExists(ctx context.Context, id uint) error {
+	_, err := s.roles.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrInvalidRole
+	}
+	return err
+}
+
+// setPassword memvalidasi password baru terhadap kebijakan lalu mengisi hash-nya ke user (belum disimpan)
+func (s *UserService) setPassword(ctx context.Context, user *models.User, password string) error {
+	if err := s.passwords.Validate(ctx, password, subjectOf(user)); err != nil {
+		return err
+	}
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	return nil
+}
+
+// hashPassword meng-hash password dengan PasswordHasher aplikasi
+func hashPassword(password string) (string, error) {
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return "", wrap(ErrHashPassword, err)
+	}
+	return hashed, nil
+}
@@ -0,0 +1,64 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+// Response adalah bentuk utils.APIResponse dengan Data yang belum di-decode
+type Response struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// DecodeResponse membaca body response sebagai envelope APIResponse
+func DecodeResponse(t *testing.T, rec *httptest.ResponseRecorder) Response {
+	t.Helper()
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v\nbody: %s", err, rec.Body.String())
+	}
+	return resp
+}
+
+// AssertSuccess memastikan response sukses dengan HTTP status dan pesan yang diharapkan,
+// lalu men-decode field data ke dest (boleh nil)
+func AssertSuccess(t *testing.T, rec *httptest.ResponseRecorder, status int, message string, dest interface{}) Response {
+	t.Helper()
+	return assertResponse(t, rec, status, "success", message, dest)
+}
+
+// AssertError memastikan response error dengan HTTP status dan pesan yang diharapkan
+func AssertError(t *testing.T, rec *httptest.ResponseRecorder, status int, message string) Response {
+	t.Helper()
+	return assertResponse(t, rec, status, "error", message, nil)
+}
+
+// AssertStatus hanya memastikan HTTP status, untuk response di luar envelope APIResponse
+func AssertStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d\nbody: %s", rec.Code, status, rec.Body.String())
+	}
+}
+
+func assertResponse(t *testing.T, rec *httptest.ResponseRecorder, status int, kind, message string, dest interface{}) Response {
+	t.Helper()
+	AssertStatus(t, rec, status)
+
+	resp := DecodeResponse(t, rec)
+	if resp.Status != kind {
+		t.Fatalf("status field = %q, want %q\nbody: %s", resp.Status, kind, rec.Body.String())
+	}
+	if message != "" && resp.Message != message {
+		t.Fatalf("message = %q, want %q", resp.Message, message)
+	}
+	if dest != nil {
+		if err := json.Unmarshal(resp.Data, dest); err != nil {
+			t.Fatalf("decode data: %v\ndata: %s", err, resp.Data)
+		}
+	}
+	return resp
+}
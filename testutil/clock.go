@@ -0,0 +1,69 @@
+package testutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTime adalah waktu awal FakeClock yang dipasang oleh New
+var DefaultTime = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// FakeClock adalah utils.Clock yang waktunya hanya berubah lewat Set atau Advance
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock membuat FakeClock yang berhenti di waktu now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now mengembalikan waktu FakeClock saat ini
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set memindahkan FakeClock ke waktu tertentu
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance memajukan FakeClock sebesar d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// SequentialIDs adalah utils.IDGenerator deterministik: ID dan token berurutan
+type SequentialIDs struct {
+	mu sync.Mutex
+	n  int
+}
+
+// NewID menghasilkan ID berformat UUID dengan angka berurutan
+func (g *SequentialIDs) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.n++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", g.n)
+}
+
+// NewToken menghasilkan token n byte yang isinya angka berurutan
+func (g *SequentialIDs) NewToken(n int) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.n++
+	raw := fmt.Sprintf("%0*d", n, g.n)
+	if len(raw) > n {
+		raw = raw[len(raw)-n:]
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw)), nil
+}
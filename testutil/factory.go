@@ -0,0 +1,102 @@
+package testutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultPassword adalah password yang dipakai factory jika tidak diisi
+const DefaultPassword = "password123"
+
+var sequence atomic.Int64
+
+// nextSequence menghasilkan angka unik untuk nama dan email factory
+func nextSequence() int64 {
+	return sequence.Add(1)
+}
+
+// RoleAttrs adalah atribut opsional untuk CreateRole
+type RoleAttrs struct {
+	Name string
+}
+
+// CreateRole menyimpan role baru, atribut kosong diisi nilai default
+func (h *Harness) CreateRole(t *testing.T, attrs RoleAttrs) models.Role {
+	t.Helper()
+	if attrs.Name == "" {
+		attrs.Name = fmt.Sprintf("role-%d", nextSequence())
+	}
+
+	role := models.Role{Name: attrs.Name}
+	if err := h.DB.Create(&role).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	return role
+}
+
+// UserAttrs adalah atribut opsional untuk CreateUser
+type UserAttrs struct {
+	Name     string
+	Email    string
+	Password string
+	IDRole   uint
+}
+
+// CreateUser menyimpan user baru beserta role-nya. Jika IDRole kosong, role baru dibuat.
+// Password disimpan dalam bentuk hash, password asli bisa dibaca dari attrs atau DefaultPassword.
+func (h *Harness) CreateUser(t *testing.T, attrs UserAttrs) models.User {
+	t.Helper()
+	n := nextSequence()
+	if attrs.Name == "" {
+		attrs.Name = fmt.Sprintf("User %d", n)
+	}
+	if attrs.Email == "" {
+		attrs.Email = fmt.Sprintf("user%d@example.com", n)
+	}
+	if attrs.Password == "" {
+		attrs.Password = DefaultPassword
+	}
+	if attrs.IDRole == 0 {
+		attrs.IDRole = h.CreateRole(t, RoleAttrs{}).ID
+	}
+
+	// Cost minimum agar test tetap cepat
+	hashed, err := bcrypt.GenerateFromPassword([]byte(attrs.Password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+
+	user := models.User{
+		Name:     attrs.Name,
+		Email:    attrs.Email,
+		Password: string(hashed),
+		IDRole:   attrs.IDRole,
+	}
+	if err := h.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := h.DB.Preload("Role").First(&user, user.ID).Error; err != nil {
+		t.Fatalf("reload user: %v", err)
+	}
+	return user
+}
+
+// CreateAdmin menyimpan user dengan role sistem admin
+func (h *Harness) CreateAdmin(t *testing.T) models.User {
+	t.Helper()
+	return h.CreateUser(t, UserAttrs{IDRole: h.SystemRole(t, models.AdminRoleName).ID})
+}
+
+// SystemRole mengambil role sistem yang dibuat saat migrasi
+func (h *Harness) SystemRole(t *testing.T, name string) models.Role {
+	t.Helper()
+	var role models.Role
+	if err := h.DB.Where("name = ? AND is_system = ?", name, true).First(&role).Error; err != nil {
+		t.Fatalf("system role %q: %v", name, err)
+	}
+	return role
+}
@@ -0,0 +1,167 @@
+// Package testutil menyediakan harness untuk integration test: router lengkap
+// dengan database sementara, factory data, dan helper request/response.
+package testutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"golang-starter-kit/config"
+	"golang-starter-kit/controllers"
+	"golang-starter-kit/mailer"
+	"golang-starter-kit/models"
+	"golang-starter-kit/routes"
+	"golang-starter-kit/utils"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestJWTSecret adalah secret JWT yang dipakai selama test
+const TestJWTSecret = "test-secret"
+
+// Harness menyimpan router, koneksi database dan jam palsu untuk satu test
+type Harness struct {
+	Router *gin.Engine
+	DB     *gorm.DB
+	Clock  *FakeClock
+	IDs    *SequentialIDs
+	Mailer *mailer.MemoryMailer
+}
+
+// New menyiapkan database sementara, menjalankan migrasi dan membangun router lengkap.
+//
+// Secara default database yang dipakai adalah SQLite in-memory. Jika env
+// TEST_DATABASE_DSN diisi (DSN PostgreSQL), test berjalan di schema PostgreSQL
+// sementara yang dihapus kembali setelah test selesai.
+func New(t *testing.T) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+
+	t.Setenv("JWT_SECRET", TestJWTSecret)
+	t.Setenv("BLACKLIST_FILE", filepath.Join(t.TempDir(), "blacklist.json"))
+
+	// Password di test sengaja sederhana, skor kekuatan hanya dicek jika test mengisi PASSWORD_MIN_SCORE
+	setDefaultEnv(t, "PASSWORD_MIN_SCORE", "0")
+	// Parameter argon2id minimum agar test tetap cepat
+	setDefaultEnv(t, "PASSWORD_ARGON2_MEMORY_KB", "64")
+	setDefaultEnv(t, "PASSWORD_ARGON2_ITERATIONS", "1")
+	setDefaultEnv(t, "PASSWORD_ARGON2_PARALLELISM", "1")
+
+	// Waktu dan ID dibuat deterministik, dikembalikan ke default setelah test
+	clock := NewFakeClock(DefaultTime)
+	ids := &SequentialIDs{}
+	utils.SetClock(clock)
+	utils.SetIDGenerator(ids)
+	t.Cleanup(func() {
+		utils.SetClock(nil)
+		utils.SetIDGenerator(nil)
+		utils.SetPasswordHasher(nil)
+	})
+
+	db := openDatabase(t)
+	if err := models.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// Global yang dipakai aplikasi diarahkan ke database test
+	config.DB = db
+	models.DB = db
+	utils.InitBlacklist()
+
+	// Email disimpan di memori agar bisa diperiksa oleh test
+	mail := mailer.NewMemoryMailer()
+	mailer.SetDefault(mail)
+	t.Cleanup(func() { mailer.SetDefault(nil) })
+
+	controllers.InitController()
+
+	return &Harness{
+		Router: routes.SetupRoutes(),
+		DB:     db,
+		Clock:  clock,
+		IDs:    ids,
+		Mailer: mail,
+	}
+}
+
+// setDefaultEnv mengisi env untuk test ini kecuali sudah diisi oleh test sebelum memanggil New
+func setDefaultEnv(t *testing.T, key, value string) {
+	if _, ok := os.LookupEnv(key); !ok {
+		t.Setenv(key, value)
+	}
+}
+
+// openDatabase membuka SQLite in-memory atau schema PostgreSQL sementara
+func openDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormConfig := &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: utils.Now,
+	}
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		// Nama database unik agar setiap test mendapat database kosong
+		name := fmt.Sprintf("file:%s?mode=memory&cache=shared&_pragma=foreign_keys(1)", randomName())
+		db, err := gorm.Open(sqlite.Open(name), gormConfig)
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		closeOnCleanup(t, db)
+		return db
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	closeOnCleanup(t, admin)
+
+	schema := "test_" + randomName()
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+	})
+
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), gormConfig)
+	if err != nil {
+		t.Fatalf("open postgres schema: %v", err)
+	}
+	closeOnCleanup(t, db)
+	return db
+}
+
+// closeOnCleanup menutup koneksi database setelah test selesai
+func closeOnCleanup(t *testing.T, db *gorm.DB) {
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
+
+// randomName menghasilkan nama acak untuk database atau schema
+func randomName() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ServeHTTP meneruskan request ke router aplikasi
+func (h *Harness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Router.ServeHTTP(w, r)
+}
@@ -0,0 +1,203 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MockIdP adalah identity provider OpenID Connect lokal untuk test login SSO. Endpoint authorize
+// langsung menyetujui request dengan claim dari Login, tanpa halaman login.
+type MockIdP struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+	now    func() time.Time
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	next   map[string]interface{} // Claim untuk request authorize berikutnya
+	codes  map[string]mockIdPCode // Authorization code yang belum ditukar
+	issued int
+}
+
+// mockIdPCode adalah authorization code yang diterbitkan MockIdP
+type mockIdPCode struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	claims      map[string]interface{}
+}
+
+// NewMockIdP menjalankan identity provider lokal. now dipakai untuk claim iat dan exp ID token,
+// biasanya h.Clock.Now agar sama dengan jam aplikasi.
+func NewMockIdP(t *testing.T, now func() time.Time) *MockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &MockIdP{
+		ClientID:     "starter-kit",
+		ClientSecret: "idp-secret",
+		now:          now,
+		key:          key,
+		codes:        map[string]mockIdPCode{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	p.URL = p.server.URL
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// Login mengikuti authorization URL dari aplikasi sebagai user dengan claim tertentu (minimal
+// "sub"), lalu mengembalikan code dan state yang dikirim identity provider ke halaman callback.
+func (p *MockIdP) Login(t *testing.T, authorizationURL string, claims map[string]interface{}) (code, state string) {
+	t.Helper()
+	p.mu.Lock()
+	p.next = claims
+	p.mu.Unlock()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+// discovery menjawab /.well-known/openid-configuration
+func (p *MockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+// jwks menampilkan public key penanda tangan ID token
+func (p *MockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": "test",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize menerbitkan code untuk claim dari Login dan redirect ke redirect_uri
+func (p *MockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != p.ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorize request", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	p.issued++
+	code := "code-" + strconv.Itoa(p.issued)
+	p.codes[code] = mockIdPCode{
+		redirectURI: query.Get("redirect_uri"),
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		claims:      p.next,
+	}
+	p.mu.Unlock()
+
+	target := query.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// token menukar code dengan ID token setelah memeriksa client secret dan PKCE verifier
+func (p *MockIdP) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if id != p.ClientID || secret != p.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	code, ok := p.codes[r.PostFormValue("code")]
+	delete(p.codes, r.PostFormValue("code"))
+	p.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || code.redirectURI != r.PostFormValue("redirect_uri") || base64.RawURLEncoding.EncodeToString(sum[:]) != code.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := p.now()
+	claims := jwt.MapClaims{
+		"iss":   p.URL,
+		"aud":   p.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": code.nonce,
+	}
+	for name, value := range code.claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "idp-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// writeJSON menulis response JSON
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,274 @@
+package testutil
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/jimlambrt/gldap"
+)
+
+// LDAPEntry adalah satu user di MockLDAP
+type LDAPEntry struct {
+	DN         string
+	Password   string              // Password untuk bind sebagai entry ini
+	Attributes map[string][]string // Contoh {"mail": {...}, "memberOf": {...}}, nama atribut tidak case sensitive
+}
+
+// MockLDAP adalah server LDAP lokal (embedded) untuk test login dan sinkronisasi LDAP. Server
+// mendukung simple bind dan search dengan filter and/or/not, equality, presence dan substring.
+// Search hanya boleh dilakukan setelah bind sebagai akun service (BindDN).
+type MockLDAP struct {
+	URL          string
+	BaseDN       string
+	BindDN       string
+	BindPassword string
+
+	mu      sync.Mutex
+	entries map[string]LDAPEntry // Key: DN huruf kecil
+	bound   map[int]string       // DN hasil bind terakhir per koneksi
+}
+
+// NewMockLDAP menjalankan server LDAP lokal dengan base DN dc=example,dc=org
+func NewMockLDAP(t *testing.T) *MockLDAP {
+	t.Helper()
+	l := &MockLDAP{
+		BaseDN:       "dc=example,dc=org",
+		BindDN:       "cn=service,dc=example,dc=org",
+		BindPassword: "service-secret",
+		entries:      map[string]LDAPEntry{},
+		bound:        map[int]string{},
+	}
+
+	server, err := gldap.NewServer(gldap.WithOnClose(l.closed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux, err := gldap.NewMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mux.Bind(l.bind); err != nil {
+		t.Fatal(err)
+	}
+	if err := mux.Search(l.search); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Router(mux); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cari port kosong, gldap hanya menerima alamat host:port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	go server.Run(addr)
+	for i := 0; !server.Ready(); i++ {
+		if i == 100 {
+			t.Fatal("mock LDAP server did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	l.URL = "ldap://" + addr
+	t.Cleanup(func() { server.Stop() })
+	return l
+}
+
+// Put menambah atau mengganti entry
+func (l *MockLDAP) Put(entry LDAPEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[strings.ToLower(entry.DN)] = entry
+}
+
+// Remove menghapus entry dari directory
+func (l *MockLDAP) Remove(dn string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, strings.ToLower(dn))
+}
+
+// bind menerima akun service dan entry dengan password yang cocok. Password kosong diterima
+// sebagai unauthenticated bind, sama seperti kebanyakan server LDAP.
+func (l *MockLDAP) bind(w *gldap.ResponseWriter, r *gldap.Request) {
+	resp := r.NewBindResponse(gldap.WithResponseCode(gldap.ResultInvalidCredentials))
+	defer w.Write(resp)
+
+	m, err := r.GetSimpleBindMessage()
+	if err != nil {
+		return
+	}
+	password := string(m.Password)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, exists := l.entries[strings.ToLower(m.UserName)]
+	switch {
+	case password == "":
+		l.bound[r.ConnectionID()] = ""
+	case strings.EqualFold(m.UserName, l.BindDN) && password == l.BindPassword,
+		exists && entry.Password == password:
+		l.bound[r.ConnectionID()] = strings.ToLower(m.UserName)
+	default:
+		return
+	}
+	resp.SetResultCode(gldap.ResultSuccess)
+}
+
+// search mengembalikan entry di bawah base DN yang cocok dengan filter
+func (l *MockLDAP) search(w *gldap.ResponseWriter, r *gldap.Request) {
+	resp := r.NewSearchDoneResponse(gldap.WithResponseCode(gldap.ResultInsufficientAccessRights))
+	defer w.Write(resp)
+
+	m, err := r.GetSearchMessage()
+	if err != nil {
+		resp.SetResultCode(gldap.ResultProtocolError)
+		return
+	}
+	filter, err := ldap.CompileFilter(m.Filter)
+	if err != nil {
+		resp.SetResultCode(gldap.ResultProtocolError)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.bound[r.ConnectionID()] != strings.ToLower(l.BindDN) {
+		return
+	}
+	base := strings.ToLower(m.BaseDN)
+	for key, entry := range l.entries {
+		if (key != base && !strings.HasSuffix(key, ","+base)) || !matchFilter(filter, entry.Attributes) {
+			continue
+		}
+		result := r.NewSearchResponseEntry(entry.DN)
+		for name, values := range entry.Attributes {
+			if len(values) > 0 && requested(m.Attributes, name) {
+				result.AddAttribute(name, values)
+			}
+		}
+		w.Write(result)
+	}
+	resp.SetResultCode(gldap.ResultSuccess)
+}
+
+// closed membuang status bind koneksi yang sudah ditutup
+func (l *MockLDAP) closed(connectionID int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.bound, connectionID)
+}
+
+// requested menandakan atribut diminta oleh search (daftar kosong berarti semua atribut)
+func requested(attributes []string, name string) bool {
+	if len(attributes) == 0 {
+		return true
+	}
+	for _, attribute := range attributes {
+		if attribute == "*" || strings.EqualFold(attribute, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilter mengevaluasi filter hasil ldap.CompileFilter terhadap atribut entry
+func matchFilter(filter *ber.Packet, attributes map[string][]string) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(child, attributes) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matchFilter(child, attributes) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !matchFilter(filter.Children[0], attributes)
+	case ldap.FilterPresent:
+		return len(attributeValues(attributes, ber.DecodeString(filter.Data.Bytes()))) > 0
+	case ldap.FilterEqualityMatch:
+		want := ber.DecodeString(filter.Children[1].Data.Bytes())
+		for _, value := range attributeValues(attributes, ber.DecodeString(filter.Children[0].Data.Bytes())) {
+			if strings.EqualFold(value, want) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterSubstrings:
+		for _, value := range attributeValues(attributes, ber.DecodeString(filter.Children[0].Data.Bytes())) {
+			if matchSubstrings(strings.ToLower(value), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	}
+	// Filter lain (>=, <=, ~=, extensible) tidak didukung
+	return false
+}
+
+// matchSubstrings mencocokkan value dengan bagian filter substring (initial*any*final)
+func matchSubstrings(value string, parts []*ber.Packet) bool {
+	for _, part := range parts {
+		text := strings.ToLower(ber.DecodeString(part.Data.Bytes()))
+		switch part.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, text) {
+				return false
+			}
+			value = value[len(text):]
+		case ldap.FilterSubstringsFinal:
+			return strings.HasSuffix(value, text)
+		default:
+			index := strings.Index(value, text)
+			if index < 0 {
+				return false
+			}
+			value = value[index+len(text):]
+		}
+	}
+	return true
+}
+
+// attributeValues mengambil nilai atribut tanpa membedakan huruf besar kecil nama atributnya
+func attributeValues(attributes map[string][]string, name string) []string {
+	for key, values := range attributes {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	// objectClass tidak perlu ditulis di setiap entry test
+	if strings.EqualFold(name, "objectClass") {
+		return []string{"top", "person", "inetOrgPerson"}
+	}
+	return nil
+}
+
+// LDAPUser membuat entry user di ou=people dengan mail, cn, entryUUID dan grup tertentu
+func LDAPUser(uid, email, password string, groups ...string) LDAPEntry {
+	return LDAPEntry{
+		DN:       "uid=" + uid + ",ou=people,dc=example,dc=org",
+		Password: password,
+		Attributes: map[string][]string{
+			"uid":       {uid},
+			"cn":        {strings.ToUpper(uid[:1]) + uid[1:]},
+			"mail":      {email},
+			"entryUUID": {"uuid-" + uid},
+			"memberOf":  groups,
+		},
+	}
+}
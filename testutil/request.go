@@ -0,0 +1,72 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-starter-kit/models"
+	"golang-starter-kit/utils"
+)
+
+// Token menerbitkan JWT untuk user beserta session-nya, sama seperti yang dilakukan Login
+func (h *Harness) Token(t *testing.T, user models.User) string {
+	t.Helper()
+	session := models.Session{
+		ID:        utils.NewID(),
+		UserID:    user.ID,
+		ExpiresAt: utils.Now().Add(utils.JWTLifetime),
+	}
+	if err := h.DB.Create(&session).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, session.ID)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return token
+}
+
+// Request mengirim request ke router. Body di-encode sebagai JSON kecuali berupa string atau nil.
+// Token boleh kosong untuk request tanpa header Authorization.
+func (h *Harness) Request(t *testing.T, method, path string, body interface{}, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, encodeBody(t, body))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	h.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+// AuthRequest mengirim request sebagai user tertentu
+func (h *Harness) AuthRequest(t *testing.T, user models.User, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return h.Request(t, method, path, body, h.Token(t, user))
+}
+
+// encodeBody mengubah body menjadi reader untuk httptest
+func encodeBody(t *testing.T, body interface{}) io.Reader {
+	t.Helper()
+	switch b := body.(type) {
+	case nil:
+		return http.NoBody
+	case string:
+		return bytes.NewBufferString(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+		return bytes.NewReader(raw)
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -16,8 +17,16 @@ var (
 	blacklist = make(map[string]TokenEntry)
 	mutex     sync.RWMutex
 	filePath  = "blacklist.json"
+
+	// activeUntil mencatat session dan grant OAuth yang baru saja dipastikan masih aktif di database, sampai kapan
+	// hasil itu boleh dipakai tanpa membaca database lagi. Tidak disimpan ke file.
+	activeUntil = make(map[string]time.Time)
 )
 
+// RevocationCheckTTL adalah lama hasil pengecekan status session atau grant OAuth di database dipakai ulang.
+// Pencabutan dari replica lain terlihat paling lambat setelah selang ini.
+const RevocationCheckTTL = 30 * time.Second
+
 // AddToBlacklist menambahkan token ke dalam blacklist beserta waktu kadaluwarsanya
 func AddToBlacklist(token string, expiresAt time.Time) {
 	mutex.Lock()
@@ -36,7 +45,7 @@ func IsBlacklisted(token string) bool {
 		return false
 	}
 
-	if time.Now().After(entry.ExpiresAt) {
+	if Now().After(entry.ExpiresAt) {
 		mutex.RUnlock()
 		mutex.Lock()
 		delete(blacklist, token)
@@ -49,6 +58,54 @@ func IsBlacklisted(token string) bool {
 	return true
 }
 
+// sessionKeyPrefix membedakan entry session dari token di dalam blacklist
+const sessionKeyPrefix = "session:"
+
+// RevokeSession memasukkan session ke blacklist sehingga semua token dengan claim sid tersebut ditolak
+func RevokeSession(sessionID string, expiresAt time.Time) {
+	forgetActive(sessionKeyPrefix + sessionID)
+	AddToBlacklist(sessionKeyPrefix+sessionID, expiresAt)
+}
+
+// IsSessionRevoked mengecek apakah session sudah dicabut
+func IsSessionRevoked(sessionID string) bool {
+	return IsBlacklisted(sessionKeyPrefix + sessionID)
+}
+
+// MarkSessionActive mencatat bahwa session baru saja dipastikan aktif di database
+func MarkSessionActive(sessionID string) {
+	markActive(sessionKeyPrefix + sessionID)
+}
+
+// IsSessionKnownActive mengecek apakah session dipastikan aktif dalam RevocationCheckTTL terakhir
+func IsSessionKnownActive(sessionID string) bool {
+	return knownActive(sessionKeyPrefix + sessionID)
+}
+
+// grantKeyPrefix membedakan entry grant OAuth dari token di dalam blacklist
+const grantKeyPrefix = "grant:"
+
+// RevokeGrant memasukkan grant OAuth ke blacklist sehingga semua access token dengan claim gid tersebut ditolak
+func RevokeGrant(grantID string, expiresAt time.Time) {
+	forgetActive(grantKeyPrefix + grantID)
+	AddToBlacklist(grantKeyPrefix+grantID, expiresAt)
+}
+
+// IsGrantRevoked mengecek apakah grant OAuth sudah dicabut
+func IsGrantRevoked(grantID string) bool {
+	return IsBlacklisted(grantKeyPrefix + grantID)
+}
+
+// MarkGrantActive mencatat bahwa grant OAuth baru saja dipastikan aktif di database
+func MarkGrantActive(grantID string) {
+	markActive(grantKeyPrefix + grantID)
+}
+
+// IsGrantKnownActive mengecek apakah grant OAuth dipastikan aktif dalam RevocationCheckTTL terakhir
+func IsGrantKnownActive(grantID string) bool {
+	return knownActive(grantKeyPrefix + grantID)
+}
+
 // GetBlacklistedTokens mengembalikan daftar token yang ada di dalam blacklist
 func GetBlacklistedTokens() map[string]TokenEntry {
 	mutex.RLock()
@@ -56,15 +113,71 @@ func GetBlacklistedTokens() map[string]TokenEntry {
 	return blacklist
 }
 
-// ClearBlacklist menghapus semua token dari blacklist dan file
+// CompactBlacklist menghapus semua token yang sudah kadaluwarsa dari blacklist dan file,
+// mengembalikan jumlah token yang dihapus. IsBlacklisted hanya menghapus token yang dicek,
+// sehingga fungsi ini perlu dijalankan berkala agar file tidak terus membesar.
+func CompactBlacklist() int {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	now := Now()
+	removed := 0
+	for token, entry := range blacklist {
+		if now.After(entry.ExpiresAt) {
+			delete(blacklist, token)
+			removed++
+		}
+	}
+	if removed > 0 {
+		saveBlacklistToFile()
+	}
+	return removed
+}
+
+// ClearBlacklist menghapus semua token logout dari blacklist dan file. Entry session dan grant OAuth
+// yang dicabut tetap disimpan agar token dari session atau grant tersebut tidak aktif kembali.
 func ClearBlacklist() {
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	blacklist = make(map[string]TokenEntry) // kosongkan map
+	for key := range blacklist {
+		if !strings.HasPrefix(key, sessionKeyPrefix) && !strings.HasPrefix(key, grantKeyPrefix) {
+			delete(blacklist, key)
+		}
+	}
+	saveBlacklistToFile()
+}
+
+// markActive mencatat key sebagai aktif selama RevocationCheckTTL. Jika catatan sudah banyak,
+// catatan yang sudah lewat dibersihkan sekalian agar map tidak terus membesar.
+func markActive(key string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	now := Now()
+	if len(activeUntil) >= 1024 {
+		for k, until := range activeUntil {
+			if !now.Before(until) {
+				delete(activeUntil, k)
+			}
+		}
+	}
+	activeUntil[key] = now.Add(RevocationCheckTTL)
+}
+
+// knownActive mengecek apakah key dicatat aktif dan catatannya belum lewat
+func knownActive(key string) bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	until, ok := activeUntil[key]
+	return ok && Now().Before(until)
+}
 
-	// Hapus file atau kosongkan isinya
-	_ = os.WriteFile(filePath, []byte("{}"), 0644)
+// forgetActive menghapus catatan aktif, dipanggil saat session atau grant dicabut di replica ini
+func forgetActive(key string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	delete(activeUntil, key)
 }
 
 // saveBlacklistToFile menyimpan blacklist ke file JSON
@@ -91,7 +204,17 @@ func loadBlacklistFromFile() {
 	_ = decoder.Decode(&blacklist)
 }
 
-// InitBlacklist memuat blacklist dari file saat aplikasi dimulai
+// InitBlacklist memuat blacklist dari file saat aplikasi dimulai.
+// Lokasi file bisa diatur lewat env BLACKLIST_FILE (default: blacklist.json)
 func InitBlacklist() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	filePath = "blacklist.json"
+	if path := os.Getenv("BLACKLIST_FILE"); path != "" {
+		filePath = path
+	}
+	blacklist = make(map[string]TokenEntry)
+	activeUntil = make(map[string]time.Time)
 	loadBlacklistFromFile()
 }
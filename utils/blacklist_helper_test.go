@@ -0,0 +1,54 @@
+package utils_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang-starter-kit/testutil"
+	"golang-starter-kit/utils"
+)
+
+func TestBlacklistEntryExpires(t *testing.T) {
+	t.Setenv("BLACKLIST_FILE", filepath.Join(t.TempDir(), "blacklist.json"))
+	clock := testutil.NewFakeClock(testutil.DefaultTime)
+	utils.SetClock(clock)
+	t.Cleanup(func() { utils.SetClock(nil) })
+	utils.InitBlacklist()
+
+	utils.AddToBlacklist("token-a", clock.Now().Add(time.Hour))
+	if !utils.IsBlacklisted("token-a") {
+		t.Fatal("token-a should be blacklisted")
+	}
+
+	clock.Advance(2 * time.Hour)
+	if utils.IsBlacklisted("token-a") {
+		t.Fatal("token-a should expire from the blacklist")
+	}
+	if _, ok := utils.GetBlacklistedTokens()["token-a"]; ok {
+		t.Fatal("expired token-a should be removed from the blacklist")
+	}
+}
+
+func TestCompactBlacklistRemovesExpiredEntries(t *testing.T) {
+	t.Setenv("BLACKLIST_FILE", filepath.Join(t.TempDir(), "blacklist.json"))
+	clock := testutil.NewFakeClock(testutil.DefaultTime)
+	utils.SetClock(clock)
+	t.Cleanup(func() { utils.SetClock(nil) })
+	utils.InitBlacklist()
+
+	utils.AddToBlacklist("token-a", clock.Now().Add(time.Hour))
+	utils.AddToBlacklist("token-b", clock.Now().Add(3*time.Hour))
+	clock.Advance(2 * time.Hour)
+
+	if removed := utils.CompactBlacklist(); removed != 1 {
+		t.Fatalf("removed = %d, want 1", removed)
+	}
+
+	// File ikut diperbarui, sehingga token-a tidak muncul lagi setelah dimuat ulang
+	utils.InitBlacklist()
+	tokens := utils.GetBlacklistedTokens()
+	if _, ok := tokens["token-a"]; ok || len(tokens) != 1 {
+		t.Fatalf("unexpected blacklist after compaction: %v", tokens)
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock adalah sumber waktu aplikasi. Semua logika yang bergantung pada waktu
+// (kadaluwarsa token, blacklist, timestamp model) membaca waktu lewat Clock
+// agar bisa diganti dengan jam palsu saat test.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock adalah Clock yang memakai waktu sistem
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+var (
+	clock      Clock = systemClock{}
+	clockMutex sync.RWMutex
+)
+
+// SetClock mengganti Clock yang dipakai aplikasi, nil mengembalikan ke waktu sistem
+func SetClock(c Clock) {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+	if c == nil {
+		c = systemClock{}
+	}
+	clock = c
+}
+
+// Now mengembalikan waktu saat ini menurut Clock yang aktif
+func Now() time.Time {
+	clockMutex.RLock()
+	defer clockMutex.RUnlock()
+	return clock.Now()
+}
@@ -1,13 +1,240 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algoritma hash password yang didukung
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+// PasswordHashConfig adalah algoritma dan parameter untuk hash password baru.
+// Hash lama dengan algoritma atau parameter lain tetap bisa diverifikasi.
+type PasswordHashConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+	Pepper            []byte // Rahasia server yang dicampur ke password (HMAC-SHA256), kosong berarti tanpa pepper
+}
+
+// DefaultPasswordHashConfig mengembalikan parameter argon2id yang direkomendasikan OWASP
+func DefaultPasswordHashConfig() PasswordHashConfig {
+	return PasswordHashConfig{
+		Algorithm:         PasswordHashArgon2id,
+		BcryptCost:        12,
+		Argon2Memory:      64 * 1024,
+		Argon2Iterations:  3,
+		Argon2Parallelism: 2,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+	}
+}
+
+// PasswordHasher membuat dan memverifikasi hash password
+type PasswordHasher interface {
+	// Hash membuat hash password dengan algoritma dan parameter saat ini
+	Hash(password string) (string, error)
+	// Verify mencocokkan password dengan hash. rehash bernilai true jika password cocok tetapi
+	// hash dibuat dengan algoritma, parameter atau pepper yang berbeda dari konfigurasi saat ini.
+	Verify(hash, password string) (ok, rehash bool)
+}
+
+// passwordHasher adalah PasswordHasher untuk bcrypt dan argon2id
+type passwordHasher struct {
+	config PasswordHashConfig
+}
+
+// NewPasswordHasher membuat PasswordHasher dengan konfigurasi tertentu
+func NewPasswordHasher(config PasswordHashConfig) PasswordHasher {
+	return &passwordHasher{config: config}
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	input := h.pepper(password)
+	if h.config.Algorithm == PasswordHashBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(input), h.config.BcryptCost)
+		return string(hashed), err
+	}
+
+	salt := make([]byte, h.config.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	params := argon2Params{
+		memory:      h.config.Argon2Memory,
+		iterations:  h.config.Argon2Iterations,
+		parallelism: h.config.Argon2Parallelism,
+	}
+	key := argon2.IDKey([]byte(input), salt, params.iterations, params.memory, params.parallelism, h.config.Argon2KeyLength)
+	return params.encode(salt, key), nil
+}
+
+func (h *passwordHasher) Verify(hash, password string) (bool, bool) {
+	if verifyPasswordHash(hash, h.pepper(password)) {
+		return true, h.outdated(hash)
+	}
+	if len(h.config.Pepper) == 0 {
+		return false, false
+	}
+	// Hash yang dibuat sebelum pepper diaktifkan masih diterima lalu diperbarui
+	ok := verifyPasswordHash(hash, password)
+	return ok, ok
+}
+
+// pepper mencampur password dengan pepper server. Hasil HMAC di-encode base64 agar
+// panjangnya tetap di bawah batas 72 byte bcrypt dan tidak mengandung byte nol.
+func (h *passwordHasher) pepper(password string) string {
+	if len(h.config.Pepper) == 0 {
+		return password
+	}
+	mac := hmac.New(sha256.New, h.config.Pepper)
+	mac.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// outdated mengecek apakah hash perlu dibuat ulang dengan konfigurasi saat ini
+func (h *passwordHasher) outdated(hash string) bool {
+	if h.config.Algorithm == PasswordHashBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.config.BcryptCost
+	}
+
+	params, _, key, err := decodeArgon2(hash)
+	return err != nil ||
+		params.memory != h.config.Argon2Memory ||
+		params.iterations != h.config.Argon2Iterations ||
+		params.parallelism != h.config.Argon2Parallelism ||
+		uint32(len(key)) != h.config.Argon2KeyLength
+}
+
+// verifyPasswordHash mencocokkan input dengan hash bcrypt atau argon2id. Hash dengan format
+// yang tidak dikenal (misalnya kosong) dianggap tidak cocok.
+func verifyPasswordHash(hash, input string) bool {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(input)) == nil
+	}
+
+	params, salt, key, err := decodeArgon2(hash)
+	if err != nil {
+		return false
+	}
+	other := argon2.IDKey([]byte(input), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+// argon2Params adalah parameter argon2id yang disimpan di dalam hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// encode menulis hash dalam format PHC: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func (p argon2Params) encode(salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+// decodeArgon2 membaca parameter, salt dan key dari hash argon2id format PHC
+func decodeArgon2(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("format hash argon2id tidak valid")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("versi argon2 tidak didukung")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("parameter argon2id tidak valid")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("key argon2id tidak valid")
+	}
+	return params, salt, key, nil
+}
+
+var (
+	passwordHasherDefault PasswordHasher = NewPasswordHasher(DefaultPasswordHashConfig())
+	passwordHasherMutex   sync.RWMutex
+)
+
+// SetPasswordHasher mengganti PasswordHasher yang dipakai aplikasi, nil mengembalikan ke default
+func SetPasswordHasher(h PasswordHasher) {
+	passwordHasherMutex.Lock()
+	defer passwordHasherMutex.Unlock()
+	if h == nil {
+		h = NewPasswordHasher(DefaultPasswordHashConfig())
+	}
+	passwordHasherDefault = h
+}
+
+// HashPassword membuat hash password dengan PasswordHasher yang aktif
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+	passwordHasherMutex.RLock()
+	defer passwordHasherMutex.RUnlock()
+	return passwordHasherDefault.Hash(password)
 }
 
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+// VerifyPassword mencocokkan password dengan hash memakai PasswordHasher yang aktif,
+// rehash bernilai true jika hash perlu diperbarui
+func VerifyPassword(hash, password string) (ok, rehash bool) {
+	passwordHasherMutex.RLock()
+	defer passwordHasherMutex.RUnlock()
+	return passwordHasherDefault.Verify(hash, password)
+}
+
+var (
+	dummyPasswordHash   string
+	dummyPasswordHasher PasswordHasher
+	dummyPasswordMutex  sync.Mutex
+)
+
+// VerifyDummyPassword menjalankan verifikasi password terhadap hash palsu dengan algoritma dan
+// parameter yang sama dengan hash sungguhan. Dipanggil saat user tidak ditemukan agar waktu
+// response tidak membedakan email yang terdaftar dan yang tidak.
+func VerifyDummyPassword(password string) {
+	passwordHasherMutex.RLock()
+	hasher := passwordHasherDefault
+	passwordHasherMutex.RUnlock()
+
+	// Hash palsu dibuat sekali per hasher, dibuat ulang jika hasher diganti
+	dummyPasswordMutex.Lock()
+	if dummyPasswordHasher != hasher {
+		hash, err := hasher.Hash("dummy-password-for-timing")
+		if err == nil {
+			dummyPasswordHash, dummyPasswordHasher = hash, hasher
+		}
+	}
+	hash := dummyPasswordHash
+	dummyPasswordMutex.Unlock()
+
+	hasher.Verify(hash, password)
 }
@@ -0,0 +1,89 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang-starter-kit/utils"
+)
+
+// fastHashConfig adalah konfigurasi argon2id dengan parameter minimum agar test cepat
+func fastHashConfig() utils.PasswordHashConfig {
+	config := utils.DefaultPasswordHashConfig()
+	config.Argon2Memory = 64
+	config.Argon2Iterations = 1
+	config.Argon2Parallelism = 1
+	config.BcryptCost = 4
+	return config
+}
+
+func TestPasswordHasherRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{utils.PasswordHashArgon2id, utils.PasswordHashBcrypt} {
+		config := fastHashConfig()
+		config.Algorithm = algorithm
+		hasher := utils.NewPasswordHasher(config)
+
+		hash, err := hasher.Hash("kuda lari di pantai")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if algorithm == utils.PasswordHashArgon2id && !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+			t.Fatalf("unexpected argon2id hash: %s", hash)
+		}
+		if ok, rehash := hasher.Verify(hash, "kuda lari di pantai"); !ok || rehash {
+			t.Fatalf("%s: Verify = %v, %v, want true, false", algorithm, ok, rehash)
+		}
+		if ok, _ := hasher.Verify(hash, "kuda lari di pantai!"); ok {
+			t.Fatalf("%s: wrong password accepted", algorithm)
+		}
+	}
+}
+
+func TestPasswordHasherDetectsOutdatedHash(t *testing.T) {
+	bcryptConfig := fastHashConfig()
+	bcryptConfig.Algorithm = utils.PasswordHashBcrypt
+	legacy, _ := utils.NewPasswordHasher(bcryptConfig).Hash("rahasia123")
+
+	weak, _ := utils.NewPasswordHasher(fastHashConfig()).Hash("rahasia123")
+
+	stronger := fastHashConfig()
+	stronger.Argon2Iterations = 2
+	peppered := stronger
+	peppered.Pepper = []byte("pepper-server")
+
+	cases := []struct {
+		name   string
+		config utils.PasswordHashConfig
+		hash   string
+		rehash bool
+	}{
+		{"bcrypt ke argon2id", stronger, legacy, true},
+		{"parameter argon2id berubah", stronger, weak, true},
+		{"pepper baru diaktifkan", fastHashConfig(), weak, false},
+		{"hash tanpa pepper", peppered, weak, true},
+	}
+	for _, tc := range cases {
+		ok, rehash := utils.NewPasswordHasher(tc.config).Verify(tc.hash, "rahasia123")
+		if !ok || rehash != tc.rehash {
+			t.Fatalf("%s: Verify = %v, %v, want true, %v", tc.name, ok, rehash, tc.rehash)
+		}
+	}
+
+	// Hash dengan pepper tidak bisa diverifikasi tanpa pepper yang sama
+	hash, _ := utils.NewPasswordHasher(peppered).Hash("rahasia123")
+	if ok, _ := utils.NewPasswordHasher(stronger).Verify(hash, "rahasia123"); ok {
+		t.Fatal("peppered hash verified without pepper")
+	}
+	if ok, rehash := utils.NewPasswordHasher(peppered).Verify(hash, "rahasia123"); !ok || rehash {
+		t.Fatalf("Verify = %v, %v, want true, false", ok, rehash)
+	}
+}
+
+func TestPasswordHasherRejectsUnknownHash(t *testing.T) {
+	hasher := utils.NewPasswordHasher(fastHashConfig())
+	for _, hash := range []string{"", "plain", "$argon2id$v=19$m=64,t=1,p=1$bad"} {
+		if ok, _ := hasher.Verify(hash, "plain"); ok {
+			t.Fatalf("hash %q accepted", hash)
+		}
+	}
+}
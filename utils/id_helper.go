@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// IDGenerator adalah sumber ID unik dan token acak, misalnya untuk jti JWT
+// dan token reset. Bisa diganti dengan generator deterministik saat test.
+type IDGenerator interface {
+	// NewID menghasilkan ID unik berformat UUID v4
+	NewID() string
+	// NewToken menghasilkan token acak sepanjang n byte dalam encoding base64 URL
+	NewToken(n int) (string, error)
+}
+
+// randomIDGenerator adalah IDGenerator yang memakai crypto/rand
+type randomIDGenerator struct{}
+
+func (randomIDGenerator) NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // versi 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+func (randomIDGenerator) NewToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+var (
+	idGenerator      IDGenerator = randomIDGenerator{}
+	idGeneratorMutex sync.RWMutex
+)
+
+// SetIDGenerator mengganti IDGenerator yang dipakai aplikasi, nil mengembalikan ke crypto/rand
+func SetIDGenerator(g IDGenerator) {
+	idGeneratorMutex.Lock()
+	defer idGeneratorMutex.Unlock()
+	if g == nil {
+		g = randomIDGenerator{}
+	}
+	idGenerator = g
+}
+
+// NewID menghasilkan ID unik menurut IDGenerator yang aktif
+func NewID() string {
+	idGeneratorMutex.RLock()
+	defer idGeneratorMutex.RUnlock()
+	return idGenerator.NewID()
+}
+
+// NewToken menghasilkan token acak sepanjang n byte menurut IDGenerator yang aktif
+func NewToken(n int) (string, error) {
+	idGeneratorMutex.RLock()
+	defer idGeneratorMutex.RUnlock()
+	return idGenerator.NewToken(n)
+}
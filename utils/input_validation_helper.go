@@ -3,7 +3,6 @@ package utils
 import (
 	"errors"
 	"fmt"
-	"regexp"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -50,9 +49,3 @@ func InputValidation(c *gin.Context, input interface{}) (bool, APIResponse) {
 
 	return true, APIResponse{}
 }
-
-// InputValidationPasswordCriteria memastikan password hanya mengandung huruf, angka, dan karakter @#$.
-func InputValidationPasswordCriteria(password string) bool {
-	matched, _ := regexp.MatchString(`^[a-zA-Z0-9@#$]+$`, password)
-	return matched
-}
@@ -3,19 +3,44 @@ package utils
 import (
 	"os"
 	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateJWT(userID uint, email string) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	expirationHours := 24
+// JWTLifetime adalah masa berlaku JWT yang diterbitkan GenerateJWT
+const JWTLifetime = time.Hour * 24
+
+// GenerateJWT menerbitkan JWT untuk user. sessionID dibawa sebagai claim "sid"
+// agar token bisa dicabut per session, kosong berarti token tanpa session.
+func GenerateJWT(userID uint, email, sessionID string) (string, error) {
+	now := Now()
 
 	claims := jwt.MapClaims{
 		"id":    userID,
 		"email": email,
-		"exp":   time.Now().Add(time.Hour * time.Duration(expirationHours)).Unix(),
+		"jti":   NewID(),
+		"iat":   now.Unix(),
+		"exp":   now.Add(JWTLifetime).Unix(),
+	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
 	}
+	return SignJWT(claims)
+}
 
+// SignJWT menandatangani claims dengan JWT_SECRET, dipakai juga untuk access token OAuth
+func SignJWT(claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+// ParseJWT mem-parse dan memvalidasi JWT memakai JWT_SECRET dan Clock aplikasi
+func ParseJWT(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Pastikan metode penandatanganan yang digunakan adalah HMAC
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	}, jwt.WithTimeFunc(Now))
 }
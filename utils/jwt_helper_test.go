@@ -0,0 +1,37 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang-starter-kit/testutil"
+	"golang-starter-kit/utils"
+)
+
+func TestGenerateJWTUsesClockAndIDGenerator(t *testing.T) {
+	t.Setenv("JWT_SECRET", testutil.TestJWTSecret)
+	utils.SetClock(testutil.NewFakeClock(testutil.DefaultTime))
+	utils.SetIDGenerator(&testutil.SequentialIDs{})
+	t.Cleanup(func() {
+		utils.SetClock(nil)
+		utils.SetIDGenerator(nil)
+	})
+
+	token, err := utils.GenerateJWT(1, "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := utils.ParseJWT(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := parsed.Claims.(jwt.MapClaims)
+	if claims["jti"] != "00000000-0000-4000-8000-000000000001" {
+		t.Fatalf("jti = %v", claims["jti"])
+	}
+	exp, _ := claims.GetExpirationTime()
+	if !exp.Time.Equal(testutil.DefaultTime.Add(utils.JWTLifetime)) {
+		t.Fatalf("exp = %v", exp.Time)
+	}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import "context"
+
+// RequestInfo adalah informasi request HTTP yang dibawa lewat context.Context
+// sampai ke layer service (dipakai untuk audit log)
+type RequestInfo struct {
+	UserID    uint // 0 berarti anonim
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo menyimpan RequestInfo ke context
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// WithRequestUserID mengisi UserID pada RequestInfo yang sudah ada di context
+func WithRequestUserID(ctx context.Context, userID uint) context.Context {
+	info := RequestInfoFrom(ctx)
+	info.UserID = userID
+	return WithRequestInfo(ctx, info)
+}
+
+// RequestInfoFrom mengambil RequestInfo dari context, kosong jika tidak ada
+// (misalnya task scheduler atau job worker)
+func RequestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
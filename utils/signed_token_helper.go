@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error yang dikembalikan VerifySignedToken
+var (
+	ErrSignedTokenInvalid = errors.New("signed token invalid")
+	ErrSignedTokenExpired = errors.New("signed token expired")
+)
+
+// SignToken membuat token bertanda tangan HMAC (JWT_SECRET) yang berisi payload dan waktu kadaluwarsa.
+// purpose membedakan kegunaan token (misalnya "invitation") agar token satu
+// fitur tidak bisa dipakai di fitur lain. Format: payload.exp.signature (base64 URL).
+func SignToken(purpose, payload string, expiresAt time.Time) string {
+	encodedPayload := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	exp := strconv.FormatInt(expiresAt.Unix(), 10)
+	return encodedPayload + "." + exp + "." + signature(purpose, encodedPayload, exp)
+}
+
+// VerifySignedToken memeriksa tanda tangan dan kadaluwarsa token dari SignToken, lalu mengembalikan payload-nya
+func VerifySignedToken(purpose, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrSignedTokenInvalid
+	}
+	encodedPayload, exp, sig := parts[0], parts[1], parts[2]
+
+	expected := signature(purpose, encodedPayload, exp)
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return "", ErrSignedTokenInvalid
+	}
+
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", ErrSignedTokenInvalid
+	}
+	if !Now().Before(time.Unix(expUnix, 0)) {
+		return "", ErrSignedTokenExpired
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", ErrSignedTokenInvalid
+	}
+	return string(payload), nil
+}
+
+// HashToken menghasilkan SHA-256 (hex) dari token rahasia untuk disimpan di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// signature menghitung HMAC-SHA256 untuk SignToken
+func signature(purpose, encodedPayload, exp string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte(purpose + "|" + encodedPayload + "|" + exp))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}